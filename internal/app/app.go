@@ -13,6 +13,7 @@ import (
 	"gpt-load/internal/i18n"
 	"gpt-load/internal/keypool"
 	"gpt-load/internal/models"
+	"gpt-load/internal/policy"
 	"gpt-load/internal/proxy"
 	"gpt-load/internal/services"
 	"gpt-load/internal/store"
@@ -31,6 +32,9 @@ type App struct {
 	configManager     types.ConfigManager
 	settingsManager   *config.SystemSettingsManager
 	groupManager      *services.GroupManager
+	modelRouter       *services.ModelRouter
+	secretManager     *services.SecretManager
+	policyEngine      *policy.PolicyEngine
 	logCleanupService *services.LogCleanupService
 	requestLogService *services.RequestLogService
 	cronChecker       *keypool.CronChecker
@@ -48,6 +52,9 @@ type AppParams struct {
 	ConfigManager     types.ConfigManager
 	SettingsManager   *config.SystemSettingsManager
 	GroupManager      *services.GroupManager
+	ModelRouter       *services.ModelRouter
+	SecretManager     *services.SecretManager
+	PolicyEngine      *policy.PolicyEngine
 	LogCleanupService *services.LogCleanupService
 	RequestLogService *services.RequestLogService
 	CronChecker       *keypool.CronChecker
@@ -64,6 +71,9 @@ func NewApp(params AppParams) *App {
 		configManager:     params.ConfigManager,
 		settingsManager:   params.SettingsManager,
 		groupManager:      params.GroupManager,
+		modelRouter:       params.ModelRouter,
+		secretManager:     params.SecretManager,
+		policyEngine:      params.PolicyEngine,
 		logCleanupService: params.LogCleanupService,
 		requestLogService: params.RequestLogService,
 		cronChecker:       params.CronChecker,
@@ -98,6 +108,10 @@ func (a *App) Start() error {
 			&models.APIKey{},
 			&models.RequestLog{},
 			&models.GroupHourlyStat{},
+			&models.Policy{},
+			&models.GroupPolicy{},
+			&models.ModelRoute{},
+			&models.Secret{},
 		); err != nil {
 			return fmt.Errorf("database auto-migration failed: %w", err)
 		}
@@ -134,6 +148,15 @@ func (a *App) Start() error {
 	a.configManager.DisplayServerConfig()
 
 	a.groupManager.Initialize()
+	if err := a.modelRouter.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize model router: %w", err)
+	}
+	if err := a.secretManager.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize secret manager: %w", err)
+	}
+	if err := a.policyEngine.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize policy engine: %w", err)
+	}
 
 	// Create HTTP server
 	serverConfig := a.configManager.GetEffectiveServerConfig()
@@ -183,6 +206,9 @@ func (a *App) Stop(ctx context.Context) {
 	// 使用原始的总超时 context 继续关闭其他后台服务
 	stoppableServices := []func(context.Context){
 		a.groupManager.Stop,
+		a.modelRouter.Stop,
+		a.secretManager.Stop,
+		a.policyEngine.Stop,
 		a.settingsManager.Stop,
 	}
 
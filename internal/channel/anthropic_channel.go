@@ -73,6 +73,11 @@ func (ch *AnthropicChannel) ExtractModel(c *gin.Context, bodyBytes []byte) strin
 	return ""
 }
 
+// IsQuotaExhausted detects Anthropic's low credit balance error, which persists until credits are added.
+func (ch *AnthropicChannel) IsQuotaExhausted(statusCode int, errorBody []byte) bool {
+	return statusCode == http.StatusBadRequest && bodyContainsAny(errorBody, "credit balance is too low")
+}
+
 // ValidateKey checks if the given API key is valid by making a messages request.
 func (ch *AnthropicChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
 	upstreamURL := ch.getUpstreamURL()
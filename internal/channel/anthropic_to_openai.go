@@ -0,0 +1,450 @@
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// channel_config 中启用 anthropic_messages 的 OpenAI 分组同时接受 Anthropic Messages API 请求（/v1/messages），
+// 转换为 Chat Completions 请求后转发；未启用时原样透传
+
+// TranslatesRequest reports whether the request is an Anthropic messages request and the group translates them.
+func (ch *OpenAIChannel) TranslatesRequest(c *gin.Context) bool {
+	return ch.anthropicMessages && c.Request.Method == http.MethodPost && strings.HasSuffix(c.Request.URL.Path, "/v1/messages")
+}
+
+// TranslateRequest converts an Anthropic messages request into an OpenAI chat completion request.
+func (ch *OpenAIChannel) TranslateRequest(c *gin.Context, bodyBytes []byte) (*TranslatedRequest, error) {
+	body, err := anthropicToOpenAIRequest(bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamURL := *c.Request.URL
+	upstreamURL.Path = strings.TrimSuffix(upstreamURL.Path, "/messages") + "/chat/completions"
+	upstreamURL.RawPath = ""
+	return &TranslatedRequest{Body: body, URL: &upstreamURL}, nil
+}
+
+// TranslateResponse converts an OpenAI chat completion into an Anthropic message.
+func (ch *OpenAIChannel) TranslateResponse(c *gin.Context, body []byte) ([]byte, error) {
+	return openAIToAnthropicResponse(body)
+}
+
+// TranslateError converts an OpenAI error body into an Anthropic error body.
+func (ch *OpenAIChannel) TranslateError(statusCode int, body []byte) []byte {
+	var openAIErr openAIErrorResponse
+	if err := json.Unmarshal(body, &openAIErr); err != nil || openAIErr.Error.Message == "" {
+		return body
+	}
+	return newAnthropicError(statusCode, openAIErr.Error.Message)
+}
+
+// NewStreamTranslator returns a translator from OpenAI chunks to Anthropic stream events.
+func (ch *OpenAIChannel) NewStreamTranslator(c *gin.Context) StreamTranslator {
+	return newOpenAIToAnthropicStream()
+}
+
+// anthropicToOpenAIRequest converts an Anthropic messages request body into an OpenAI chat completion request body.
+func anthropicToOpenAIRequest(bodyBytes []byte) ([]byte, error) {
+	var req anthropicRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		return nil, fmt.Errorf("invalid messages request: %w", err)
+	}
+
+	out := openAIChatRequest{
+		Model:       req.Model,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stream:      req.Stream,
+	}
+	if req.MaxTokens > 0 {
+		out.MaxTokens = &req.MaxTokens
+	}
+	if req.Stream {
+		out.StreamOptions = &openAIStreamOpts{IncludeUsage: true}
+	}
+	if len(req.StopSequences) > 0 {
+		out.Stop, _ = json.Marshal(req.StopSequences)
+	}
+	if req.Metadata != nil {
+		out.User = req.Metadata.UserID
+	}
+
+	if system := anthropicSystemText(req.System); system != "" {
+		out.Messages = append(out.Messages, openAIMessage{Role: "system", Content: jsonString(system)})
+	}
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case "user":
+			out.Messages = append(out.Messages, anthropicUserToOpenAI(msg.Content)...)
+		case "assistant":
+			out.Messages = append(out.Messages, anthropicAssistantToOpenAI(msg.Content))
+		}
+	}
+
+	for _, tool := range req.Tools {
+		out.Tools = append(out.Tools, openAITool{
+			Type:     "function",
+			Function: openAIFunctionSpec{Name: tool.Name, Description: tool.Description, Parameters: tool.InputSchema},
+		})
+	}
+	if req.ToolChoice != nil && len(out.Tools) > 0 {
+		out.ToolChoice = anthropicToolChoiceToOpenAI(req.ToolChoice)
+		if req.ToolChoice.DisableParallelToolUse {
+			parallel := false
+			out.ParallelToolCalls = &parallel
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// anthropicSystemText joins the system prompt, a plain string or an array of text blocks.
+func anthropicSystemText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var blocks anthropicContent
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return ""
+	}
+	return anthropicBlocksText(blocks)
+}
+
+// anthropicBlocksText joins the text blocks of content.
+func anthropicBlocksText(blocks anthropicContent) string {
+	var texts []string
+	for _, block := range blocks {
+		if block.Type == "text" && block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// anthropicUserToOpenAI converts a user message. Tool results become tool messages, which OpenAI
+// expects directly after the assistant message that made the calls, ahead of any other user content.
+func anthropicUserToOpenAI(blocks anthropicContent) []openAIMessage {
+	var messages []openAIMessage
+	var parts []openAIContentPart
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			parts = append(parts, openAIContentPart{Type: "text", Text: block.Text})
+		case "image":
+			if block.Source == nil {
+				continue
+			}
+			imageURL := block.Source.URL
+			if block.Source.Type == "base64" {
+				imageURL = "data:" + block.Source.MediaType + ";base64," + block.Source.Data
+			}
+			parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: imageURL}})
+		case "tool_result":
+			var result anthropicContent
+			if len(block.Content) > 0 {
+				_ = json.Unmarshal(block.Content, &result)
+			}
+			messages = append(messages, openAIMessage{
+				Role:       "tool",
+				ToolCallID: block.ToolUseID,
+				Content:    jsonString(anthropicBlocksText(result)),
+			})
+		}
+	}
+
+	if len(parts) > 0 {
+		content, _ := json.Marshal(parts)
+		if len(parts) == 1 && parts[0].Type == "text" {
+			content = jsonString(parts[0].Text)
+		}
+		messages = append(messages, openAIMessage{Role: "user", Content: content})
+	}
+	return messages
+}
+
+// anthropicAssistantToOpenAI converts an assistant message, turning tool_use blocks into tool calls.
+func anthropicAssistantToOpenAI(blocks anthropicContent) openAIMessage {
+	message := openAIMessage{Role: "assistant"}
+	var texts []string
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			message.ToolCalls = append(message.ToolCalls, openAIToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: openAIFunctionCall{Name: block.Name, Arguments: arguments},
+			})
+		}
+	}
+	if len(texts) > 0 || len(message.ToolCalls) == 0 {
+		message.Content = jsonString(strings.Join(texts, ""))
+	}
+	return message
+}
+
+// anthropicToolChoiceToOpenAI converts the Anthropic tool_choice parameter.
+func anthropicToolChoiceToOpenAI(choice *anthropicToolChoice) json.RawMessage {
+	switch choice.Type {
+	case "any":
+		return jsonString("required")
+	case "none":
+		return jsonString("none")
+	case "tool":
+		raw, _ := json.Marshal(openAITool{Type: "function", Function: openAIFunctionSpec{Name: choice.Name}})
+		return raw
+	default:
+		return jsonString("auto")
+	}
+}
+
+// openAIFinishReasonToAnthropic maps an OpenAI finish reason to an Anthropic stop reason.
+func openAIFinishReasonToAnthropic(finishReason string) string {
+	switch finishReason {
+	case "length":
+		return "max_tokens"
+	case "tool_calls", "function_call":
+		return "tool_use"
+	case "content_filter":
+		return "refusal"
+	default:
+		return "end_turn"
+	}
+}
+
+// openAIToAnthropicResponse converts an OpenAI chat completion body into an Anthropic message body.
+func openAIToAnthropicResponse(body []byte) ([]byte, error) {
+	var resp openAIChatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid chat completion response: %w", err)
+	}
+
+	out := anthropicResponse{
+		ID:      anthropicMessageID(resp.ID),
+		Type:    "message",
+		Role:    "assistant",
+		Model:   resp.Model,
+		Content: anthropicContent{},
+	}
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		if text := openAIContentText(choice.Message.Content); text != "" {
+			out.Content = append(out.Content, anthropicContentBlock{Type: "text", Text: text})
+		}
+		for _, call := range choice.Message.ToolCalls {
+			input := json.RawMessage(call.Function.Arguments)
+			if !json.Valid(input) {
+				input = json.RawMessage("{}")
+			}
+			out.Content = append(out.Content, anthropicContentBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+		}
+		out.StopReason = openAIFinishReasonToAnthropic(choice.FinishReason)
+	}
+	if resp.Usage != nil {
+		out.Usage = anthropicUsage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+	}
+	return json.Marshal(out)
+}
+
+// newAnthropicError builds an Anthropic style error body, deriving the error type from the HTTP status.
+func newAnthropicError(statusCode int, message string) []byte {
+	errorType := "api_error"
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		errorType = "authentication_error"
+	case statusCode == http.StatusForbidden:
+		errorType = "permission_error"
+	case statusCode == http.StatusNotFound:
+		errorType = "not_found_error"
+	case statusCode == http.StatusRequestEntityTooLarge:
+		errorType = "request_too_large"
+	case statusCode == http.StatusTooManyRequests:
+		errorType = "rate_limit_error"
+	case statusCode == 529:
+		errorType = "overloaded_error"
+	case statusCode >= 400 && statusCode < 500:
+		errorType = "invalid_request_error"
+	}
+	body, _ := json.Marshal(anthropicErrorResponse{Type: "error", Error: anthropicError{Type: errorType, Message: message}})
+	return body
+}
+
+func anthropicMessageID(id string) string {
+	if id == "" {
+		id = strings.ReplaceAll(uuid.NewString(), "-", "")
+	}
+	return "msg_" + strings.TrimPrefix(id, "chatcmpl-")
+}
+
+func jsonString(s string) json.RawMessage {
+	raw, _ := json.Marshal(s)
+	return raw
+}
+
+// openAIToAnthropicStream translates one OpenAI chunk stream into the Anthropic event sequence:
+// message_start, content blocks (start, deltas, stop), message_delta and message_stop.
+type openAIToAnthropicStream struct {
+	started      bool
+	blockIndex   int         // 下一个内容块的索引
+	openBlock    string      // 当前打开的内容块类型，为空表示没有
+	toolBlocks   map[int]int // OpenAI tool_calls 索引 -> Anthropic 内容块索引
+	stopReason   string
+	inputTokens  int
+	outputTokens int
+	done         bool
+}
+
+func newOpenAIToAnthropicStream() *openAIToAnthropicStream {
+	return &openAIToAnthropicStream{toolBlocks: make(map[int]int)}
+}
+
+// anthropicStreamBlock is the content_block of a content_block_start event; text blocks must carry an empty text.
+type anthropicStreamBlock struct {
+	Type  string          `json:"type"`
+	Text  *string         `json:"text,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// TranslateEvent converts one OpenAI chunk into Anthropic stream events.
+func (s *openAIToAnthropicStream) TranslateEvent(event SSEEvent) ([]SSEEvent, error) {
+	if string(event.Data) == "[DONE]" {
+		return s.Finish(), nil
+	}
+
+	var errResp openAIErrorResponse
+	if err := json.Unmarshal(event.Data, &errResp); err == nil && errResp.Error.Message != "" {
+		return []SSEEvent{{Event: "error", Data: newAnthropicError(http.StatusInternalServerError, errResp.Error.Message)}}, nil
+	}
+
+	var chunk openAIChatChunk
+	if err := json.Unmarshal(event.Data, &chunk); err != nil {
+		return nil, fmt.Errorf("invalid chat completion chunk: %w", err)
+	}
+
+	var events []SSEEvent
+	if !s.started {
+		s.started = true
+		events = append(events, anthropicEvent("message_start", gin.H{
+			"type": "message_start",
+			"message": gin.H{
+				"id":            anthropicMessageID(chunk.ID),
+				"type":          "message",
+				"role":          "assistant",
+				"model":         chunk.Model,
+				"content":       []any{},
+				"stop_reason":   nil,
+				"stop_sequence": nil,
+				"usage":         anthropicUsage{},
+			},
+		}))
+	}
+	if chunk.Usage != nil {
+		s.inputTokens = chunk.Usage.PromptTokens
+		s.outputTokens = chunk.Usage.CompletionTokens
+	}
+	if len(chunk.Choices) == 0 {
+		return events, nil
+	}
+
+	choice := chunk.Choices[0]
+	if choice.Delta.Content != nil && *choice.Delta.Content != "" {
+		if s.openBlock != "text" {
+			events = append(events, s.closeBlock()...)
+			empty := ""
+			events = append(events, s.startBlock(anthropicStreamBlock{Type: "text", Text: &empty}))
+			s.openBlock = "text"
+		}
+		events = append(events, anthropicEvent("content_block_delta", gin.H{
+			"type":  "content_block_delta",
+			"index": s.blockIndex - 1,
+			"delta": anthropicStreamDelta{Type: "text_delta", Text: *choice.Delta.Content},
+		}))
+	}
+	for i, call := range choice.Delta.ToolCalls {
+		toolIndex := i
+		if call.Index != nil {
+			toolIndex = *call.Index
+		}
+		blockIndex, ok := s.toolBlocks[toolIndex]
+		if !ok {
+			events = append(events, s.closeBlock()...)
+			blockIndex = s.blockIndex
+			s.toolBlocks[toolIndex] = blockIndex
+			events = append(events, s.startBlock(anthropicStreamBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: json.RawMessage("{}")}))
+			s.openBlock = "tool_use"
+		}
+		if call.Function.Arguments != "" {
+			events = append(events, anthropicEvent("content_block_delta", gin.H{
+				"type":  "content_block_delta",
+				"index": blockIndex,
+				"delta": anthropicStreamDelta{Type: "input_json_delta", PartialJSON: call.Function.Arguments},
+			}))
+		}
+	}
+	if choice.FinishReason != nil && *choice.FinishReason != "" {
+		s.stopReason = openAIFinishReasonToAnthropic(*choice.FinishReason)
+		events = append(events, s.closeBlock()...)
+	}
+	return events, nil
+}
+
+// Finish closes any open content block and ends the message. The message_delta is held back until
+// the end of the stream, since OpenAI reports usage in a separate chunk after the finish reason.
+func (s *openAIToAnthropicStream) Finish() []SSEEvent {
+	if s.done || !s.started {
+		return nil
+	}
+	s.done = true
+
+	events := s.closeBlock()
+	stopReason := s.stopReason
+	if stopReason == "" {
+		stopReason = "end_turn"
+	}
+	events = append(events,
+		anthropicEvent("message_delta", gin.H{
+			"type":  "message_delta",
+			"delta": gin.H{"stop_reason": stopReason, "stop_sequence": nil},
+			"usage": anthropicUsage{InputTokens: s.inputTokens, OutputTokens: s.outputTokens},
+		}),
+		anthropicEvent("message_stop", gin.H{"type": "message_stop"}),
+	)
+	return events
+}
+
+func (s *openAIToAnthropicStream) startBlock(block anthropicStreamBlock) SSEEvent {
+	event := anthropicEvent("content_block_start", gin.H{
+		"type":          "content_block_start",
+		"index":         s.blockIndex,
+		"content_block": block,
+	})
+	s.blockIndex++
+	return event
+}
+
+func (s *openAIToAnthropicStream) closeBlock() []SSEEvent {
+	if s.openBlock == "" {
+		return nil
+	}
+	s.openBlock = ""
+	return []SSEEvent{anthropicEvent("content_block_stop", gin.H{"type": "content_block_stop", "index": s.blockIndex - 1})}
+}
+
+func anthropicEvent(eventType string, payload any) SSEEvent {
+	data, _ := json.Marshal(payload)
+	return SSEEvent{Event: eventType, Data: data}
+}
@@ -0,0 +1,189 @@
+package channel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIChannel_TranslatesMessagesRequest(t *testing.T) {
+	// 未启用 anthropic_messages 时 /v1/messages 原样透传
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/proxy/g1/v1/messages", nil)
+	assert.False(t, (&OpenAIChannel{}).TranslatesRequest(c))
+
+	ch := &OpenAIChannel{anthropicMessages: true}
+
+	for path, want := range map[string]bool{
+		"/proxy/g1/v1/messages":                  true,
+		"/v1/messages":                           true,
+		"/proxy/g1/v1/threads/thread_1/messages": false,
+		"/proxy/g1/v1/chat/completions":          false,
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, path, nil)
+		assert.Equal(t, want, ch.TranslatesRequest(c), path)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/proxy/g1/v1/messages?beta=true", nil)
+	translated, err := ch.TranslateRequest(c, []byte(`{"model":"gpt-4o","max_tokens":10,"messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "/proxy/g1/v1/chat/completions?beta=true", translated.URL.String())
+}
+
+func TestAnthropicToOpenAIRequest(t *testing.T) {
+	body := `{
+		"model": "gpt-4o",
+		"max_tokens": 1024,
+		"stream": true,
+		"stop_sequences": ["END"],
+		"system": [{"type": "text", "text": "Be brief."}],
+		"metadata": {"user_id": "u-1"},
+		"messages": [
+			{"role": "user", "content": [
+				{"type": "text", "text": "What is this?"},
+				{"type": "image", "source": {"type": "base64", "media_type": "image/png", "data": "iVBORw0KGgo="}}
+			]},
+			{"role": "assistant", "content": [
+				{"type": "text", "text": "Let me check."},
+				{"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": {"q": "cat"}}
+			]},
+			{"role": "user", "content": [
+				{"type": "tool_result", "tool_use_id": "toolu_1", "content": [{"type": "text", "text": "a cat"}]},
+				{"type": "text", "text": "Thanks"}
+			]}
+		],
+		"tools": [{"name": "lookup", "description": "Look up", "input_schema": {"type": "object"}}],
+		"tool_choice": {"type": "tool", "name": "lookup", "disable_parallel_tool_use": true}
+	}`
+
+	out, err := anthropicToOpenAIRequest([]byte(body))
+	require.NoError(t, err)
+
+	var req openAIChatRequest
+	require.NoError(t, json.Unmarshal(out, &req))
+	assert.Equal(t, "gpt-4o", req.Model)
+	assert.Equal(t, 1024, *req.MaxTokens)
+	assert.True(t, req.Stream)
+	require.NotNil(t, req.StreamOptions)
+	assert.True(t, req.StreamOptions.IncludeUsage)
+	assert.JSONEq(t, `["END"]`, string(req.Stop))
+	assert.Equal(t, "u-1", req.User)
+
+	require.Len(t, req.Messages, 5)
+	assert.Equal(t, "system", req.Messages[0].Role)
+	assert.JSONEq(t, `"Be brief."`, string(req.Messages[0].Content))
+
+	assert.JSONEq(t, `[
+		{"type":"text","text":"What is this?"},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw0KGgo="}}
+	]`, string(req.Messages[1].Content))
+
+	assistant := req.Messages[2]
+	assert.JSONEq(t, `"Let me check."`, string(assistant.Content))
+	require.Len(t, assistant.ToolCalls, 1)
+	assert.Equal(t, "toolu_1", assistant.ToolCalls[0].ID)
+	assert.JSONEq(t, `{"q":"cat"}`, assistant.ToolCalls[0].Function.Arguments)
+
+	// 工具结果排在同一条消息的其他用户内容之前
+	assert.Equal(t, "tool", req.Messages[3].Role)
+	assert.Equal(t, "toolu_1", req.Messages[3].ToolCallID)
+	assert.JSONEq(t, `"a cat"`, string(req.Messages[3].Content))
+	assert.Equal(t, "user", req.Messages[4].Role)
+	assert.JSONEq(t, `"Thanks"`, string(req.Messages[4].Content))
+
+	require.Len(t, req.Tools, 1)
+	assert.JSONEq(t, `{"type":"object"}`, string(req.Tools[0].Function.Parameters))
+	assert.JSONEq(t, `{"type":"function","function":{"name":"lookup"}}`, string(req.ToolChoice))
+	require.NotNil(t, req.ParallelToolCalls)
+	assert.False(t, *req.ParallelToolCalls)
+}
+
+func TestOpenAIToAnthropicResponse(t *testing.T) {
+	body := `{
+		"id": "chatcmpl-1", "object": "chat.completion", "model": "gpt-4o",
+		"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {
+			"role": "assistant", "content": "Let me check.",
+			"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{\"q\":\"cat\"}"}}]
+		}}],
+		"usage": {"prompt_tokens": 10, "completion_tokens": 7, "total_tokens": 17}
+	}`
+
+	out, err := openAIToAnthropicResponse([]byte(body))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": "msg_1", "type": "message", "role": "assistant", "model": "gpt-4o",
+		"content": [
+			{"type": "text", "text": "Let me check."},
+			{"type": "tool_use", "id": "call_1", "name": "lookup", "input": {"q": "cat"}}
+		],
+		"stop_reason": "tool_use", "stop_sequence": null,
+		"usage": {"input_tokens": 10, "output_tokens": 7}
+	}`, string(out))
+}
+
+func TestOpenAIToAnthropicStream(t *testing.T) {
+	upstream := strings.Join([]string{
+		`data: {"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":null}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":""}}]},"finish_reason":null}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"q\":1}"}}]},"finish_reason":null}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		``,
+		`data: {"id":"chatcmpl-1","model":"gpt-4o","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		``,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+
+	stream := newOpenAIToAnthropicStream()
+	var out []SSEEvent
+	err := ReadSSE(strings.NewReader(upstream), func(event SSEEvent) error {
+		events, err := stream.TranslateEvent(event)
+		out = append(out, events...)
+		return err
+	})
+	require.NoError(t, err)
+	out = append(out, stream.Finish()...)
+
+	var types []string
+	for _, event := range out {
+		var payload struct {
+			Type string `json:"type"`
+		}
+		require.NoError(t, json.Unmarshal(event.Data, &payload))
+		assert.Equal(t, event.Event, payload.Type)
+		types = append(types, event.Event)
+	}
+	assert.Equal(t, []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}, types)
+
+	assert.JSONEq(t, `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`, string(out[1].Data))
+	assert.JSONEq(t, `{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"call_1","name":"lookup","input":{}}}`, string(out[4].Data))
+	assert.JSONEq(t, `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"q\":1}"}}`, string(out[5].Data))
+	assert.JSONEq(t, `{"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"input_tokens":3,"output_tokens":4}}`, string(out[7].Data))
+}
+
+func TestOpenAIChannel_TranslateError(t *testing.T) {
+	ch := &OpenAIChannel{}
+
+	out := ch.TranslateError(429, []byte(`{"error":{"message":"slow down","type":"requests","code":"rate_limit_exceeded"}}`))
+	assert.JSONEq(t, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`, string(out))
+
+	assert.Equal(t, "not json", string(ch.TranslateError(500, []byte("not json"))))
+}
@@ -0,0 +1,110 @@
+package channel
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// AWS event stream (application/vnd.amazon.eventstream) 二进制帧解码
+
+const (
+	awsEventStreamPreludeLen = 12
+	awsEventStreamMaxMessage = 16 << 20
+)
+
+// awsEventStreamMessage is one decoded event stream frame. Only string headers are kept.
+type awsEventStreamMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// readAWSEventStream decodes event stream frames from r and calls fn for each message, verifying both checksums.
+func readAWSEventStream(r io.Reader, fn func(awsEventStreamMessage) error) error {
+	prelude := make([]byte, awsEventStreamPreludeLen)
+	for {
+		if _, err := io.ReadFull(r, prelude); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		totalLen := binary.BigEndian.Uint32(prelude[0:4])
+		headersLen := binary.BigEndian.Uint32(prelude[4:8])
+		if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+			return errors.New("event stream prelude checksum mismatch")
+		}
+		if totalLen > awsEventStreamMaxMessage || uint64(totalLen) < awsEventStreamPreludeLen+4+uint64(headersLen) {
+			return fmt.Errorf("invalid event stream message length %d", totalLen)
+		}
+
+		rest := make([]byte, totalLen-awsEventStreamPreludeLen)
+		if _, err := io.ReadFull(r, rest); err != nil {
+			return err
+		}
+		checksum := crc32.Update(crc32.ChecksumIEEE(prelude), crc32.IEEETable, rest[:len(rest)-4])
+		if checksum != binary.BigEndian.Uint32(rest[len(rest)-4:]) {
+			return errors.New("event stream message checksum mismatch")
+		}
+
+		headers, err := parseAWSEventStreamHeaders(rest[:headersLen])
+		if err != nil {
+			return err
+		}
+		if err := fn(awsEventStreamMessage{Headers: headers, Payload: rest[headersLen : len(rest)-4]}); err != nil {
+			return err
+		}
+	}
+}
+
+// parseAWSEventStreamHeaders decodes the typed headers of a frame.
+func parseAWSEventStreamHeaders(b []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, errors.New("truncated event stream header")
+		}
+		name := string(b[1 : 1+nameLen])
+		valueType := b[1+nameLen]
+		b = b[2+nameLen:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true / false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // int16
+			size = 2
+		case 4: // int32
+			size = 4
+		case 5, 8: // int64, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // byte array, string
+			if len(b) < 2 {
+				return nil, errors.New("truncated event stream header")
+			}
+			size = int(binary.BigEndian.Uint16(b[:2]))
+			b = b[2:]
+			if len(b) < size {
+				return nil, errors.New("truncated event stream header")
+			}
+			if valueType == 7 {
+				headers[name] = string(b[:size])
+			}
+		default:
+			return nil, fmt.Errorf("unknown event stream header type %d", valueType)
+		}
+		if len(b) < size {
+			return nil, errors.New("truncated event stream header")
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
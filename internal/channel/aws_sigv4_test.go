@@ -0,0 +1,103 @@
+package channel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from the AWS SigV4 test suite (get-vanilla, get-vanilla-query-order-key-case).
+func TestSignAWSRequestV4(t *testing.T) {
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		url, signature string
+	}{
+		{"https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+		require.NoError(t, err)
+
+		signAWSRequestV4(req, nil, creds, "us-east-1", "service", now)
+		assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature="+tt.signature,
+			req.Header.Get("Authorization"), tt.url)
+	}
+}
+
+func TestAWSCanonicalURI(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-v2:1/invoke", nil)
+	require.NoError(t, err)
+	req.URL.RawPath = awsEscapePath(req.URL.Path)
+
+	assert.Equal(t, "/model/anthropic.claude-v2%3A1/invoke", req.URL.EscapedPath())
+	assert.Equal(t, "/model/anthropic.claude-v2%253A1/invoke", awsCanonicalURI(req.URL))
+}
+
+func TestParseAWSCredentials(t *testing.T) {
+	creds, err := parseAWSCredentials("AKID:secret/with+chars=:token")
+	require.NoError(t, err)
+	assert.Equal(t, awsCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret/with+chars=", SessionToken: "token"}, creds)
+
+	_, err = parseAWSCredentials("AKID")
+	assert.Error(t, err)
+	_, err = parseAWSCredentials(":secret")
+	assert.Error(t, err)
+}
+
+// encodeAWSEventStreamMessage frames a message with string headers.
+func encodeAWSEventStreamMessage(headers map[string]string, payload []byte) []byte {
+	var headerBytes bytes.Buffer
+	for name, value := range headers {
+		headerBytes.WriteByte(byte(len(name)))
+		headerBytes.WriteString(name)
+		headerBytes.WriteByte(7)
+		_ = binary.Write(&headerBytes, binary.BigEndian, uint16(len(value)))
+		headerBytes.WriteString(value)
+	}
+
+	totalLen := uint32(awsEventStreamPreludeLen + headerBytes.Len() + len(payload) + 4)
+	var msg bytes.Buffer
+	_ = binary.Write(&msg, binary.BigEndian, totalLen)
+	_ = binary.Write(&msg, binary.BigEndian, uint32(headerBytes.Len()))
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(headerBytes.Bytes())
+	msg.Write(payload)
+	_ = binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func TestReadAWSEventStream(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(encodeAWSEventStreamMessage(map[string]string{":message-type": "event", ":event-type": "chunk"}, []byte(`{"bytes":"e30="}`)))
+	stream.Write(encodeAWSEventStreamMessage(map[string]string{":message-type": "event"}, nil))
+
+	var messages []awsEventStreamMessage
+	err := readAWSEventStream(&stream, func(msg awsEventStreamMessage) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "chunk", messages[0].Headers[":event-type"])
+	assert.Equal(t, `{"bytes":"e30="}`, string(messages[0].Payload))
+	assert.Empty(t, messages[1].Payload)
+
+	corrupted := encodeAWSEventStreamMessage(map[string]string{":message-type": "event"}, []byte(`{}`))
+	corrupted[len(corrupted)-6] ^= 0xff
+	err = readAWSEventStream(bytes.NewReader(corrupted), func(awsEventStreamMessage) error { return nil })
+	assert.ErrorContains(t, err, "checksum")
+
+	err = readAWSEventStream(strings.NewReader("short"), func(awsEventStreamMessage) error { return nil })
+	assert.Error(t, err)
+}
@@ -0,0 +1,255 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register("azure", newAzureChannel)
+	RegisterConfigValidator("azure", validateAzureConfig)
+}
+
+// defaultAzureAPIVersion is the api-version used when neither the group nor the client sets one.
+const defaultAzureAPIVersion = "2024-10-21"
+
+// azureDeploymentOperations are the OpenAI endpoints that Azure serves per deployment.
+var azureDeploymentOperations = []string{
+	"/chat/completions",
+	"/completions",
+	"/embeddings",
+	"/images/generations",
+	"/audio/transcriptions",
+	"/audio/translations",
+	"/audio/speech",
+}
+
+var azureDeploymentPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// azureConfig is the channel_config of an azure group.
+type azureConfig struct {
+	// APIVersion is sent as the api-version query parameter unless the client sets one.
+	APIVersion string `json:"api_version"`
+	// Deployments maps OpenAI model names to deployment names. Unmapped models use their name with dots removed,
+	// following Azure's naming of model deployments (gpt-3.5-turbo -> gpt-35-turbo).
+	Deployments map[string]string `json:"deployments"`
+	// AnthropicMessages translates Anthropic Messages API requests into chat completions, as for openai groups.
+	AnthropicMessages bool `json:"anthropic_messages"`
+}
+
+// AzureChannel serves OpenAI clients from Azure OpenAI resources, mapping models to deployments.
+type AzureChannel struct {
+	*OpenAIChannel
+	config azureConfig
+}
+
+func newAzureChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	var config azureConfig
+	if err := decodeChannelConfig(group.ChannelConfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid channel config for azure channel: %w", err)
+	}
+	if config.APIVersion == "" {
+		config.APIVersion = defaultAzureAPIVersion
+	}
+
+	base, err := f.newBaseChannel("azure", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzureChannel{
+		OpenAIChannel: &OpenAIChannel{BaseChannel: base, anthropicMessages: config.AnthropicMessages},
+		config:        config,
+	}, nil
+}
+
+func validateAzureConfig(config map[string]any) error {
+	var cfg azureConfig
+	if err := decodeChannelConfig(config, &cfg); err != nil {
+		return err
+	}
+	for model, deployment := range cfg.Deployments {
+		if strings.TrimSpace(model) == "" {
+			return errors.New("deployment mapping has an empty model name")
+		}
+		if !azureDeploymentPattern.MatchString(deployment) {
+			return fmt.Errorf("invalid deployment name %q for model %q", deployment, model)
+		}
+	}
+	return nil
+}
+
+// deployment returns the deployment serving the model. Derived names are checked like configured ones,
+// since the model comes from the client and becomes a segment of the upstream path.
+func (ch *AzureChannel) deployment(model string) (string, error) {
+	if deployment, ok := ch.config.Deployments[model]; ok {
+		return deployment, nil
+	}
+	deployment := strings.ReplaceAll(model, ".", "")
+	if !azureDeploymentPattern.MatchString(deployment) {
+		return "", fmt.Errorf("invalid deployment name %q for model %q", deployment, model)
+	}
+	return deployment, nil
+}
+
+// ModifyRequest sets the api-key header used by Azure OpenAI.
+func (ch *AzureChannel) ModifyRequest(req *http.Request, apiKey *models.APIKey, group *models.Group) {
+	req.Header.Set("api-key", apiKey.KeyValue)
+}
+
+// RewriteRequestURL maps OpenAI paths onto Azure paths and adds the api-version parameter:
+// /v1/chat/completions becomes /openai/deployments/<deployment>/chat/completions and other
+// endpoints such as /v1/models become /openai/models. Azure native paths are only given an api-version.
+func (ch *AzureChannel) RewriteRequestURL(c *gin.Context, requestURL *url.URL, bodyBytes []byte) (*url.URL, error) {
+	rewritten := *requestURL
+	rewritten.RawPath = ""
+
+	if !strings.Contains(requestURL.Path, "/openai/") {
+		rest := TrimProxyPrefix(requestURL.Path)
+		prefix := strings.TrimSuffix(requestURL.Path, rest)
+		rest = strings.TrimPrefix(rest, "/v1")
+
+		rewritten.Path = prefix + "/openai" + rest
+		for _, operation := range azureDeploymentOperations {
+			if rest != operation {
+				continue
+			}
+			model := ch.ExtractModel(c, bodyBytes)
+			if model == "" {
+				return nil, errors.New("model is required to select an Azure deployment")
+			}
+			deployment, err := ch.deployment(model)
+			if err != nil {
+				return nil, err
+			}
+			rewritten.Path = prefix + "/openai/deployments/" + deployment + rest
+			break
+		}
+	}
+
+	query := rewritten.Query()
+	if query.Get("api-version") == "" {
+		query.Set("api-version", ch.config.APIVersion)
+		rewritten.RawQuery = query.Encode()
+	}
+	return &rewritten, nil
+}
+
+// ExtractModel reads the model from JSON and multipart bodies, or the deployment from Azure native paths.
+func (ch *AzureChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
+	if model := ch.OpenAIChannel.ExtractModel(c, bodyBytes); model != "" {
+		return model
+	}
+	if model := multipartFormValue(c.GetHeader("Content-Type"), bodyBytes, "model"); model != "" {
+		return model
+	}
+
+	parts := strings.Split(c.Request.URL.Path, "/")
+	for i, part := range parts {
+		if part == "deployments" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// multipartFormValue returns the value of a form field of a multipart/form-data body.
+func multipartFormValue(contentType string, body []byte, name string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return ""
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return ""
+		}
+		if part.FormName() == name {
+			value, _ := io.ReadAll(io.LimitReader(part, 256))
+			return strings.TrimSpace(string(value))
+		}
+	}
+}
+
+// ValidateKey checks if the given API key is valid by making a chat completion request to the test model's deployment.
+func (ch *AzureChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
+	upstreamURL := ch.getUpstreamURL()
+	if upstreamURL == nil {
+		return false, fmt.Errorf("no upstream URL configured for channel %s", ch.Name)
+	}
+
+	validationEndpoint := ch.ValidationEndpoint
+	if validationEndpoint == "" {
+		deployment, err := ch.deployment(ch.TestModel)
+		if err != nil {
+			return false, err
+		}
+		validationEndpoint = "/openai/deployments/" + deployment + "/chat/completions"
+	}
+	reqURL, err := url.JoinPath(upstreamURL.String(), validationEndpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to join upstream URL and validation endpoint: %w", err)
+	}
+	reqURL += "?api-version=" + url.QueryEscape(ch.config.APIVersion)
+
+	payload := gin.H{
+		"messages": []gin.H{
+			{"role": "user", "content": "hi"},
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal validation payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, bytes.NewBuffer(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create validation request: %w", err)
+	}
+	req.Header.Set("api-key", apiKey.KeyValue)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Apply custom header rules if available
+	if len(group.HeaderRuleList) > 0 {
+		headerCtx := utils.NewHeaderVariableContext(group, apiKey)
+		utils.ApplyHeaderRules(req, group.HeaderRuleList, headerCtx)
+	}
+
+	resp, err := ch.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send validation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Any 2xx status code indicates the key is valid.
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+
+	// For non-200 responses, parse the body to provide a more specific error reason.
+	errorBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("key is invalid (status %d), but failed to read error body: %w", resp.StatusCode, err)
+	}
+
+	parsedError := app_errors.ParseUpstreamError(errorBody)
+
+	return false, fmt.Errorf("[status %d] %s", resp.StatusCode, parsedError)
+}
@@ -0,0 +1,115 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"gpt-load/internal/models"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAzureChannel(t *testing.T, upstream string) *AzureChannel {
+	base := newHealthTestChannel(t, 5, upstream)
+	base.HTTPClient = http.DefaultClient
+	base.TestModel = "gpt-4o"
+	return &AzureChannel{
+		OpenAIChannel: &OpenAIChannel{BaseChannel: base},
+		config: azureConfig{
+			APIVersion:  "2024-10-21",
+			Deployments: map[string]string{"gpt-4o": "prod-4o"},
+		},
+	}
+}
+
+func TestAzureChannel_RewriteRequestURL(t *testing.T) {
+	ch := newTestAzureChannel(t, "https://res.openai.azure.com")
+
+	tests := []struct {
+		name, path, body, want string
+	}{
+		{"mapped deployment", "/proxy/az/v1/chat/completions", `{"model":"gpt-4o"}`, "/proxy/az/openai/deployments/prod-4o/chat/completions?api-version=2024-10-21"},
+		{"unmapped deployment", "/v1/embeddings", `{"model":"text-embedding-3.5"}`, "/openai/deployments/text-embedding-35/embeddings?api-version=2024-10-21"},
+		{"deployment independent", "/proxy/az/v1/models", ``, "/proxy/az/openai/models?api-version=2024-10-21"},
+		{"native path keeps client api-version", "/proxy/az/openai/deployments/x/chat/completions?api-version=2025-01-01", `{}`, "/proxy/az/openai/deployments/x/chat/completions?api-version=2025-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+
+			rewritten, err := ch.RewriteRequestURL(c, c.Request.URL, []byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rewritten.String())
+		})
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/proxy/az/v1/chat/completions", nil)
+	_, err := ch.RewriteRequestURL(c, c.Request.URL, []byte(`{}`))
+	assert.Error(t, err)
+
+	for _, model := range []string{"../models", "gpt-4o/../../x", "gpt-4o?api-version=1"} {
+		_, err := ch.RewriteRequestURL(c, c.Request.URL, []byte(`{"model":"`+model+`"}`))
+		assert.ErrorContains(t, err, "invalid deployment name", model)
+	}
+}
+
+func TestAzureChannel_ExtractModelFromMultipart(t *testing.T) {
+	ch := newTestAzureChannel(t, "https://res.openai.azure.com")
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("model", "whisper-1"))
+	file, err := writer.CreateFormFile("file", "a.wav")
+	require.NoError(t, err)
+	_, _ = file.Write([]byte("RIFF"))
+	require.NoError(t, writer.Close())
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", bytes.NewReader(body.Bytes()))
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	assert.Equal(t, "whisper-1", ch.ExtractModel(c, body.Bytes()))
+}
+
+func TestValidateAzureConfig(t *testing.T) {
+	assert.NoError(t, validateAzureConfig(nil))
+	assert.NoError(t, validateAzureConfig(map[string]any{"api_version": "2024-10-21", "deployments": map[string]any{"gpt-4o": "prod-4o"}}))
+	assert.Error(t, validateAzureConfig(map[string]any{"deployments": map[string]any{"gpt-4o": "../admin"}}))
+	assert.Error(t, validateAzureConfig(map[string]any{"api-version": "2024-10-21"}))
+}
+
+func TestAzureChannel_ValidateKey(t *testing.T) {
+	var gotPath, gotQuery, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery, gotKey = r.URL.Path, r.URL.RawQuery, r.Header.Get("api-key")
+		if gotKey != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":{"code":"401","message":"Access denied due to invalid subscription key."}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	ch := newTestAzureChannel(t, server.URL)
+	group := &models.Group{}
+
+	valid, err := ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "good"}, group)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, "/openai/deployments/prod-4o/chat/completions", gotPath)
+	assert.Equal(t, "api-version=2024-10-21", gotQuery)
+
+	valid, err = ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "bad"}, group)
+	assert.False(t, valid)
+	assert.ErrorContains(t, err, "invalid subscription key")
+}
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"gorm.io/datatypes"
 )
@@ -19,6 +20,8 @@ type UpstreamInfo struct {
 	URL           *url.URL
 	Weight        int
 	CurrentWeight int
+
+	*upstreamState
 }
 
 // BaseChannel provides common functionality for channel proxies.
@@ -29,15 +32,20 @@ type BaseChannel struct {
 	StreamClient       *http.Client
 	TestModel          string
 	ValidationEndpoint string
-	upstreamLock       sync.Mutex
+	upstreamLock       *sync.Mutex // shared with rebuilt channels of the same group, see upstreamRegistry
 
 	// Cached fields from the group for stale check
 	channelType     string
 	groupUpstreams  datatypes.JSON
+	channelConfig   datatypes.JSONMap
 	effectiveConfig *types.SystemSettings
 }
 
-// getUpstreamURL selects an upstream URL using a smooth weighted round-robin algorithm.
+// getUpstreamURL selects an upstream URL using the group's upstream selection strategy.
+// Weighted round-robin uses the smooth algorithm; the latency and outstanding-request strategies pick the
+// lowest cost and fall back to the round-robin order on ties.
+// Upstreams ejected by the circuit breaker are skipped; if every upstream is ejected, all of them are used
+// rather than failing the request outright.
 func (b *BaseChannel) getUpstreamURL() *url.URL {
 	b.upstreamLock.Lock()
 	defer b.upstreamLock.Unlock()
@@ -45,20 +53,35 @@ func (b *BaseChannel) getUpstreamURL() *url.URL {
 	if len(b.Upstreams) == 0 {
 		return nil
 	}
-	if len(b.Upstreams) == 1 {
-		return b.Upstreams[0].URL
+
+	now := time.Now()
+	cooloff := b.upstreamCooloff()
+	candidates := make([]*UpstreamInfo, 0, len(b.Upstreams))
+	for i := range b.Upstreams {
+		if b.Upstreams[i].health.available(now, cooloff) {
+			candidates = append(candidates, &b.Upstreams[i])
+		}
+	}
+	if len(candidates) == 0 {
+		for i := range b.Upstreams {
+			candidates = append(candidates, &b.Upstreams[i])
+		}
 	}
 
+	strategy := b.upstreamSelectionStrategy()
 	totalWeight := 0
 	var best *UpstreamInfo
+	var bestScore float64
 
-	for i := range b.Upstreams {
-		up := &b.Upstreams[i]
+	for _, up := range candidates {
 		totalWeight += up.Weight
 		up.CurrentWeight += up.Weight
-
-		if best == nil || up.CurrentWeight > best.CurrentWeight {
+	}
+	for _, up := range candidates {
+		score, scored := selectionScore(strategy, up, now)
+		if best == nil || (scored && score < bestScore) || ((!scored || score == bestScore) && up.CurrentWeight > best.CurrentWeight) {
 			best = up
+			bestScore = score
 		}
 	}
 
@@ -67,6 +90,9 @@ func (b *BaseChannel) getUpstreamURL() *url.URL {
 	}
 
 	best.CurrentWeight -= totalWeight
+	if best.health.state(now) == UpstreamStateHalfOpen {
+		best.health.probeStartedAt = now
+	}
 	return best.URL
 }
 
@@ -78,9 +104,7 @@ func (b *BaseChannel) BuildUpstreamURL(originalURL *url.URL, group *models.Group
 	}
 
 	finalURL := *base
-	proxyPrefix := "/proxy/" + group.Name
-	requestPath := originalURL.Path
-	requestPath = strings.TrimPrefix(requestPath, proxyPrefix)
+	requestPath := TrimProxyPrefix(originalURL.Path)
 
 	finalURL.Path = strings.TrimRight(finalURL.Path, "/") + requestPath
 
@@ -89,6 +113,19 @@ func (b *BaseChannel) BuildUpstreamURL(originalURL *url.URL, group *models.Group
 	return finalURL.String(), nil
 }
 
+// TrimProxyPrefix strips the "/proxy/<group>" prefix from a request path.
+// The group segment is not compared with the serving group, since a request may fall back to another group.
+func TrimProxyPrefix(requestPath string) string {
+	rest, ok := strings.CutPrefix(requestPath, "/proxy/")
+	if !ok {
+		return requestPath
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[i:]
+	}
+	return ""
+}
+
 // IsConfigStale checks if the channel's configuration is stale compared to the provided group.
 func (b *BaseChannel) IsConfigStale(group *models.Group) bool {
 	if b.channelType != group.ChannelType {
@@ -103,6 +140,9 @@ func (b *BaseChannel) IsConfigStale(group *models.Group) bool {
 	if !bytes.Equal(b.groupUpstreams, group.Upstreams) {
 		return true
 	}
+	if !reflect.DeepEqual(b.channelConfig, group.ChannelConfig) {
+		return true
+	}
 	if !reflect.DeepEqual(b.effectiveConfig, &group.EffectiveConfig) {
 		return true
 	}
@@ -118,3 +158,14 @@ func (b *BaseChannel) GetHTTPClient() *http.Client {
 func (b *BaseChannel) GetStreamClient() *http.Client {
 	return b.StreamClient
 }
+
+// bodyContainsAny reports whether the lower-cased error body contains any of the lower-case patterns.
+func bodyContainsAny(errorBody []byte, patterns ...string) bool {
+	lower := bytes.ToLower(errorBody)
+	for _, pattern := range patterns {
+		if bytes.Contains(lower, []byte(pattern)) {
+			return true
+		}
+	}
+	return false
+}
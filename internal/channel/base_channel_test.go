@@ -0,0 +1,38 @@
+package channel
+
+import (
+	"net/url"
+	"testing"
+
+	"gpt-load/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseChannel_BuildUpstreamURL(t *testing.T) {
+	b := newHealthTestChannel(t, 0, "https://api.example.com/base")
+	group := &models.Group{Name: "backup"}
+
+	tests := []struct {
+		name     string
+		original string
+		want     string
+	}{
+		{"own group prefix", "/proxy/backup/v1/chat/completions?x=1", "https://api.example.com/base/v1/chat/completions?x=1"},
+		{"fallback from another group", "/proxy/primary/v1/chat/completions", "https://api.example.com/base/v1/chat/completions"},
+		{"unified endpoint", "/v1/chat/completions", "https://api.example.com/base/v1/chat/completions"},
+		{"group root", "/proxy/primary", "https://api.example.com/base"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := url.Parse(tt.original)
+			require.NoError(t, err)
+
+			got, err := b.BuildUpstreamURL(original, group)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register("bedrock", newBedrockChannel)
+	RegisterConfigValidator("bedrock", validateBedrockConfig)
+}
+
+const (
+	bedrockAnthropicVersion = "bedrock-2023-05-31"
+	bedrockDefaultRegion    = "us-east-1"
+	bedrockSigningService   = "bedrock"
+	bedrockModelKey         = "bedrockModel"
+)
+
+var (
+	bedrockRegionPattern  = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+	bedrockModelIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+)
+
+// bedrockConfig is the channel_config of a bedrock group.
+type bedrockConfig struct {
+	// Region is the signing region. When empty it is taken from a bedrock-runtime.<region>.amazonaws.com
+	// upstream host, falling back to us-east-1.
+	Region string `json:"region"`
+	// ModelIDs maps client model names to Bedrock model or inference profile IDs. Unmapped models are sent as is.
+	ModelIDs map[string]string `json:"model_ids"`
+}
+
+// BedrockChannel serves Anthropic Messages API clients from AWS Bedrock. Keys are AWS credentials in the form
+// ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN] and every upstream request is signed with SigV4.
+// Bedrock native /model/<id>/invoke requests are passed through and only signed.
+type BedrockChannel struct {
+	*AnthropicChannel
+	config bedrockConfig
+}
+
+func newBedrockChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	var config bedrockConfig
+	if err := decodeChannelConfig(group.ChannelConfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid channel config for bedrock channel: %w", err)
+	}
+
+	base, err := f.newBaseChannel("bedrock", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BedrockChannel{
+		AnthropicChannel: &AnthropicChannel{BaseChannel: base},
+		config:           config,
+	}, nil
+}
+
+func validateBedrockConfig(config map[string]any) error {
+	var cfg bedrockConfig
+	if err := decodeChannelConfig(config, &cfg); err != nil {
+		return err
+	}
+	if cfg.Region != "" && !bedrockRegionPattern.MatchString(cfg.Region) {
+		return fmt.Errorf("invalid AWS region %q", cfg.Region)
+	}
+	for model, modelID := range cfg.ModelIDs {
+		if strings.TrimSpace(model) == "" {
+			return errors.New("model ID mapping has an empty model name")
+		}
+		if !bedrockModelIDPattern.MatchString(modelID) {
+			return fmt.Errorf("invalid Bedrock model ID %q for model %q", modelID, model)
+		}
+	}
+	return nil
+}
+
+// modelID returns the Bedrock model ID serving the model.
+func (ch *BedrockChannel) modelID(model string) string {
+	if modelID, ok := ch.config.ModelIDs[model]; ok {
+		return modelID
+	}
+	return model
+}
+
+// region returns the signing region for requests to the host.
+func (ch *BedrockChannel) region(host string) string {
+	if ch.config.Region != "" {
+		return ch.config.Region
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) >= 3 && strings.HasPrefix(parts[0], "bedrock") && bedrockRegionPattern.MatchString(parts[1]) {
+		return parts[1]
+	}
+	return bedrockDefaultRegion
+}
+
+// ModifyRequest signs the request with the key's AWS credentials.
+func (ch *BedrockChannel) ModifyRequest(req *http.Request, apiKey *models.APIKey, group *models.Group) {
+	creds, err := parseAWSCredentials(apiKey.KeyValue)
+	if err != nil {
+		logrus.WithField("key_id", apiKey.ID).Warn(err.Error())
+		return
+	}
+
+	var payload []byte
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			payload, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	// 模型 ID 中的 ':' 等字符按 AWS SDK 的方式编码后发送
+	req.URL.RawPath = awsEscapePath(req.URL.Path)
+	if strings.HasSuffix(req.URL.Path, "/invoke-with-response-stream") {
+		req.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	req.Header.Del("anthropic-version")
+	signAWSRequestV4(req, payload, creds, ch.region(req.URL.Hostname()), bedrockSigningService, time.Now())
+}
+
+// IsStreamRequest also treats Bedrock native streaming invocations as streams.
+func (ch *BedrockChannel) IsStreamRequest(c *gin.Context, bodyBytes []byte) bool {
+	if strings.HasSuffix(c.Request.URL.Path, "/invoke-with-response-stream") {
+		return true
+	}
+	return ch.AnthropicChannel.IsStreamRequest(c, bodyBytes)
+}
+
+// ExtractModel resolves the model from the body, the translated request, or the Bedrock native path.
+func (ch *BedrockChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
+	if model := ch.AnthropicChannel.ExtractModel(c, bodyBytes); model != "" {
+		return model
+	}
+	if model := c.GetString(bedrockModelKey); model != "" {
+		return model
+	}
+
+	parts := strings.Split(c.Request.URL.Path, "/")
+	for i, part := range parts {
+		if part == "model" && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// TranslatesRequest reports whether the request is an Anthropic Messages API call.
+func (ch *BedrockChannel) TranslatesRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodPost && strings.HasSuffix(c.Request.URL.Path, "/v1/messages")
+}
+
+// TranslateRequest turns a Messages API request into a Bedrock model invocation. The model moves from the body
+// into the path and the beta features from the anthropic-beta header into the body.
+func (ch *BedrockChannel) TranslateRequest(c *gin.Context, bodyBytes []byte) (*TranslatedRequest, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, fmt.Errorf("invalid messages request: %w", err)
+	}
+
+	var model string
+	if err := json.Unmarshal(body["model"], &model); err != nil || model == "" {
+		return nil, errors.New("model is required")
+	}
+	var stream bool
+	if raw, ok := body["stream"]; ok {
+		if err := json.Unmarshal(raw, &stream); err != nil {
+			return nil, fmt.Errorf("invalid stream field: %w", err)
+		}
+	}
+	delete(body, "model")
+	delete(body, "stream")
+
+	if _, ok := body["anthropic_version"]; !ok {
+		body["anthropic_version"] = jsonString(bedrockAnthropicVersion)
+	}
+	if beta := c.GetHeader("anthropic-beta"); beta != "" {
+		if _, ok := body["anthropic_beta"]; !ok {
+			var features []string
+			for _, feature := range strings.Split(beta, ",") {
+				if feature = strings.TrimSpace(feature); feature != "" {
+					features = append(features, feature)
+				}
+			}
+			body["anthropic_beta"], _ = json.Marshal(features)
+		}
+	}
+
+	out, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(bedrockModelKey, model)
+
+	// /proxy/<group>/v1/messages -> /proxy/<group>/model/<model id>/invoke
+	method := "/invoke"
+	if stream {
+		method = "/invoke-with-response-stream"
+	}
+	upstreamURL := *c.Request.URL
+	upstreamURL.Path = strings.TrimSuffix(upstreamURL.Path, "/v1/messages") + "/model/" + ch.modelID(model) + method
+	upstreamURL.RawPath = ""
+	upstreamURL.RawQuery = ""
+	return &TranslatedRequest{Body: out, URL: &upstreamURL}, nil
+}
+
+// TranslateResponse returns the response unchanged, as Bedrock returns Anthropic messages as is.
+func (ch *BedrockChannel) TranslateResponse(c *gin.Context, body []byte) ([]byte, error) {
+	return body, nil
+}
+
+// TranslateError converts a Bedrock error body into an Anthropic error body.
+func (ch *BedrockChannel) TranslateError(statusCode int, body []byte) []byte {
+	message := bedrockErrorMessage(body)
+	if message == "" {
+		return body
+	}
+	return newAnthropicError(statusCode, message)
+}
+
+// NewStreamTranslator returns a reader of the Bedrock event stream that yields the Anthropic events it carries.
+func (ch *BedrockChannel) NewStreamTranslator(c *gin.Context) StreamTranslator {
+	return bedrockStream{}
+}
+
+// bedrockErrorMessage extracts the message of a Bedrock error body.
+func bedrockErrorMessage(body []byte) string {
+	var bedrockErr struct {
+		Message      string `json:"message"`
+		UpperMessage string `json:"Message"`
+	}
+	if err := json.Unmarshal(body, &bedrockErr); err != nil {
+		return ""
+	}
+	if bedrockErr.Message != "" {
+		return bedrockErr.Message
+	}
+	return bedrockErr.UpperMessage
+}
+
+// bedrockStream decodes the event stream framing of InvokeModelWithResponseStream. Each chunk carries one
+// base64-encoded Anthropic stream event, which is forwarded as a server-sent event.
+type bedrockStream struct{}
+
+func (bedrockStream) ReadEvents(r io.Reader, fn func(SSEEvent) error) error {
+	return readAWSEventStream(r, func(msg awsEventStreamMessage) error {
+		switch msg.Headers[":message-type"] {
+		case "event":
+			var chunk struct {
+				Bytes []byte `json:"bytes"`
+			}
+			if err := json.Unmarshal(msg.Payload, &chunk); err != nil || len(chunk.Bytes) == 0 {
+				return nil
+			}
+			var event struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal(chunk.Bytes, &event)
+			return fn(SSEEvent{Event: event.Type, Data: chunk.Bytes})
+		case "exception", "error":
+			message := bedrockErrorMessage(msg.Payload)
+			if message == "" {
+				message = msg.Headers[":error-message"]
+			}
+			errorType := msg.Headers[":exception-type"]
+			if errorType == "" {
+				errorType = msg.Headers[":error-code"]
+			}
+			body, _ := json.Marshal(anthropicErrorResponse{Type: "error", Error: anthropicError{
+				Type:    bedrockExceptionToAnthropicErrorType(errorType),
+				Message: message,
+			}})
+			return fn(SSEEvent{Event: "error", Data: body})
+		}
+		return nil
+	})
+}
+
+func (bedrockStream) TranslateEvent(event SSEEvent) ([]SSEEvent, error) {
+	return []SSEEvent{event}, nil
+}
+
+func (bedrockStream) Finish() []SSEEvent {
+	return nil
+}
+
+func bedrockExceptionToAnthropicErrorType(exceptionType string) string {
+	switch exceptionType {
+	case "throttlingException":
+		return "rate_limit_error"
+	case "validationException":
+		return "invalid_request_error"
+	case "serviceUnavailableException":
+		return "overloaded_error"
+	}
+	return "api_error"
+}
+
+// ValidateKey checks if the given AWS credentials are valid by invoking the test model.
+func (ch *BedrockChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
+	upstreamURL := ch.getUpstreamURL()
+	if upstreamURL == nil {
+		return false, fmt.Errorf("no upstream URL configured for channel %s", ch.Name)
+	}
+	creds, err := parseAWSCredentials(apiKey.KeyValue)
+	if err != nil {
+		return false, err
+	}
+
+	validationEndpoint := ch.ValidationEndpoint
+	if validationEndpoint == "" {
+		validationEndpoint = "/model/" + ch.modelID(ch.TestModel) + "/invoke"
+	}
+	reqURL, err := url.JoinPath(upstreamURL.String(), validationEndpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to join upstream URL and validation endpoint: %w", err)
+	}
+
+	payload := gin.H{
+		"anthropic_version": bedrockAnthropicVersion,
+		"max_tokens":        1,
+		"messages": []gin.H{
+			{"role": "user", "content": "hi"},
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal validation payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, bytes.NewBuffer(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create validation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	// Apply custom header rules if available
+	if len(group.HeaderRuleList) > 0 {
+		headerCtx := utils.NewHeaderVariableContext(group, apiKey)
+		utils.ApplyHeaderRules(req, group.HeaderRuleList, headerCtx)
+	}
+
+	req.URL.RawPath = awsEscapePath(req.URL.Path)
+	signAWSRequestV4(req, body, creds, ch.region(req.URL.Hostname()), bedrockSigningService, time.Now())
+
+	resp, err := ch.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send validation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Any 2xx status code indicates the key is valid.
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+
+	// For non-200 responses, parse the body to provide a more specific error reason.
+	errorBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("key is invalid (status %d), but failed to read error body: %w", resp.StatusCode, err)
+	}
+
+	parsedError := bedrockErrorMessage(errorBody)
+	if parsedError == "" {
+		parsedError = app_errors.ParseUpstreamError(errorBody)
+	}
+
+	return false, fmt.Errorf("[status %d] %s", resp.StatusCode, parsedError)
+}
@@ -0,0 +1,185 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"gpt-load/internal/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBedrockChannel(t *testing.T, upstream string) *BedrockChannel {
+	base := newHealthTestChannel(t, 5, upstream)
+	base.HTTPClient = http.DefaultClient
+	base.TestModel = "claude-haiku"
+	return &BedrockChannel{
+		AnthropicChannel: &AnthropicChannel{BaseChannel: base},
+		config: bedrockConfig{
+			Region:   "us-west-2",
+			ModelIDs: map[string]string{"claude-haiku": "anthropic.claude-3-haiku-20240307-v1:0"},
+		},
+	}
+}
+
+// newSigV4Stub returns a server that accepts only requests whose SigV4 signature it can reproduce for the secret.
+func newSigV4Stub(t *testing.T, secret string, handler http.HandlerFunc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"message":"Missing Authentication Token"}`)
+			return
+		}
+
+		auth := r.Header.Get("Authorization")
+		credential, _, _ := strings.Cut(strings.TrimPrefix(auth, awsSigningAlgorithm+" Credential="), ",")
+		scope := strings.Split(credential, "/")
+		if len(scope) != 5 {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"message":"Authorization header requires 'Credential' parameter."}`)
+			return
+		}
+
+		expected, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+		signAWSRequestV4(expected, body, awsCredentials{
+			AccessKeyID:     scope[0],
+			SecretAccessKey: secret,
+			SessionToken:    r.Header.Get("X-Amz-Security-Token"),
+		}, scope[2], scope[3], signedAt)
+		if expected.Header.Get("Authorization") != auth {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"message":"The request signature we calculated does not match the signature you provided."}`)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		handler(w, r)
+	}))
+}
+
+func TestBedrockChannel_ModifyRequestSignsRequest(t *testing.T) {
+	var gotPath, gotAccept string
+	server := newSigV4Stub(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAccept = r.URL.EscapedPath(), r.Header.Get("Accept")
+		_, _ = io.WriteString(w, `{}`)
+	})
+	defer server.Close()
+
+	ch := newTestBedrockChannel(t, server.URL)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/proxy/br/v1/messages", nil)
+	c.Request.Header.Set("anthropic-beta", "beta-a, beta-b")
+	translated, err := ch.TranslateRequest(c, []byte(`{"model":"claude-haiku","stream":true,"max_tokens":5,"messages":[]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"anthropic_version":"bedrock-2023-05-31","anthropic_beta":["beta-a","beta-b"],"max_tokens":5,"messages":[]}`, string(translated.Body))
+	assert.Equal(t, "claude-haiku", ch.ExtractModel(c, translated.Body))
+
+	upstreamURL, err := ch.BuildUpstreamURL(translated.URL, &models.Group{})
+	require.NoError(t, err)
+	for key, wantStatus := range map[string]int{"AKID:secret:session": http.StatusOK, "AKID:wrong": http.StatusForbidden} {
+		req, err := http.NewRequest(http.MethodPost, upstreamURL, bytes.NewReader(translated.Body))
+		require.NoError(t, err)
+		ch.ModifyRequest(req, &models.APIKey{KeyValue: key}, &models.Group{})
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, wantStatus, resp.StatusCode, key)
+	}
+	assert.Equal(t, "/model/anthropic.claude-3-haiku-20240307-v1%3A0/invoke-with-response-stream", gotPath)
+	assert.Equal(t, "application/vnd.amazon.eventstream", gotAccept)
+}
+
+func TestBedrockChannel_ValidateKey(t *testing.T) {
+	var gotPath string
+	server := newSigV4Stub(t, "secret", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = io.WriteString(w, `{"type":"message"}`)
+	})
+	defer server.Close()
+
+	ch := newTestBedrockChannel(t, server.URL)
+	group := &models.Group{}
+
+	valid, err := ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "AKID:secret"}, group)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke", gotPath)
+
+	valid, err = ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "AKID:wrong"}, group)
+	assert.False(t, valid)
+	assert.ErrorContains(t, err, "signature we calculated does not match")
+}
+
+func TestBedrockStream(t *testing.T) {
+	chunk := func(event string) []byte {
+		payload, _ := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString([]byte(event))})
+		return encodeAWSEventStreamMessage(map[string]string{":message-type": "event", ":event-type": "chunk"}, payload)
+	}
+	var upstream bytes.Buffer
+	upstream.Write(chunk(`{"type":"message_start","message":{"id":"msg_1"}}`))
+	upstream.Write(chunk(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`))
+	upstream.Write(encodeAWSEventStreamMessage(map[string]string{":message-type": "exception", ":exception-type": "throttlingException"},
+		[]byte(`{"message":"Too many requests"}`)))
+
+	ch := newTestBedrockChannel(t, "https://bedrock-runtime.us-west-2.amazonaws.com")
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	stream := ch.NewStreamTranslator(c)
+
+	var out bytes.Buffer
+	err := ReadStream(stream, &upstream, func(event SSEEvent) error {
+		events, err := stream.TranslateEvent(event)
+		for _, e := range events {
+			require.NoError(t, WriteSSE(&out, e))
+		}
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"msg_1"}}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`,
+		``,
+		`event: error`,
+		`data: {"type":"error","error":{"type":"rate_limit_error","message":"Too many requests"}}`,
+		``,
+		``,
+	}, "\n"), out.String())
+}
+
+func TestBedrockChannel_Region(t *testing.T) {
+	ch := &BedrockChannel{}
+	assert.Equal(t, "eu-central-1", ch.region("bedrock-runtime.eu-central-1.amazonaws.com"))
+	assert.Equal(t, "us-east-1", ch.region("127.0.0.1"))
+
+	ch.config.Region = "ap-northeast-1"
+	assert.Equal(t, "ap-northeast-1", ch.region("bedrock-runtime.eu-central-1.amazonaws.com"))
+}
+
+func TestValidateBedrockConfig(t *testing.T) {
+	assert.NoError(t, validateBedrockConfig(nil))
+	assert.NoError(t, validateBedrockConfig(map[string]any{"region": "us-gov-west-1", "model_ids": map[string]any{"claude": "us.anthropic.claude-3-7-sonnet-20250219-v1:0"}}))
+	assert.Error(t, validateBedrockConfig(map[string]any{"region": "us east"}))
+	assert.Error(t, validateBedrockConfig(map[string]any{"model_ids": map[string]any{"claude": "../admin"}}))
+	assert.Error(t, validateBedrockConfig(map[string]any{"model_id": map[string]any{}}))
+}
+
+func TestBedrockChannel_TranslateError(t *testing.T) {
+	ch := &BedrockChannel{}
+
+	out := ch.TranslateError(400, []byte(`{"message":"Malformed input request"}`))
+	assert.JSONEq(t, `{"type":"error","error":{"type":"invalid_request_error","message":"Malformed input request"}}`, string(out))
+
+	assert.Equal(t, "not json", string(ch.TranslateError(500, []byte("not json"))))
+}
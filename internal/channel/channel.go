@@ -5,6 +5,7 @@ import (
 	"gpt-load/internal/models"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,6 +33,35 @@ type ChannelProxy interface {
 	// ExtractModel extracts the model name from the request.
 	ExtractModel(c *gin.Context, bodyBytes []byte) string
 
+	// IsQuotaExhausted reports whether a failed upstream response means the key's quota is used up
+	// until the provider's next quota reset, as opposed to a transient or per-minute rate limit.
+	IsQuotaExhausted(statusCode int, errorBody []byte) bool
+
+	// RecordUpstreamResult feeds the outcome and response-header latency of a request to upstreamURL
+	// into the upstream's passive health and latency statistics.
+	RecordUpstreamResult(upstreamURL string, statusCode int, err error, latency time.Duration)
+
+	// TrackUpstreamRequest marks a request to upstreamURL as in flight; the returned function ends it.
+	TrackUpstreamRequest(upstreamURL string) func()
+
+	// UpstreamHealth returns a snapshot of the health of all upstreams.
+	UpstreamHealth() []UpstreamHealth
+
 	// ValidateKey checks if the given API key is valid.
 	ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error)
 }
+
+// RequestURLRewriter is implemented by channels whose upstream path depends on the request body,
+// such as Azure OpenAI, which addresses models through deployment paths.
+type RequestURLRewriter interface {
+	// RewriteRequestURL returns the request URL from which the upstream URL is built.
+	RewriteRequestURL(c *gin.Context, requestURL *url.URL, bodyBytes []byte) (*url.URL, error)
+}
+
+// KeylessChannel is implemented by channels whose upstreams need no API key, such as self-hosted model
+// servers. The proxy skips key selection for them and their upstreams are probed instead of keys validated.
+type KeylessChannel interface {
+	// CheckUpstreams probes every upstream, feeding the results into the upstream health statistics,
+	// and returns an error describing the upstreams that failed.
+	CheckUpstreams(ctx context.Context, group *models.Group) error
+}
@@ -0,0 +1,339 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register("custom", newCustomChannel)
+	RegisterConfigValidator("custom", validateCustomConfig)
+}
+
+const (
+	customAuthInHeader = "header"
+	customAuthInQuery  = "query"
+	// customTestModelVariable is replaced by the group's test model in the validation request.
+	customTestModelVariable = "${TEST_MODEL}"
+)
+
+var customHeaderNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// customConfig is the channel_config of a custom group, declaring how an OpenAI-like provider is called.
+type customConfig struct {
+	Auth   customAuthConfig   `json:"auth"`
+	Stream customStreamConfig `json:"stream"`
+	// ModelPath is the dotted JSON path of the model in request bodies, "model" by default.
+	ModelPath string `json:"model_path"`
+	// QuotaExhaustedPatterns are case-insensitive error body fragments meaning the key's quota is used up.
+	QuotaExhaustedPatterns []string               `json:"quota_exhausted_patterns"`
+	Validation             customValidationConfig `json:"validation"`
+}
+
+// customAuthConfig declares where the key is sent.
+type customAuthConfig struct {
+	// In is "header" (default) or "query".
+	In string `json:"in"`
+	// Name is the header or query parameter, Authorization by default for headers.
+	Name string `json:"name"`
+	// Template is the value sent, resolved with the header rule variables such as ${API_KEY}.
+	// It defaults to "Bearer ${API_KEY}" for the Authorization header and "${API_KEY}" otherwise.
+	Template string `json:"template"`
+}
+
+// customStreamConfig declares how streaming requests are recognized, in addition to an
+// Accept: text/event-stream request header.
+type customStreamConfig struct {
+	// BodyField is the dotted JSON path of a boolean body field enabling streaming, "stream" by default.
+	BodyField string `json:"body_field"`
+	// PathSuffixes are request path suffixes of streaming endpoints, e.g. ":streamGenerate".
+	PathSuffixes []string `json:"path_suffixes"`
+	// Query are query parameter values enabling streaming, e.g. {"stream": "true"}.
+	Query map[string]string `json:"query"`
+}
+
+// customValidationConfig is the template of the key validation request.
+type customValidationConfig struct {
+	// Method is POST by default.
+	Method string `json:"method"`
+	// Path is the group's validation endpoint or /v1/chat/completions by default.
+	Path string `json:"path"`
+	// Headers are added to the request, resolved with the header rule variables.
+	Headers map[string]string `json:"headers"`
+	// Body is the JSON body, with ${TEST_MODEL} replaced by the group's test model. It defaults to an
+	// OpenAI chat completion request; GET and HEAD requests have no body.
+	Body json.RawMessage `json:"body"`
+}
+
+// CustomChannel is a channel for OpenAI-like providers whose differences are declared in the group's
+// channel_config instead of code.
+type CustomChannel struct {
+	*BaseChannel
+	config customConfig
+}
+
+func newCustomChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	var config customConfig
+	if err := decodeChannelConfig(group.ChannelConfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid channel config for custom channel: %w", err)
+	}
+	config.applyDefaults()
+
+	base, err := f.newBaseChannel("custom", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomChannel{
+		BaseChannel: base,
+		config:      config,
+	}, nil
+}
+
+func (cfg *customConfig) applyDefaults() {
+	if cfg.Auth.In == "" {
+		cfg.Auth.In = customAuthInHeader
+	}
+	if cfg.Auth.Name == "" && cfg.Auth.In == customAuthInHeader {
+		cfg.Auth.Name = "Authorization"
+	}
+	if cfg.Auth.Template == "" {
+		cfg.Auth.Template = "${API_KEY}"
+		if strings.EqualFold(cfg.Auth.Name, "Authorization") {
+			cfg.Auth.Template = "Bearer ${API_KEY}"
+		}
+	}
+	if cfg.Stream.BodyField == "" {
+		cfg.Stream.BodyField = "stream"
+	}
+	if cfg.ModelPath == "" {
+		cfg.ModelPath = "model"
+	}
+	if cfg.Validation.Method == "" {
+		cfg.Validation.Method = http.MethodPost
+	}
+	cfg.Validation.Method = strings.ToUpper(cfg.Validation.Method)
+}
+
+func validateCustomConfig(config map[string]any) error {
+	var cfg customConfig
+	if err := decodeChannelConfig(config, &cfg); err != nil {
+		return err
+	}
+
+	switch cfg.Auth.In {
+	case "", customAuthInHeader:
+		if cfg.Auth.Name != "" && !customHeaderNamePattern.MatchString(cfg.Auth.Name) {
+			return fmt.Errorf("invalid auth header name %q", cfg.Auth.Name)
+		}
+	case customAuthInQuery:
+		if cfg.Auth.Name == "" {
+			return errors.New("auth query parameter name is required")
+		}
+	default:
+		return fmt.Errorf("invalid auth placement %q, expected header or query", cfg.Auth.In)
+	}
+	if cfg.Auth.Template != "" && !strings.Contains(cfg.Auth.Template, "${API_KEY}") {
+		return errors.New("auth template must contain ${API_KEY}")
+	}
+
+	for _, path := range []string{cfg.ModelPath, cfg.Stream.BodyField} {
+		if path == "" {
+			continue
+		}
+		if _, err := utils.SplitJSONPath(path); err != nil {
+			return err
+		}
+	}
+	for _, suffix := range cfg.Stream.PathSuffixes {
+		if suffix == "" {
+			return errors.New("stream path suffixes must not be empty")
+		}
+	}
+
+	switch strings.ToUpper(cfg.Validation.Method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("unsupported validation method %q", cfg.Validation.Method)
+	}
+	if cfg.Validation.Path != "" && !strings.HasPrefix(cfg.Validation.Path, "/") {
+		return fmt.Errorf("validation path %q must start with /", cfg.Validation.Path)
+	}
+	for name := range cfg.Validation.Headers {
+		if !customHeaderNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid validation header name %q", name)
+		}
+	}
+	return nil
+}
+
+// applyAuth sends the key as declared by the auth config.
+func (ch *CustomChannel) applyAuth(req *http.Request, apiKey *models.APIKey, group *models.Group) {
+	value := utils.ResolveHeaderVariables(ch.config.Auth.Template, utils.NewHeaderVariableContext(group, apiKey))
+	if ch.config.Auth.In == customAuthInQuery {
+		q := req.URL.Query()
+		q.Set(ch.config.Auth.Name, value)
+		req.URL.RawQuery = q.Encode()
+		return
+	}
+	req.Header.Set(ch.config.Auth.Name, value)
+}
+
+// ModifyRequest sends the key in the declared header or query parameter.
+func (ch *CustomChannel) ModifyRequest(req *http.Request, apiKey *models.APIKey, group *models.Group) {
+	ch.applyAuth(req, apiKey, group)
+}
+
+// IsStreamRequest checks the declared path suffixes, query parameters and body field.
+func (ch *CustomChannel) IsStreamRequest(c *gin.Context, bodyBytes []byte) bool {
+	if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		return true
+	}
+	for _, suffix := range ch.config.Stream.PathSuffixes {
+		if strings.HasSuffix(c.Request.URL.Path, suffix) {
+			return true
+		}
+	}
+	for param, value := range ch.config.Stream.Query {
+		if actual, ok := c.GetQuery(param); ok && actual == value {
+			return true
+		}
+	}
+
+	var body any
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		return false
+	}
+	stream, _ := utils.GetJSONPath(body, ch.config.Stream.BodyField)
+	return stream == true
+}
+
+// ExtractModel reads the model from the declared body path.
+func (ch *CustomChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
+	var body any
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		return ""
+	}
+	model, _ := utils.GetJSONPath(body, ch.config.ModelPath)
+	s, _ := model.(string)
+	return s
+}
+
+// IsQuotaExhausted matches the error body against the declared quota patterns.
+func (ch *CustomChannel) IsQuotaExhausted(statusCode int, errorBody []byte) bool {
+	patterns := make([]string, 0, len(ch.config.QuotaExhaustedPatterns))
+	for _, pattern := range ch.config.QuotaExhaustedPatterns {
+		patterns = append(patterns, strings.ToLower(pattern))
+	}
+	return bodyContainsAny(errorBody, patterns...)
+}
+
+// validationRequestBody returns the declared validation body with the test model filled in.
+func (ch *CustomChannel) validationRequestBody() ([]byte, error) {
+	if ch.config.Validation.Method == http.MethodGet || ch.config.Validation.Method == http.MethodHead {
+		return nil, nil
+	}
+	if len(ch.config.Validation.Body) == 0 {
+		return json.Marshal(gin.H{
+			"model": ch.TestModel,
+			"messages": []gin.H{
+				{"role": "user", "content": "hi"},
+			},
+		})
+	}
+
+	// 以 JSON 字符串内容替换，避免模型名破坏模板结构
+	model, err := json.Marshal(ch.TestModel)
+	if err != nil {
+		return nil, err
+	}
+	body := bytes.ReplaceAll(ch.config.Validation.Body, []byte(customTestModelVariable), model[1:len(model)-1])
+	if !json.Valid(body) {
+		return nil, errors.New("validation body template is not valid JSON")
+	}
+	return body, nil
+}
+
+// ValidateKey checks if the given API key is valid by sending the declared validation request.
+func (ch *CustomChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
+	upstreamURL := ch.getUpstreamURL()
+	if upstreamURL == nil {
+		return false, fmt.Errorf("no upstream URL configured for channel %s", ch.Name)
+	}
+
+	validationEndpoint := ch.config.Validation.Path
+	if validationEndpoint == "" {
+		validationEndpoint = ch.ValidationEndpoint
+	}
+	if validationEndpoint == "" {
+		validationEndpoint = "/v1/chat/completions"
+	}
+	endpoint, err := url.Parse(validationEndpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse validation endpoint: %w", err)
+	}
+	reqURL, err := url.JoinPath(upstreamURL.String(), endpoint.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to join upstream URL and validation endpoint: %w", err)
+	}
+	if endpoint.RawQuery != "" {
+		reqURL += "?" + endpoint.RawQuery
+	}
+
+	body, err := ch.validationRequestBody()
+	if err != nil {
+		return false, fmt.Errorf("failed to build validation payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, ch.config.Validation.Method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create validation request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	headerCtx := utils.NewHeaderVariableContext(group, apiKey)
+	for name, value := range ch.config.Validation.Headers {
+		req.Header.Set(name, utils.ResolveHeaderVariables(value, headerCtx))
+	}
+	ch.applyAuth(req, apiKey, group)
+
+	// Apply custom header rules if available
+	if len(group.HeaderRuleList) > 0 {
+		utils.ApplyHeaderRules(req, group.HeaderRuleList, headerCtx)
+	}
+
+	resp, err := ch.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send validation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Any 2xx status code indicates the key is valid.
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+
+	// For non-200 responses, parse the body to provide a more specific error reason.
+	errorBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("key is invalid (status %d), but failed to read error body: %w", resp.StatusCode, err)
+	}
+
+	parsedError := app_errors.ParseUpstreamError(errorBody)
+
+	return false, fmt.Errorf("[status %d] %s", resp.StatusCode, parsedError)
+}
@@ -0,0 +1,157 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"gpt-load/internal/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCustomChannel(t *testing.T, upstream string, config map[string]any) *CustomChannel {
+	require.NoError(t, validateCustomConfig(config))
+	var cfg customConfig
+	require.NoError(t, decodeChannelConfig(config, &cfg))
+	cfg.applyDefaults()
+
+	base := newHealthTestChannel(t, 5, upstream)
+	base.HTTPClient = http.DefaultClient
+	base.TestModel = "test-model"
+	return &CustomChannel{BaseChannel: base, config: cfg}
+}
+
+func TestCustomChannel_ModifyRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+		check  func(t *testing.T, req *http.Request)
+	}{
+		{"default bearer", nil, func(t *testing.T, req *http.Request) {
+			assert.Equal(t, "Bearer sk-1", req.Header.Get("Authorization"))
+		}},
+		{"header template", map[string]any{"auth": map[string]any{"name": "X-Token", "template": "Token ${API_KEY}"}}, func(t *testing.T, req *http.Request) {
+			assert.Equal(t, "Token sk-1", req.Header.Get("X-Token"))
+			assert.Empty(t, req.Header.Get("Authorization"))
+		}},
+		{"query parameter", map[string]any{"auth": map[string]any{"in": "query", "name": "access_token"}}, func(t *testing.T, req *http.Request) {
+			assert.Equal(t, "sk-1", req.URL.Query().Get("access_token"))
+			assert.Equal(t, "v", req.URL.Query().Get("keep"))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := newTestCustomChannel(t, "http://upstream", tt.config)
+			req := httptest.NewRequest(http.MethodPost, "http://upstream/v1/chat/completions?keep=v", nil)
+			ch.ModifyRequest(req, &models.APIKey{KeyValue: "sk-1"}, &models.Group{})
+			tt.check(t, req)
+		})
+	}
+}
+
+func TestCustomChannel_StreamAndModel(t *testing.T) {
+	ch := newTestCustomChannel(t, "http://upstream", map[string]any{
+		"model_path": "input.model",
+		"stream": map[string]any{
+			"body_field":    "parameters.incremental_output",
+			"path_suffixes": []any{":streamGenerate"},
+			"query":         map[string]any{"sse": "enable"},
+		},
+	})
+
+	tests := []struct {
+		path, body string
+		stream     bool
+	}{
+		{"/v1/generate", `{"parameters":{"incremental_output":true}}`, true},
+		{"/v1/generate", `{"parameters":{"incremental_output":false},"stream":true}`, false},
+		{"/v1/models/m:streamGenerate", `{}`, true},
+		{"/v1/generate?sse=enable", `{}`, true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		assert.Equal(t, tt.stream, ch.IsStreamRequest(c, []byte(tt.body)), tt.path+" "+tt.body)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/generate", nil)
+	assert.Equal(t, "qwen-max", ch.ExtractModel(c, []byte(`{"model":"other","input":{"model":"qwen-max"}}`)))
+	assert.Empty(t, ch.ExtractModel(c, []byte(`{"input":{"model":1}}`)))
+
+	// 配置为空值的查询参数必须出现在请求中才表示流式
+	ch = newTestCustomChannel(t, "http://upstream", map[string]any{"stream": map[string]any{"query": map[string]any{"stream": ""}}})
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/generate", nil)
+	assert.False(t, ch.IsStreamRequest(c, []byte(`{}`)))
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/generate?stream", nil)
+	assert.True(t, ch.IsStreamRequest(c, []byte(`{}`)))
+}
+
+func TestCustomChannel_ValidateKey(t *testing.T) {
+	var gotMethod, gotPath, gotKey, gotHeader string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotKey, gotHeader = r.Method, r.URL.RequestURI(), r.Header.Get("X-Api-Key"), r.Header.Get("X-Group")
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if gotKey != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":{"message":"invalid key"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	ch := newTestCustomChannel(t, server.URL, map[string]any{
+		"auth": map[string]any{"name": "X-Api-Key"},
+		"validation": map[string]any{
+			"path":    "/api/v2/generate?version=2",
+			"headers": map[string]any{"X-Group": "${GROUP_NAME}"},
+			"body":    map[string]any{"input": map[string]any{"model": "${TEST_MODEL}", "prompt": "hi"}},
+		},
+	})
+	group := &models.Group{Name: "g1"}
+
+	valid, err := ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "good"}, group)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, http.MethodPost, gotMethod)
+	assert.Equal(t, "/api/v2/generate?version=2", gotPath)
+	assert.Equal(t, "g1", gotHeader)
+	assert.Equal(t, map[string]any{"input": map[string]any{"model": "test-model", "prompt": "hi"}}, gotBody)
+
+	valid, err = ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "bad"}, group)
+	assert.False(t, valid)
+	assert.ErrorContains(t, err, "invalid key")
+
+	ch = newTestCustomChannel(t, server.URL, map[string]any{
+		"auth":       map[string]any{"name": "X-Api-Key"},
+		"validation": map[string]any{"method": "get", "path": "/v1/models"},
+	})
+	valid, err = ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "good"}, group)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, http.MethodGet, gotMethod)
+	assert.Nil(t, gotBody)
+}
+
+func TestValidateCustomConfig(t *testing.T) {
+	assert.NoError(t, validateCustomConfig(nil))
+	assert.Error(t, validateCustomConfig(map[string]any{"auth": map[string]any{"in": "cookie"}}))
+	assert.Error(t, validateCustomConfig(map[string]any{"auth": map[string]any{"in": "query"}}))
+	assert.Error(t, validateCustomConfig(map[string]any{"auth": map[string]any{"name": "Bad Header"}}))
+	assert.Error(t, validateCustomConfig(map[string]any{"auth": map[string]any{"template": "static"}}))
+	assert.Error(t, validateCustomConfig(map[string]any{"model_path": "input..model"}))
+	assert.Error(t, validateCustomConfig(map[string]any{"validation": map[string]any{"method": "DELETE"}}))
+	assert.Error(t, validateCustomConfig(map[string]any{"validation": map[string]any{"path": "v1/models"}}))
+	assert.Error(t, validateCustomConfig(map[string]any{"unknown": true}))
+}
@@ -1,6 +1,7 @@
 package channel
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"gpt-load/internal/config"
@@ -16,9 +17,14 @@ import (
 // channelConstructor defines the function signature for creating a new channel proxy.
 type channelConstructor func(f *Factory, group *models.Group) (ChannelProxy, error)
 
+// channelConfigValidator validates the channel_config of a group for a channel type.
+type channelConfigValidator func(config map[string]any) error
+
 var (
 	// channelRegistry holds the mapping from channel type string to its constructor.
 	channelRegistry = make(map[string]channelConstructor)
+	// channelConfigValidators holds the channel_config validators of the channel types that use it.
+	channelConfigValidators = make(map[string]channelConfigValidator)
 )
 
 // Register adds a new channel constructor to the registry.
@@ -29,6 +35,38 @@ func Register(channelType string, constructor channelConstructor) {
 	channelRegistry[channelType] = constructor
 }
 
+// RegisterConfigValidator adds a channel_config validator for a channel type.
+func RegisterConfigValidator(channelType string, validator channelConfigValidator) {
+	if _, exists := channelConfigValidators[channelType]; exists {
+		panic(fmt.Sprintf("config validator for channel type '%s' is already registered", channelType))
+	}
+	channelConfigValidators[channelType] = validator
+}
+
+// ValidateChannelConfig checks the channel_config of a group against its channel type.
+// Channel types without a validator accept any configuration.
+func ValidateChannelConfig(channelType string, config map[string]any) error {
+	validator, ok := channelConfigValidators[channelType]
+	if !ok {
+		return nil
+	}
+	return validator(config)
+}
+
+// decodeChannelConfig decodes a group's channel_config into the channel's config struct, rejecting unknown fields.
+func decodeChannelConfig(config map[string]any, out any) error {
+	if len(config) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(out)
+}
+
 // GetChannels returns a slice of all registered channel type names.
 func GetChannels() []string {
 	supportedTypes := make([]string, 0, len(channelRegistry))
@@ -43,7 +81,9 @@ type Factory struct {
 	settingsManager *config.SystemSettingsManager
 	clientManager   *httpclient.HTTPClientManager
 	channelCache    map[uint]ChannelProxy
+	upstreamStates  map[uint]*upstreamRegistry
 	cacheLock       sync.Mutex
+	googleTokens    *googleTokenCache
 }
 
 // NewFactory creates a new channel factory.
@@ -52,6 +92,8 @@ func NewFactory(settingsManager *config.SystemSettingsManager, clientManager *ht
 		settingsManager: settingsManager,
 		clientManager:   clientManager,
 		channelCache:    make(map[uint]ChannelProxy),
+		upstreamStates:  make(map[uint]*upstreamRegistry),
+		googleTokens:    newGoogleTokenCache(),
 	}
 }
 
@@ -97,6 +139,7 @@ func (f *Factory) newBaseChannel(name string, group *models.Group) (*BaseChannel
 	}
 
 	var upstreamInfos []UpstreamInfo
+	var upstreamURLs []string
 	for _, def := range defs {
 		u, err := url.Parse(def.URL)
 		if err != nil {
@@ -106,6 +149,13 @@ func (f *Factory) newBaseChannel(name string, group *models.Group) (*BaseChannel
 			continue
 		}
 		upstreamInfos = append(upstreamInfos, UpstreamInfo{URL: u, Weight: def.Weight})
+		upstreamURLs = append(upstreamURLs, u.String())
+	}
+
+	// Health and load carry over from the group's previous channel.
+	registry := f.upstreamRegistry(group.ID, upstreamURLs)
+	for i := range upstreamInfos {
+		upstreamInfos[i].upstreamState = registry.states[upstreamURLs[i]]
 	}
 
 	// Base configuration for regular requests, derived from the group's effective settings.
@@ -146,8 +196,10 @@ func (f *Factory) newBaseChannel(name string, group *models.Group) (*BaseChannel
 		StreamClient:       streamClient,
 		TestModel:          group.TestModel,
 		ValidationEndpoint: group.ValidationEndpoint,
+		upstreamLock:       &registry.lock,
 		channelType:        group.ChannelType,
 		groupUpstreams:     group.Upstreams,
+		channelConfig:      group.ChannelConfig,
 		effectiveConfig:    &group.EffectiveConfig,
 	}, nil
 }
@@ -95,6 +95,18 @@ func (ch *GeminiChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
 	return ""
 }
 
+// IsQuotaExhausted detects Gemini's RESOURCE_EXHAUSTED errors. Per-minute limits are left to the
+// regular retry handling, since they recover long before the daily quota reset.
+func (ch *GeminiChannel) IsQuotaExhausted(statusCode int, errorBody []byte) bool {
+	if statusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if bodyContainsAny(errorBody, "perminute") {
+		return false
+	}
+	return bodyContainsAny(errorBody, "resource has been exhausted", "resource_exhausted", "exceeded your current quota")
+}
+
 // ValidateKey checks if the given API key is valid by making a generateContent request.
 func (ch *GeminiChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
 	upstreamURL := ch.getUpstreamURL()
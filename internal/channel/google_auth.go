@@ -0,0 +1,213 @@
+package channel
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Google 服务账号 OAuth 访问令牌签发（JWT bearer 流程）与缓存，供 Vertex 渠道使用
+
+const (
+	googleDefaultTokenURL    = "https://oauth2.googleapis.com/token"
+	googleCloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	googleJWTBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	googleAssertionLifetime  = time.Hour
+	// googleTokenRefreshMargin is how long before expiry a cached token is replaced.
+	googleTokenRefreshMargin = 5 * time.Minute
+)
+
+// googleServiceAccount is the part of a service account JSON key used to mint access tokens.
+type googleServiceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// parseGoogleServiceAccount parses a service account JSON key.
+func parseGoogleServiceAccount(key string) (*googleServiceAccount, error) {
+	var account googleServiceAccount
+	if err := json.Unmarshal([]byte(key), &account); err != nil {
+		return nil, errors.New("key is not a service account JSON key")
+	}
+	if account.Type != "service_account" {
+		return nil, fmt.Errorf("unsupported credential type %q, expected service_account", account.Type)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("service account key is missing client_email or private_key")
+	}
+	return &account, nil
+}
+
+// rsaKey decodes the PEM private key of the service account.
+func (a *googleServiceAccount) rsaKey() (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(a.PrivateKey))
+	if block == nil {
+		return nil, errors.New("service account private_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("service account private_key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account private_key: %w", err)
+	}
+	return key, nil
+}
+
+// googleTokenCache mints access tokens per service account and token endpoint and reuses them until shortly
+// before they expire. It is shared by all channels of a factory, so tokens survive channel rebuilds.
+type googleTokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*googleTokenEntry
+	now     func() time.Time
+}
+
+type googleTokenEntry struct {
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newGoogleTokenCache() *googleTokenCache {
+	return &googleTokenCache{
+		entries: make(map[string]*googleTokenEntry),
+		now:     time.Now,
+	}
+}
+
+// Token returns a valid access token for the service account. Concurrent callers for the same account
+// wait for a single token request.
+func (c *googleTokenCache) Token(ctx context.Context, client *http.Client, account *googleServiceAccount, tokenURL string) (string, error) {
+	keyHash := sha256.Sum256([]byte(account.PrivateKey))
+	cacheKey := account.ClientEmail + "|" + hex.EncodeToString(keyHash[:8]) + "|" + tokenURL
+
+	c.mu.Lock()
+	entry, ok := c.entries[cacheKey]
+	if !ok {
+		entry = &googleTokenEntry{}
+		c.entries[cacheKey] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	now := c.now()
+	if entry.token != "" && now.Add(googleTokenRefreshMargin).Before(entry.expiry) {
+		return entry.token, nil
+	}
+
+	token, expiresIn, err := mintGoogleAccessToken(ctx, client, account, tokenURL, now)
+	if err != nil {
+		return "", err
+	}
+	entry.token, entry.expiry = token, now.Add(expiresIn)
+	return token, nil
+}
+
+// mintGoogleAccessToken exchanges a JWT signed with the service account key for an access token.
+func mintGoogleAccessToken(ctx context.Context, client *http.Client, account *googleServiceAccount, tokenURL string, now time.Time) (string, time.Duration, error) {
+	assertion, err := signGoogleJWT(account, tokenURL, now)
+	if err != nil {
+		return "", 0, err
+	}
+
+	form := url.Values{
+		"grant_type": {googleJWTBearerGrantType},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var tokenResp struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &tokenResp)
+	if resp.StatusCode != http.StatusOK || tokenResp.AccessToken == "" {
+		reason := tokenResp.ErrorDescription
+		if reason == "" {
+			reason = tokenResp.Error
+		}
+		if reason == "" {
+			reason = strings.TrimSpace(string(body))
+		}
+		return "", 0, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, reason)
+	}
+
+	expiresIn := time.Duration(tokenResp.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = googleAssertionLifetime
+	}
+	return tokenResp.AccessToken, expiresIn, nil
+}
+
+// signGoogleJWT builds the RS256 signed assertion of the JWT bearer flow.
+func signGoogleJWT(account *googleServiceAccount, audience string, now time.Time) (string, error) {
+	key, err := account.rsaKey()
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": account.PrivateKeyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   account.ClientEmail,
+		"scope": googleCloudPlatformScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(googleAssertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token assertion: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,130 @@
+package channel
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServiceAccountKey returns a service account JSON key with a fresh RSA key.
+func newTestServiceAccountKey(t *testing.T, projectID string) (string, *rsa.PublicKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	account, err := json.Marshal(googleServiceAccount{
+		Type:         "service_account",
+		ProjectID:    projectID,
+		PrivateKeyID: "kid-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "proxy@" + projectID + ".iam.gserviceaccount.com",
+	})
+	require.NoError(t, err)
+	return string(account), &key.PublicKey
+}
+
+// newTokenStub returns a token endpoint that verifies JWT bearer assertions and issues numbered tokens.
+func newTokenStub(t *testing.T, publicKey *rsa.PublicKey, minted *atomic.Int32) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		parts := strings.Split(r.PostForm.Get("assertion"), ".")
+		if r.PostForm.Get("grant_type") != googleJWTBearerGrantType || len(parts) != 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Invalid grant"}`))
+			return
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Invalid JWT Signature."}`))
+			return
+		}
+
+		var claims struct {
+			Aud   string `json:"aud"`
+			Scope string `json:"scope"`
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		assert.Equal(t, server.URL+"/token", claims.Aud)
+		assert.Equal(t, googleCloudPlatformScope, claims.Scope)
+
+		n := minted.Add(1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"tok-%d","expires_in":3600,"token_type":"Bearer"}`, n)
+	}))
+	return server
+}
+
+func TestGoogleTokenCache(t *testing.T) {
+	key, publicKey := newTestServiceAccountKey(t, "proj")
+	var minted atomic.Int32
+	server := newTokenStub(t, publicKey, &minted)
+	defer server.Close()
+
+	account, err := parseGoogleServiceAccount(key)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newGoogleTokenCache()
+	cache.now = func() time.Time { return now }
+
+	token, err := cache.Token(context.Background(), http.DefaultClient, account, server.URL+"/token")
+	require.NoError(t, err)
+	assert.Equal(t, "tok-1", token)
+
+	now = now.Add(50 * time.Minute)
+	token, err = cache.Token(context.Background(), http.DefaultClient, account, server.URL+"/token")
+	require.NoError(t, err)
+	assert.Equal(t, "tok-1", token)
+
+	// 距过期不足刷新余量时重新签发
+	now = now.Add(6 * time.Minute)
+	token, err = cache.Token(context.Background(), http.DefaultClient, account, server.URL+"/token")
+	require.NoError(t, err)
+	assert.Equal(t, "tok-2", token)
+	assert.Equal(t, int32(2), minted.Load())
+}
+
+func TestGoogleTokenCache_InvalidSignature(t *testing.T) {
+	key, _ := newTestServiceAccountKey(t, "proj")
+	_, otherPublicKey := newTestServiceAccountKey(t, "proj")
+	var minted atomic.Int32
+	server := newTokenStub(t, otherPublicKey, &minted)
+	defer server.Close()
+
+	account, err := parseGoogleServiceAccount(key)
+	require.NoError(t, err)
+
+	_, err = newGoogleTokenCache().Token(context.Background(), http.DefaultClient, account, server.URL+"/token")
+	assert.ErrorContains(t, err, "Invalid JWT Signature.")
+}
+
+func TestParseGoogleServiceAccount(t *testing.T) {
+	_, err := parseGoogleServiceAccount("sk-123")
+	assert.Error(t, err)
+	_, err = parseGoogleServiceAccount(`{"type":"authorized_user","client_email":"a","private_key":"b"}`)
+	assert.ErrorContains(t, err, "authorized_user")
+	_, err = parseGoogleServiceAccount(`{"type":"service_account","client_email":"a"}`)
+	assert.Error(t, err)
+}
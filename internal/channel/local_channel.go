@@ -0,0 +1,128 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("local", newLocalChannel)
+	RegisterConfigValidator("local", validateOpenAIConfig)
+}
+
+// LocalChannel fronts self-hosted OpenAI-compatible servers such as Ollama, vLLM and llama.cpp, which need
+// no API key. Its groups have no keys: requests are sent without credentials unless header rules add them,
+// and health is checked by listing the models of every upstream.
+type LocalChannel struct {
+	*OpenAIChannel
+}
+
+func newLocalChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	var config openAIConfig
+	if err := decodeChannelConfig(group.ChannelConfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid channel config for local channel: %w", err)
+	}
+
+	base, err := f.newBaseChannel("local", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalChannel{
+		OpenAIChannel: &OpenAIChannel{BaseChannel: base, anthropicMessages: config.AnthropicMessages},
+	}, nil
+}
+
+// ModifyRequest leaves the request unchanged.
+func (ch *LocalChannel) ModifyRequest(req *http.Request, apiKey *models.APIKey, group *models.Group) {
+	// Local servers need no credentials; header rules can still add them.
+}
+
+// IsQuotaExhausted always returns false, as local servers have no quota.
+func (ch *LocalChannel) IsQuotaExhausted(statusCode int, errorBody []byte) bool {
+	return false
+}
+
+// CheckUpstreams lists the models of every upstream and records the outcome in the upstream health statistics.
+func (ch *LocalChannel) CheckUpstreams(ctx context.Context, group *models.Group) error {
+	ch.upstreamLock.Lock()
+	upstreams := make([]*url.URL, 0, len(ch.Upstreams))
+	for _, upstream := range ch.Upstreams {
+		upstreams = append(upstreams, upstream.URL)
+	}
+	ch.upstreamLock.Unlock()
+
+	var failures []string
+	for _, upstream := range upstreams {
+		if err := ch.probeUpstream(ctx, upstream, group); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", upstream, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// ValidateKey checks that an upstream answers the models endpoint. Keys are not needed by local groups.
+func (ch *LocalChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
+	upstreamURL := ch.getUpstreamURL()
+	if upstreamURL == nil {
+		return false, fmt.Errorf("no upstream URL configured for channel %s", ch.Name)
+	}
+	if err := ch.probeUpstream(ctx, upstreamURL, group); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// probeUpstream requests the models endpoint, or the group's validation endpoint, of one upstream.
+func (ch *LocalChannel) probeUpstream(ctx context.Context, upstreamURL *url.URL, group *models.Group) error {
+	validationEndpoint := ch.ValidationEndpoint
+	if validationEndpoint == "" {
+		validationEndpoint = "/v1/models"
+	}
+	reqURL, err := url.JoinPath(upstreamURL.String(), validationEndpoint)
+	if err != nil {
+		return fmt.Errorf("failed to join upstream URL and validation endpoint: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create validation request: %w", err)
+	}
+
+	// Apply custom header rules if available
+	if len(group.HeaderRuleList) > 0 {
+		headerCtx := utils.NewHeaderVariableContext(group, nil)
+		utils.ApplyHeaderRules(req, group.HeaderRuleList, headerCtx)
+	}
+
+	start := time.Now()
+	resp, err := ch.HTTPClient.Do(req)
+	if err != nil {
+		ch.RecordUpstreamResult(reqURL, 0, err, 0)
+		return fmt.Errorf("failed to send validation request: %w", err)
+	}
+	defer resp.Body.Close()
+	ch.RecordUpstreamResult(reqURL, resp.StatusCode, nil, time.Since(start))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	errorBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("upstream check failed (status %d), but failed to read error body: %w", resp.StatusCode, err)
+	}
+	return fmt.Errorf("[status %d] %s", resp.StatusCode, app_errors.ParseUpstreamError(errorBody))
+}
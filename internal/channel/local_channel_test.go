@@ -0,0 +1,76 @@
+package channel
+
+import (
+	"context"
+	"gpt-load/internal/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalChannel(t *testing.T, upstreams ...string) *LocalChannel {
+	base := newHealthTestChannel(t, 1, upstreams...)
+	base.HTTPClient = http.DefaultClient
+	return &LocalChannel{OpenAIChannel: &OpenAIChannel{BaseChannel: base}}
+}
+
+func TestLocalChannel_CheckUpstreams(t *testing.T) {
+	var gotPath, gotHeader string
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotHeader = r.URL.Path, r.Header.Get("X-Group")
+		_, _ = io.WriteString(w, `{"object":"list","data":[]}`)
+	}))
+	defer healthy.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, `{"error":"model loading"}`)
+	}))
+	defer broken.Close()
+
+	group := &models.Group{
+		Name:           "local",
+		HeaderRuleList: []models.HeaderRule{{Key: "X-Group", Value: "${GROUP_NAME}", Action: "set"}},
+	}
+
+	ch := newTestLocalChannel(t, healthy.URL)
+	require.NoError(t, ch.CheckUpstreams(context.Background(), group))
+	assert.Equal(t, "/v1/models", gotPath)
+	assert.Equal(t, "local", gotHeader)
+
+	ch = newTestLocalChannel(t, healthy.URL, broken.URL)
+	err := ch.CheckUpstreams(context.Background(), group)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), broken.URL)
+	assert.NotContains(t, err.Error(), healthy.URL+":")
+
+	health := ch.UpstreamHealth()
+	assert.Equal(t, UpstreamStateClosed, health[0].State)
+	assert.Equal(t, UpstreamStateOpen, health[1].State)
+}
+
+func TestLocalChannel_ValidateKey(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	ch := newTestLocalChannel(t, server.URL)
+	ch.ValidationEndpoint = "/api/tags"
+
+	valid, err := ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: "ignored"}, &models.Group{})
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, "/api/tags", gotPath)
+	assert.Empty(t, gotAuth)
+
+	req := httptest.NewRequest(http.MethodPost, server.URL+"/v1/chat/completions", nil)
+	ch.ModifyRequest(req, &models.APIKey{KeyValue: "ignored"}, &models.Group{})
+	assert.Empty(t, req.Header.Get("Authorization"))
+	assert.False(t, ch.IsQuotaExhausted(http.StatusTooManyRequests, []byte(`{"error":"quota"}`)))
+}
@@ -18,20 +18,40 @@ import (
 
 func init() {
 	Register("openai", newOpenAIChannel)
+	RegisterConfigValidator("openai", validateOpenAIConfig)
+}
+
+// openAIConfig is the channel_config of an openai or local group.
+type openAIConfig struct {
+	// AnthropicMessages makes the group accept Anthropic Messages API requests (/v1/messages) and translate
+	// them into chat completions. It is off by default, so upstreams serving /v1/messages natively receive
+	// those requests unchanged.
+	AnthropicMessages bool `json:"anthropic_messages"`
+}
+
+func validateOpenAIConfig(config map[string]any) error {
+	return decodeChannelConfig(config, &openAIConfig{})
 }
 
 type OpenAIChannel struct {
 	*BaseChannel
+	anthropicMessages bool // 将 Anthropic Messages 请求转换为 Chat Completions 请求
 }
 
 func newOpenAIChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	var config openAIConfig
+	if err := decodeChannelConfig(group.ChannelConfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid channel config for openai channel: %w", err)
+	}
+
 	base, err := f.newBaseChannel("openai", group)
 	if err != nil {
 		return nil, err
 	}
 
 	return &OpenAIChannel{
-		BaseChannel: base,
+		BaseChannel:       base,
+		anthropicMessages: config.AnthropicMessages,
 	}, nil
 }
 
@@ -72,6 +92,11 @@ func (ch *OpenAIChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
 	return ""
 }
 
+// IsQuotaExhausted detects OpenAI's insufficient_quota error, returned when the account has run out of credits.
+func (ch *OpenAIChannel) IsQuotaExhausted(statusCode int, errorBody []byte) bool {
+	return statusCode == http.StatusTooManyRequests && bodyContainsAny(errorBody, "insufficient_quota", "exceeded your current quota")
+}
+
 // ValidateKey checks if the given API key is valid by making a chat completion request.
 func (ch *OpenAIChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
 	upstreamURL := ch.getUpstreamURL()
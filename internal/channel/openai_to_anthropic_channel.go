@@ -0,0 +1,377 @@
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"gpt-load/internal/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	Register("openai-to-anthropic", newOpenAIToAnthropicChannel)
+}
+
+// OpenAIToAnthropicChannel serves OpenAI Chat Completions clients from an Anthropic Messages API key pool.
+// Requests other than chat completions are forwarded to the Anthropic upstream unchanged.
+type OpenAIToAnthropicChannel struct {
+	*AnthropicChannel
+}
+
+func newOpenAIToAnthropicChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	base, err := f.newBaseChannel("openai-to-anthropic", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAIToAnthropicChannel{
+		AnthropicChannel: &AnthropicChannel{BaseChannel: base},
+	}, nil
+}
+
+// TranslatesRequest reports whether the request is an OpenAI chat completion.
+func (ch *OpenAIToAnthropicChannel) TranslatesRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodPost && strings.HasSuffix(c.Request.URL.Path, "/chat/completions")
+}
+
+// TranslateRequest converts an OpenAI chat completion request into an Anthropic messages request.
+func (ch *OpenAIToAnthropicChannel) TranslateRequest(c *gin.Context, bodyBytes []byte) (*TranslatedRequest, error) {
+	body, err := openAIToAnthropicRequest(bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamURL := *c.Request.URL
+	upstreamURL.Path = strings.TrimSuffix(upstreamURL.Path, "/chat/completions") + "/messages"
+	return &TranslatedRequest{Body: body, URL: &upstreamURL}, nil
+}
+
+// TranslateResponse converts an Anthropic message into an OpenAI chat completion.
+func (ch *OpenAIToAnthropicChannel) TranslateResponse(c *gin.Context, body []byte) ([]byte, error) {
+	return anthropicToOpenAIResponse(body, time.Now())
+}
+
+// TranslateError converts an Anthropic error body into an OpenAI error body.
+func (ch *OpenAIToAnthropicChannel) TranslateError(statusCode int, body []byte) []byte {
+	var anthropicErr anthropicErrorResponse
+	if err := json.Unmarshal(body, &anthropicErr); err != nil || anthropicErr.Error.Message == "" {
+		return body
+	}
+	return newOpenAIError(anthropicErr.Error.Message, anthropicErr.Error.Type)
+}
+
+// NewStreamTranslator returns a translator from Anthropic stream events to OpenAI chunks.
+func (ch *OpenAIToAnthropicChannel) NewStreamTranslator(c *gin.Context) StreamTranslator {
+	return newAnthropicToOpenAIStream(time.Now())
+}
+
+// openAIToAnthropicRequest converts an OpenAI chat completion request body into an Anthropic messages request body.
+func openAIToAnthropicRequest(bodyBytes []byte) ([]byte, error) {
+	var req openAIChatRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		return nil, fmt.Errorf("invalid chat completion request: %w", err)
+	}
+
+	out := anthropicRequest{
+		Model:         req.Model,
+		MaxTokens:     defaultAnthropicMaxTokens,
+		Temperature:   req.Temperature,
+		TopP:          req.TopP,
+		StopSequences: parseOpenAIStop(req.Stop),
+		Stream:        req.Stream,
+	}
+	if req.MaxCompletionTokens != nil {
+		out.MaxTokens = *req.MaxCompletionTokens
+	} else if req.MaxTokens != nil {
+		out.MaxTokens = *req.MaxTokens
+	}
+	if req.User != "" {
+		out.Metadata = &anthropicMetadata{UserID: req.User}
+	}
+
+	var systemPrompts []string
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case "system", "developer":
+			if text := openAIContentText(msg.Content); text != "" {
+				systemPrompts = append(systemPrompts, text)
+			}
+		case "user":
+			out.Messages = appendAnthropicMessage(out.Messages, "user", openAIPartsToAnthropic(parseOpenAIContent(msg.Content)))
+		case "assistant":
+			var blocks anthropicContent
+			if text := openAIContentText(msg.Content); text != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: text})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicContentBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+			}
+			out.Messages = appendAnthropicMessage(out.Messages, "assistant", blocks)
+		case "tool":
+			content, _ := json.Marshal(openAIContentText(msg.Content))
+			block := anthropicContentBlock{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: content}
+			out.Messages = appendAnthropicMessage(out.Messages, "user", anthropicContent{block})
+		}
+	}
+	if len(systemPrompts) > 0 {
+		out.System, _ = json.Marshal(strings.Join(systemPrompts, "\n\n"))
+	}
+
+	for _, tool := range req.Tools {
+		if tool.Type != "function" {
+			continue
+		}
+		schema := tool.Function.Parameters
+		if len(schema) == 0 || string(schema) == "null" {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		out.Tools = append(out.Tools, anthropicTool{Name: tool.Function.Name, Description: tool.Function.Description, InputSchema: schema})
+	}
+	out.ToolChoice = openAIToolChoiceToAnthropic(req.ToolChoice)
+	if req.ParallelToolCalls != nil && !*req.ParallelToolCalls && len(out.Tools) > 0 {
+		if out.ToolChoice == nil {
+			out.ToolChoice = &anthropicToolChoice{Type: "auto"}
+		}
+		out.ToolChoice.DisableParallelToolUse = true
+	}
+
+	return json.Marshal(out)
+}
+
+// appendAnthropicMessage appends content to the conversation, merging consecutive messages of the same role.
+func appendAnthropicMessage(messages []anthropicMessage, role string, blocks anthropicContent) []anthropicMessage {
+	if len(blocks) == 0 {
+		return messages
+	}
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = append(messages[n-1].Content, blocks...)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
+
+// openAIPartsToAnthropic converts user content parts into Anthropic text and image blocks.
+func openAIPartsToAnthropic(parts []openAIContentPart) anthropicContent {
+	var blocks anthropicContent
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			if part.Text != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: part.Text})
+			}
+		case "image_url":
+			if part.ImageURL == nil || part.ImageURL.URL == "" {
+				continue
+			}
+			source := &anthropicImageSource{Type: "url", URL: part.ImageURL.URL}
+			if mediaType, data, ok := parseDataURL(part.ImageURL.URL); ok {
+				source = &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: data}
+			}
+			blocks = append(blocks, anthropicContentBlock{Type: "image", Source: source})
+		}
+	}
+	return blocks
+}
+
+// openAIToolChoiceToAnthropic converts the OpenAI tool_choice parameter.
+func openAIToolChoiceToAnthropic(raw json.RawMessage) *anthropicToolChoice {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		switch mode {
+		case "none":
+			return &anthropicToolChoice{Type: "none"}
+		case "required":
+			return &anthropicToolChoice{Type: "any"}
+		default:
+			return &anthropicToolChoice{Type: "auto"}
+		}
+	}
+
+	var choice openAITool
+	if err := json.Unmarshal(raw, &choice); err == nil && choice.Function.Name != "" {
+		return &anthropicToolChoice{Type: "tool", Name: choice.Function.Name}
+	}
+	return nil
+}
+
+// anthropicStopReasonToOpenAI maps an Anthropic stop reason to an OpenAI finish reason.
+func anthropicStopReasonToOpenAI(stopReason string) string {
+	switch stopReason {
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "refusal":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+// anthropicToOpenAIResponse converts an Anthropic message body into an OpenAI chat completion body.
+func anthropicToOpenAIResponse(body []byte, now time.Time) ([]byte, error) {
+	var resp anthropicResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid anthropic response: %w", err)
+	}
+
+	message := openAIMessage{Role: "assistant"}
+	var texts []string
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			message.ToolCalls = append(message.ToolCalls, openAIToolCall{
+				ID:       block.ID,
+				Type:     "function",
+				Function: openAIFunctionCall{Name: block.Name, Arguments: arguments},
+			})
+		}
+	}
+	message.Content = json.RawMessage("null")
+	if len(texts) > 0 || len(message.ToolCalls) == 0 {
+		message.Content, _ = json.Marshal(strings.Join(texts, ""))
+	}
+
+	promptTokens := resp.Usage.promptTokens()
+	return json.Marshal(openAIChatResponse{
+		ID:      resp.ID,
+		Object:  "chat.completion",
+		Created: now.Unix(),
+		Model:   resp.Model,
+		Choices: []openAIChoice{{
+			Index:        0,
+			Message:      message,
+			FinishReason: anthropicStopReasonToOpenAI(resp.StopReason),
+		}},
+		Usage: &openAIUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      promptTokens + resp.Usage.OutputTokens,
+		},
+	})
+}
+
+// anthropicToOpenAIStream translates one Anthropic event stream into OpenAI chat completion chunks.
+type anthropicToOpenAIStream struct {
+	id           string
+	model        string
+	created      int64
+	promptTokens int
+	toolIndexes  map[int]int // Anthropic 内容块索引 -> OpenAI tool_calls 索引
+	done         bool
+}
+
+func newAnthropicToOpenAIStream(now time.Time) *anthropicToOpenAIStream {
+	return &anthropicToOpenAIStream{created: now.Unix(), toolIndexes: make(map[int]int)}
+}
+
+// TranslateEvent converts one Anthropic stream event into OpenAI chunks.
+func (s *anthropicToOpenAIStream) TranslateEvent(event SSEEvent) ([]SSEEvent, error) {
+	var ev anthropicStreamEvent
+	if err := json.Unmarshal(event.Data, &ev); err != nil {
+		return nil, fmt.Errorf("invalid anthropic stream event: %w", err)
+	}
+
+	switch ev.Type {
+	case "message_start":
+		if ev.Message != nil {
+			s.id = ev.Message.ID
+			s.model = ev.Message.Model
+			s.promptTokens = ev.Message.Usage.promptTokens()
+		}
+		empty := ""
+		return s.chunk(openAIDelta{Role: "assistant", Content: &empty}, nil, nil)
+	case "content_block_start":
+		if ev.ContentBlock == nil {
+			return nil, nil
+		}
+		switch ev.ContentBlock.Type {
+		case "tool_use":
+			index := len(s.toolIndexes)
+			s.toolIndexes[ev.Index] = index
+			call := openAIToolCall{Index: &index, ID: ev.ContentBlock.ID, Type: "function", Function: openAIFunctionCall{Name: ev.ContentBlock.Name}}
+			return s.chunk(openAIDelta{ToolCalls: []openAIToolCall{call}}, nil, nil)
+		case "text":
+			if ev.ContentBlock.Text != "" {
+				return s.chunk(openAIDelta{Content: &ev.ContentBlock.Text}, nil, nil)
+			}
+		}
+	case "content_block_delta":
+		if ev.Delta == nil {
+			return nil, nil
+		}
+		switch ev.Delta.Type {
+		case "text_delta":
+			return s.chunk(openAIDelta{Content: &ev.Delta.Text}, nil, nil)
+		case "input_json_delta":
+			index, ok := s.toolIndexes[ev.Index]
+			if !ok {
+				return nil, nil
+			}
+			call := openAIToolCall{Index: &index, Function: openAIFunctionCall{Arguments: ev.Delta.PartialJSON}}
+			return s.chunk(openAIDelta{ToolCalls: []openAIToolCall{call}}, nil, nil)
+		}
+	case "message_delta":
+		if ev.Delta == nil {
+			return nil, nil
+		}
+		finishReason := anthropicStopReasonToOpenAI(ev.Delta.StopReason)
+		var usage *openAIUsage
+		if ev.Usage != nil {
+			usage = &openAIUsage{
+				PromptTokens:     s.promptTokens,
+				CompletionTokens: ev.Usage.OutputTokens,
+				TotalTokens:      s.promptTokens + ev.Usage.OutputTokens,
+			}
+		}
+		return s.chunk(openAIDelta{}, &finishReason, usage)
+	case "message_stop":
+		return s.Finish(), nil
+	case "error":
+		if ev.Error != nil {
+			return []SSEEvent{{Data: newOpenAIError(ev.Error.Message, ev.Error.Type)}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Finish terminates the OpenAI stream with the [DONE] marker.
+func (s *anthropicToOpenAIStream) Finish() []SSEEvent {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	return []SSEEvent{{Data: []byte("[DONE]")}}
+}
+
+func (s *anthropicToOpenAIStream) chunk(delta openAIDelta, finishReason *string, usage *openAIUsage) ([]SSEEvent, error) {
+	data, err := json.Marshal(openAIChatChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: s.created,
+		Model:   s.model,
+		Choices: []openAIChunkChoice{{Index: 0, Delta: delta, FinishReason: finishReason}},
+		Usage:   usage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []SSEEvent{{Data: data}}, nil
+}
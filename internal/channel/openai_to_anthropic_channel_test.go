@@ -0,0 +1,187 @@
+package channel
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIToAnthropicRequest(t *testing.T) {
+	body := `{
+		"model": "claude-sonnet-4",
+		"max_tokens": 512,
+		"stop": "END",
+		"stream": true,
+		"user": "u-1",
+		"parallel_tool_calls": false,
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "developer", "content": [{"type": "text", "text": "Answer in English."}]},
+			{"role": "user", "content": [
+				{"type": "text", "text": "What is in this image?"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo="}},
+				{"type": "image_url", "image_url": {"url": "https://example.com/cat.jpg"}}
+			]},
+			{"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{\"q\":\"cat\"}"}},
+				{"id": "call_2", "type": "function", "function": {"name": "lookup", "arguments": ""}}
+			]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "a cat"},
+			{"role": "tool", "tool_call_id": "call_2", "content": "a dog"}
+		],
+		"tools": [{"type": "function", "function": {"name": "lookup", "description": "Look up", "parameters": {"type": "object"}}}],
+		"tool_choice": "required"
+	}`
+
+	out, err := openAIToAnthropicRequest([]byte(body))
+	require.NoError(t, err)
+
+	var req anthropicRequest
+	require.NoError(t, json.Unmarshal(out, &req))
+
+	assert.Equal(t, "claude-sonnet-4", req.Model)
+	assert.Equal(t, 512, req.MaxTokens)
+	assert.True(t, req.Stream)
+	assert.Equal(t, []string{"END"}, req.StopSequences)
+	assert.JSONEq(t, `"Be brief.\n\nAnswer in English."`, string(req.System))
+	require.NotNil(t, req.Metadata)
+	assert.Equal(t, "u-1", req.Metadata.UserID)
+
+	require.Len(t, req.Messages, 3)
+	user := req.Messages[0]
+	assert.Equal(t, "user", user.Role)
+	require.Len(t, user.Content, 3)
+	assert.Equal(t, "base64", user.Content[1].Source.Type)
+	assert.Equal(t, "image/png", user.Content[1].Source.MediaType)
+	assert.Equal(t, "iVBORw0KGgo=", user.Content[1].Source.Data)
+	assert.Equal(t, "url", user.Content[2].Source.Type)
+
+	assistant := req.Messages[1]
+	require.Len(t, assistant.Content, 2)
+	assert.Equal(t, "tool_use", assistant.Content[0].Type)
+	assert.JSONEq(t, `{"q":"cat"}`, string(assistant.Content[0].Input))
+	assert.JSONEq(t, `{}`, string(assistant.Content[1].Input))
+
+	// 连续的工具结果合并到同一条 user 消息
+	results := req.Messages[2]
+	assert.Equal(t, "user", results.Role)
+	require.Len(t, results.Content, 2)
+	assert.Equal(t, "call_2", results.Content[1].ToolUseID)
+	assert.JSONEq(t, `"a dog"`, string(results.Content[1].Content))
+
+	require.Len(t, req.Tools, 1)
+	assert.JSONEq(t, `{"type":"object"}`, string(req.Tools[0].InputSchema))
+	require.NotNil(t, req.ToolChoice)
+	assert.Equal(t, "any", req.ToolChoice.Type)
+	assert.True(t, req.ToolChoice.DisableParallelToolUse)
+}
+
+func TestOpenAIToAnthropicRequest_DefaultMaxTokens(t *testing.T) {
+	out, err := openAIToAnthropicRequest([]byte(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+
+	var req anthropicRequest
+	require.NoError(t, json.Unmarshal(out, &req))
+	assert.Equal(t, defaultAnthropicMaxTokens, req.MaxTokens)
+	assert.Empty(t, req.System)
+}
+
+func TestAnthropicToOpenAIResponse(t *testing.T) {
+	body := `{
+		"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-sonnet-4",
+		"content": [
+			{"type": "text", "text": "Let me check."},
+			{"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": {"q": "cat"}}
+		],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 10, "cache_read_input_tokens": 5, "output_tokens": 7}
+	}`
+
+	out, err := anthropicToOpenAIResponse([]byte(body), time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	var resp openAIChatResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "chat.completion", resp.Object)
+	assert.Equal(t, int64(1700000000), resp.Created)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	assert.JSONEq(t, `"Let me check."`, string(resp.Choices[0].Message.Content))
+	require.Len(t, resp.Choices[0].Message.ToolCalls, 1)
+	assert.Equal(t, "toolu_1", resp.Choices[0].Message.ToolCalls[0].ID)
+	assert.JSONEq(t, `{"q":"cat"}`, resp.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, openAIUsage{PromptTokens: 15, CompletionTokens: 7, TotalTokens: 22}, *resp.Usage)
+}
+
+func TestAnthropicToOpenAIStream(t *testing.T) {
+	upstream := strings.Join([]string{
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"msg_1","model":"claude-sonnet-4","usage":{"input_tokens":3}}}`,
+		``,
+		`event: ping`,
+		`data: {"type":"ping"}`,
+		``,
+		`event: content_block_start`,
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}`,
+		``,
+		`event: content_block_start`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"lookup"}}`,
+		``,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"q\":"}}`,
+		``,
+		`event: message_delta`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":4}}`,
+		``,
+		`event: message_stop`,
+		`data: {"type":"message_stop"}`,
+		``,
+	}, "\n")
+
+	stream := newAnthropicToOpenAIStream(time.Unix(1700000000, 0))
+	var out []SSEEvent
+	err := ReadSSE(strings.NewReader(upstream), func(event SSEEvent) error {
+		events, err := stream.TranslateEvent(event)
+		out = append(out, events...)
+		return err
+	})
+	require.NoError(t, err)
+	out = append(out, stream.Finish()...)
+
+	require.Len(t, out, 6)
+	var chunks []openAIChatChunk
+	for _, event := range out[:5] {
+		var chunk openAIChatChunk
+		require.NoError(t, json.Unmarshal(event.Data, &chunk))
+		assert.Equal(t, "msg_1", chunk.ID)
+		assert.Equal(t, "chat.completion.chunk", chunk.Object)
+		chunks = append(chunks, chunk)
+	}
+
+	assert.Equal(t, "assistant", chunks[0].Choices[0].Delta.Role)
+	assert.Equal(t, "Hi", *chunks[1].Choices[0].Delta.Content)
+	call := chunks[2].Choices[0].Delta.ToolCalls[0]
+	assert.Equal(t, 0, *call.Index)
+	assert.Equal(t, "toolu_1", call.ID)
+	assert.Equal(t, "lookup", call.Function.Name)
+	assert.Equal(t, `{"q":`, chunks[3].Choices[0].Delta.ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "tool_calls", *chunks[4].Choices[0].FinishReason)
+	assert.Equal(t, 7, chunks[4].Usage.TotalTokens)
+	assert.Equal(t, "[DONE]", string(out[5].Data))
+}
+
+func TestOpenAIToAnthropicChannel_TranslateError(t *testing.T) {
+	ch := &OpenAIToAnthropicChannel{}
+
+	out := ch.TranslateError(429, []byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	assert.JSONEq(t, `{"error":{"message":"slow down","type":"rate_limit_error","code":null}}`, string(out))
+
+	assert.Equal(t, "not json", string(ch.TranslateError(500, []byte("not json"))))
+}
@@ -0,0 +1,692 @@
+package channel
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gpt-load/internal/models"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func init() {
+	Register("openai-to-gemini", newOpenAIToGeminiChannel)
+}
+
+// geminiTranslationKey stores the *geminiTranslation of the current request in the gin context.
+const geminiTranslationKey = "geminiTranslation"
+
+// geminiModelPattern restricts the model taken from the client body, which becomes a segment of the upstream path.
+var geminiModelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// OpenAIToGeminiChannel serves OpenAI Chat Completions and Embeddings clients from a Gemini key pool
+// through the native generateContent, streamGenerateContent and embedContent methods.
+// Other requests are forwarded to the Gemini upstream unchanged.
+type OpenAIToGeminiChannel struct {
+	*GeminiChannel
+}
+
+// geminiTranslation records how a request was translated, so that its response can be converted back.
+type geminiTranslation struct {
+	model     string
+	stream    bool
+	embedding bool
+	batch     bool
+	base64    bool
+}
+
+// method returns the Gemini model method the translated request is sent to.
+func (t *geminiTranslation) method() string {
+	switch {
+	case t.embedding && t.batch:
+		return "batchEmbedContents"
+	case t.embedding:
+		return "embedContent"
+	case t.stream:
+		return "streamGenerateContent"
+	default:
+		return "generateContent"
+	}
+}
+
+func newOpenAIToGeminiChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	base, err := f.newBaseChannel("openai-to-gemini", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAIToGeminiChannel{
+		GeminiChannel: &GeminiChannel{BaseChannel: base},
+	}, nil
+}
+
+// TranslatesRequest reports whether the request is an OpenAI chat completion or embeddings request.
+func (ch *OpenAIToGeminiChannel) TranslatesRequest(c *gin.Context) bool {
+	if c.Request.Method != http.MethodPost {
+		return false
+	}
+	p := c.Request.URL.Path
+	return strings.HasSuffix(p, "/chat/completions") || strings.HasSuffix(p, "/embeddings")
+}
+
+// TranslateRequest converts the OpenAI request into a native Gemini model method call.
+func (ch *OpenAIToGeminiChannel) TranslateRequest(c *gin.Context, bodyBytes []byte) (*TranslatedRequest, error) {
+	var (
+		body        []byte
+		translation *geminiTranslation
+		err         error
+	)
+	prefix, isEmbedding := strings.CutSuffix(c.Request.URL.Path, "/embeddings")
+	if isEmbedding {
+		body, translation, err = openAIToGeminiEmbedRequest(bodyBytes)
+	} else {
+		prefix = strings.TrimSuffix(c.Request.URL.Path, "/chat/completions")
+		body, translation, err = openAIToGeminiRequest(bodyBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !geminiModelPattern.MatchString(translation.model) {
+		return nil, fmt.Errorf("invalid model name %q", translation.model)
+	}
+	c.Set(geminiTranslationKey, translation)
+
+	// /proxy/<group>/v1/chat/completions -> /proxy/<group>/v1beta/models/<model>:generateContent
+	upstreamURL := *c.Request.URL
+	upstreamURL.Path = strings.TrimSuffix(prefix, "/v1") + "/v1beta/models/" + translation.model + ":" + translation.method()
+	upstreamURL.RawPath = ""
+	upstreamURL.RawQuery = ""
+	if translation.stream {
+		upstreamURL.RawQuery = url.Values{"alt": {"sse"}}.Encode()
+	}
+	return &TranslatedRequest{Body: body, URL: &upstreamURL}, nil
+}
+
+// ExtractModel also resolves the model once the request body has been translated, since native
+// Gemini bodies carry the model in the upstream path only.
+func (ch *OpenAIToGeminiChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
+	if model := ch.GeminiChannel.ExtractModel(c, bodyBytes); model != "" {
+		return model
+	}
+	if translation := getGeminiTranslation(c); translation != nil {
+		return translation.model
+	}
+	return ""
+}
+
+// TranslateResponse converts a Gemini response into an OpenAI chat completion or embeddings list.
+func (ch *OpenAIToGeminiChannel) TranslateResponse(c *gin.Context, body []byte) ([]byte, error) {
+	translation := getGeminiTranslation(c)
+	if translation == nil {
+		return nil, errors.New("missing gemini translation state")
+	}
+	if translation.embedding {
+		return geminiToOpenAIEmbeddings(body, translation)
+	}
+	return geminiToOpenAIResponse(body, translation.model, time.Now())
+}
+
+// TranslateError converts a Gemini error body into an OpenAI error body.
+func (ch *OpenAIToGeminiChannel) TranslateError(statusCode int, body []byte) []byte {
+	var geminiErr geminiErrorResponse
+	if err := json.Unmarshal(body, &geminiErr); err != nil {
+		// 流式接口的错误以数组形式返回
+		var geminiErrs []geminiErrorResponse
+		if err := json.Unmarshal(body, &geminiErrs); err != nil || len(geminiErrs) == 0 {
+			return body
+		}
+		geminiErr = geminiErrs[0]
+	}
+	if geminiErr.Error.Message == "" {
+		return body
+	}
+
+	out, _ := json.Marshal(openAIErrorResponse{Error: openAIError{
+		Message: geminiErr.Error.Message,
+		Type:    geminiStatusToOpenAIErrorType(geminiErr.Error.Status),
+		Code:    strings.ToLower(geminiErr.Error.Status),
+	}})
+	return out
+}
+
+// NewStreamTranslator returns a translator from Gemini stream chunks to OpenAI chunks.
+func (ch *OpenAIToGeminiChannel) NewStreamTranslator(c *gin.Context) StreamTranslator {
+	model := ""
+	if translation := getGeminiTranslation(c); translation != nil {
+		model = translation.model
+	}
+	return newGeminiToOpenAIStream(model, time.Now())
+}
+
+func getGeminiTranslation(c *gin.Context) *geminiTranslation {
+	value, ok := c.Get(geminiTranslationKey)
+	if !ok {
+		return nil
+	}
+	translation, _ := value.(*geminiTranslation)
+	return translation
+}
+
+// openAIToGeminiRequest converts an OpenAI chat completion request body into a Gemini generateContent request body.
+func openAIToGeminiRequest(bodyBytes []byte) ([]byte, *geminiTranslation, error) {
+	var req openAIChatRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		return nil, nil, fmt.Errorf("invalid chat completion request: %w", err)
+	}
+	if req.Model == "" {
+		return nil, nil, errors.New("model is required")
+	}
+
+	config := &geminiGenerationConfig{
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		StopSequences:    parseOpenAIStop(req.Stop),
+		CandidateCount:   req.N,
+		Seed:             req.Seed,
+		PresencePenalty:  req.PresencePenalty,
+		FrequencyPenalty: req.FrequencyPenalty,
+	}
+	if req.MaxCompletionTokens != nil {
+		config.MaxOutputTokens = req.MaxCompletionTokens
+	} else if req.MaxTokens != nil {
+		config.MaxOutputTokens = req.MaxTokens
+	}
+	if req.ResponseFormat != nil {
+		switch req.ResponseFormat.Type {
+		case "json_object":
+			config.ResponseMimeType = "application/json"
+		case "json_schema":
+			config.ResponseMimeType = "application/json"
+			if req.ResponseFormat.JSONSchema != nil {
+				config.ResponseSchema = cleanGeminiSchema(req.ResponseFormat.JSONSchema.Schema)
+			}
+		}
+	}
+	out := geminiRequest{Contents: []geminiContent{}, GenerationConfig: config}
+
+	// 工具结果只携带 tool_call_id，Gemini 需要对应的函数名
+	callNames := make(map[string]string)
+	var systemParts []geminiPart
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case "system", "developer":
+			if text := openAIContentText(msg.Content); text != "" {
+				systemParts = append(systemParts, geminiPart{Text: text})
+			}
+		case "user":
+			out.Contents = appendGeminiContent(out.Contents, "user", openAIPartsToGemini(parseOpenAIContent(msg.Content)))
+		case "assistant":
+			var parts []geminiPart
+			if text := openAIContentText(msg.Content); text != "" {
+				parts = append(parts, geminiPart{Text: text})
+			}
+			for _, call := range msg.ToolCalls {
+				callNames[call.ID] = call.Function.Name
+				args := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(args) {
+					args = json.RawMessage("{}")
+				}
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{Name: call.Function.Name, Args: args}})
+			}
+			out.Contents = appendGeminiContent(out.Contents, "model", parts)
+		case "tool":
+			name := callNames[msg.ToolCallID]
+			if name == "" {
+				name = msg.Name
+			}
+			part := geminiPart{FunctionResponse: &geminiFunctionResponse{Name: name, Response: geminiFunctionResult(openAIContentText(msg.Content))}}
+			out.Contents = appendGeminiContent(out.Contents, "user", []geminiPart{part})
+		}
+	}
+	if len(systemParts) > 0 {
+		out.SystemInstruction = &geminiContent{Parts: systemParts}
+	}
+
+	var declarations []geminiFunctionDeclaration
+	for _, tool := range req.Tools {
+		if tool.Type != "function" {
+			continue
+		}
+		declarations = append(declarations, geminiFunctionDeclaration{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  cleanGeminiSchema(tool.Function.Parameters),
+		})
+	}
+	if len(declarations) > 0 {
+		out.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+		out.ToolConfig = openAIToolChoiceToGemini(req.ToolChoice)
+	}
+
+	body, err := json.Marshal(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, &geminiTranslation{model: strings.TrimPrefix(req.Model, "models/"), stream: req.Stream}, nil
+}
+
+// openAIToGeminiEmbedRequest converts an OpenAI embeddings request body into an embedContent request body,
+// or a batchEmbedContents request body when the input is an array.
+func openAIToGeminiEmbedRequest(bodyBytes []byte) ([]byte, *geminiTranslation, error) {
+	var req openAIEmbeddingRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		return nil, nil, fmt.Errorf("invalid embeddings request: %w", err)
+	}
+	if req.Model == "" {
+		return nil, nil, errors.New("model is required")
+	}
+
+	translation := &geminiTranslation{
+		model:     strings.TrimPrefix(req.Model, "models/"),
+		embedding: true,
+		base64:    req.EncodingFormat == "base64",
+	}
+
+	var input string
+	if err := json.Unmarshal(req.Input, &input); err == nil {
+		body, err := json.Marshal(geminiEmbedRequest{
+			Content:              geminiContent{Parts: []geminiPart{{Text: input}}},
+			OutputDimensionality: req.Dimensions,
+		})
+		return body, translation, err
+	}
+
+	var inputs []string
+	if err := json.Unmarshal(req.Input, &inputs); err != nil {
+		return nil, nil, errors.New("input must be a string or an array of strings")
+	}
+	translation.batch = true
+	batch := geminiBatchEmbedRequest{Requests: make([]geminiEmbedRequest, 0, len(inputs))}
+	for _, text := range inputs {
+		batch.Requests = append(batch.Requests, geminiEmbedRequest{
+			Model:                "models/" + translation.model,
+			Content:              geminiContent{Parts: []geminiPart{{Text: text}}},
+			OutputDimensionality: req.Dimensions,
+		})
+	}
+	body, err := json.Marshal(batch)
+	return body, translation, err
+}
+
+// appendGeminiContent appends parts to the conversation, merging consecutive contents of the same role.
+func appendGeminiContent(contents []geminiContent, role string, parts []geminiPart) []geminiContent {
+	if len(parts) == 0 {
+		return contents
+	}
+	if n := len(contents); n > 0 && contents[n-1].Role == role {
+		contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+		return contents
+	}
+	return append(contents, geminiContent{Role: role, Parts: parts})
+}
+
+// openAIPartsToGemini converts user content parts into Gemini text, inline data and file data parts.
+func openAIPartsToGemini(parts []openAIContentPart) []geminiPart {
+	var out []geminiPart
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			if part.Text != "" {
+				out = append(out, geminiPart{Text: part.Text})
+			}
+		case "image_url":
+			if part.ImageURL == nil || part.ImageURL.URL == "" {
+				continue
+			}
+			if mediaType, data, ok := parseDataURL(part.ImageURL.URL); ok {
+				out = append(out, geminiPart{InlineData: &geminiBlob{MimeType: mediaType, Data: data}})
+				continue
+			}
+			mimeType := "image/jpeg"
+			if u, err := url.Parse(part.ImageURL.URL); err == nil {
+				if detected := mime.TypeByExtension(path.Ext(u.Path)); detected != "" {
+					mimeType = detected
+				}
+			}
+			out = append(out, geminiPart{FileData: &geminiFileData{MimeType: mimeType, FileURI: part.ImageURL.URL}})
+		}
+	}
+	return out
+}
+
+// geminiFunctionResult wraps a tool result as the JSON object Gemini expects in a function response.
+func geminiFunctionResult(content string) json.RawMessage {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &object); err == nil && object != nil {
+		return json.RawMessage(content)
+	}
+	result, _ := json.Marshal(map[string]string{"content": content})
+	return result
+}
+
+// cleanGeminiSchema removes the JSON Schema keywords that Gemini rejects. An object schema without
+// properties is dropped entirely, since Gemini requires OBJECT parameters to declare at least one.
+func cleanGeminiSchema(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return raw
+	}
+	if properties, _ := schema["properties"].(map[string]any); schema["type"] == "object" && len(properties) == 0 {
+		return nil
+	}
+	cleaned, err := json.Marshal(stripGeminiSchemaKeywords(schema, false))
+	if err != nil {
+		return raw
+	}
+	return cleaned
+}
+
+func stripGeminiSchemaKeywords(value any, isProperties bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			// "properties" 下的键是字段名而非关键字
+			if !isProperties && (key == "$schema" || key == "additionalProperties") {
+				delete(v, key)
+				continue
+			}
+			v[key] = stripGeminiSchemaKeywords(child, !isProperties && key == "properties")
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = stripGeminiSchemaKeywords(child, false)
+		}
+	}
+	return value
+}
+
+// openAIToolChoiceToGemini converts the OpenAI tool_choice parameter into a function calling config.
+func openAIToolChoiceToGemini(raw json.RawMessage) *geminiToolConfig {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		switch mode {
+		case "none":
+			return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "NONE"}}
+		case "required":
+			return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY"}}
+		default:
+			return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "AUTO"}}
+		}
+	}
+
+	var choice openAITool
+	if err := json.Unmarshal(raw, &choice); err == nil && choice.Function.Name != "" {
+		return &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{
+			Mode:                 "ANY",
+			AllowedFunctionNames: []string{choice.Function.Name},
+		}}
+	}
+	return nil
+}
+
+// geminiFinishReasonToOpenAI maps a Gemini finish reason to an OpenAI finish reason.
+func geminiFinishReasonToOpenAI(finishReason string, hasToolCalls bool) string {
+	switch finishReason {
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "content_filter"
+	}
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	return "stop"
+}
+
+// geminiStatusToOpenAIErrorType maps a Google API error status to an OpenAI error type.
+func geminiStatusToOpenAIErrorType(status string) string {
+	switch status {
+	case "INVALID_ARGUMENT", "FAILED_PRECONDITION", "NOT_FOUND", "OUT_OF_RANGE":
+		return "invalid_request_error"
+	case "UNAUTHENTICATED", "PERMISSION_DENIED":
+		return "authentication_error"
+	case "RESOURCE_EXHAUSTED":
+		return "rate_limit_error"
+	default:
+		return "api_error"
+	}
+}
+
+// geminiBlockedPromptError builds the OpenAI error returned when Gemini refuses the prompt itself.
+func geminiBlockedPromptError(feedback *geminiPromptFeedback) []byte {
+	message := "The prompt was blocked by Gemini safety filters: " + feedback.BlockReason
+	if feedback.BlockReasonMessage != "" {
+		message += " (" + feedback.BlockReasonMessage + ")"
+	}
+	body, _ := json.Marshal(openAIErrorResponse{Error: openAIError{
+		Message: message,
+		Type:    "invalid_request_error",
+		Code:    "content_filter",
+	}})
+	return body
+}
+
+// isGeminiPromptBlocked reports whether the response carries no candidates because the prompt was blocked.
+func isGeminiPromptBlocked(resp *geminiResponse) bool {
+	return len(resp.Candidates) == 0 && resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != ""
+}
+
+// geminiToOpenAIMessage converts the parts of a candidate into the text and tool calls of an OpenAI message.
+func geminiToOpenAIMessage(parts []geminiPart) (string, []openAIToolCall) {
+	var texts []string
+	var toolCalls []openAIToolCall
+	for _, part := range parts {
+		switch {
+		case part.FunctionCall != nil:
+			arguments := string(part.FunctionCall.Args)
+			if arguments == "" || arguments == "null" {
+				arguments = "{}"
+			}
+			id := part.FunctionCall.ID
+			if id == "" {
+				id = newOpenAICallID()
+			}
+			toolCalls = append(toolCalls, openAIToolCall{
+				ID:       id,
+				Type:     "function",
+				Function: openAIFunctionCall{Name: part.FunctionCall.Name, Arguments: arguments},
+			})
+		case part.Thought:
+			// 思考内容不返回给 OpenAI 客户端
+		case part.Text != "":
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, ""), toolCalls
+}
+
+// geminiToOpenAIResponse converts a Gemini generateContent body into an OpenAI chat completion body.
+// A prompt blocked by safety filters is returned as a *TranslationError.
+func geminiToOpenAIResponse(body []byte, model string, now time.Time) ([]byte, error) {
+	var resp geminiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid gemini response: %w", err)
+	}
+	if isGeminiPromptBlocked(&resp) {
+		return nil, &TranslationError{StatusCode: http.StatusBadRequest, Body: geminiBlockedPromptError(resp.PromptFeedback)}
+	}
+
+	out := openAIChatResponse{
+		ID:      geminiCompletionID(resp.ResponseID),
+		Object:  "chat.completion",
+		Created: now.Unix(),
+		Model:   model,
+		Choices: make([]openAIChoice, 0, len(resp.Candidates)),
+		Usage:   resp.UsageMetadata.openAIUsage(),
+	}
+	if resp.ModelVersion != "" {
+		out.Model = resp.ModelVersion
+	}
+	for _, candidate := range resp.Candidates {
+		text, toolCalls := geminiToOpenAIMessage(candidate.Content.Parts)
+		message := openAIMessage{Role: "assistant", ToolCalls: toolCalls, Content: json.RawMessage("null")}
+		if text != "" || len(toolCalls) == 0 {
+			message.Content, _ = json.Marshal(text)
+		}
+		out.Choices = append(out.Choices, openAIChoice{
+			Index:        candidate.Index,
+			Message:      message,
+			FinishReason: geminiFinishReasonToOpenAI(candidate.FinishReason, len(toolCalls) > 0),
+		})
+	}
+	return json.Marshal(out)
+}
+
+// geminiToOpenAIEmbeddings converts an embedContent or batchEmbedContents body into an OpenAI embeddings list.
+func geminiToOpenAIEmbeddings(body []byte, translation *geminiTranslation) ([]byte, error) {
+	var resp geminiEmbedResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid gemini embedding response: %w", err)
+	}
+
+	embeddings := resp.Embeddings
+	if resp.Embedding != nil {
+		embeddings = []geminiEmbedding{*resp.Embedding}
+	}
+
+	out := openAIEmbeddingResponse{Object: "list", Data: make([]openAIEmbedding, 0, len(embeddings)), Model: translation.model}
+	for i, embedding := range embeddings {
+		var values any = embedding.Values
+		if translation.base64 {
+			values = encodeEmbeddingBase64(embedding.Values)
+		}
+		out.Data = append(out.Data, openAIEmbedding{Object: "embedding", Index: i, Embedding: values})
+	}
+	return json.Marshal(out)
+}
+
+// encodeEmbeddingBase64 encodes an embedding as little-endian float32 values, as OpenAI does for encoding_format=base64.
+func encodeEmbeddingBase64(values []float64) string {
+	buf := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func geminiCompletionID(responseID string) string {
+	if responseID != "" {
+		return "chatcmpl-" + responseID
+	}
+	return "chatcmpl-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+func newOpenAICallID() string {
+	return "call_" + strings.ReplaceAll(uuid.NewString(), "-", "")[:24]
+}
+
+// geminiToOpenAIStream translates one Gemini SSE stream into OpenAI chat completion chunks.
+type geminiToOpenAIStream struct {
+	id        string
+	model     string
+	created   int64
+	started   bool
+	toolCalls map[int]int // 候选索引 -> 已发送的 tool_calls 数量
+	done      bool
+}
+
+func newGeminiToOpenAIStream(model string, now time.Time) *geminiToOpenAIStream {
+	return &geminiToOpenAIStream{model: model, created: now.Unix(), toolCalls: make(map[int]int)}
+}
+
+// TranslateEvent converts one Gemini response chunk into OpenAI chunks.
+func (s *geminiToOpenAIStream) TranslateEvent(event SSEEvent) ([]SSEEvent, error) {
+	var resp geminiResponse
+	if err := json.Unmarshal(event.Data, &resp); err != nil {
+		return nil, fmt.Errorf("invalid gemini stream chunk: %w", err)
+	}
+	if isGeminiPromptBlocked(&resp) {
+		return []SSEEvent{{Data: geminiBlockedPromptError(resp.PromptFeedback)}}, nil
+	}
+
+	if s.id == "" {
+		s.id = geminiCompletionID(resp.ResponseID)
+	}
+	if resp.ModelVersion != "" {
+		s.model = resp.ModelVersion
+	}
+
+	var events []SSEEvent
+	if !s.started {
+		s.started = true
+		empty := ""
+		chunk, err := s.chunk(0, openAIDelta{Role: "assistant", Content: &empty}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, chunk...)
+	}
+
+	for _, candidate := range resp.Candidates {
+		text, toolCalls := geminiToOpenAIMessage(candidate.Content.Parts)
+		if text != "" || len(toolCalls) > 0 {
+			delta := openAIDelta{}
+			if text != "" {
+				delta.Content = &text
+			}
+			for i := range toolCalls {
+				index := s.toolCalls[candidate.Index]
+				s.toolCalls[candidate.Index]++
+				toolCalls[i].Index = &index
+			}
+			delta.ToolCalls = toolCalls
+			chunk, err := s.chunk(candidate.Index, delta, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, chunk...)
+		}
+
+		if candidate.FinishReason != "" {
+			finishReason := geminiFinishReasonToOpenAI(candidate.FinishReason, s.toolCalls[candidate.Index] > 0)
+			chunk, err := s.chunk(candidate.Index, openAIDelta{}, &finishReason, resp.UsageMetadata.openAIUsage())
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, chunk...)
+		}
+	}
+	return events, nil
+}
+
+// Finish terminates the OpenAI stream with the [DONE] marker.
+func (s *geminiToOpenAIStream) Finish() []SSEEvent {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	return []SSEEvent{{Data: []byte("[DONE]")}}
+}
+
+func (s *geminiToOpenAIStream) chunk(index int, delta openAIDelta, finishReason *string, usage *openAIUsage) ([]SSEEvent, error) {
+	data, err := json.Marshal(openAIChatChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: s.created,
+		Model:   s.model,
+		Choices: []openAIChunkChoice{{Index: index, Delta: delta, FinishReason: finishReason}},
+		Usage:   usage,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []SSEEvent{{Data: data}}, nil
+}
@@ -0,0 +1,257 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIToGeminiRequest(t *testing.T) {
+	body := `{
+		"model": "models/gemini-2.5-flash",
+		"max_tokens": 256,
+		"stop": ["END"],
+		"n": 2,
+		"stream": true,
+		"response_format": {"type": "json_object"},
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": [
+				{"type": "text", "text": "What is this?"},
+				{"type": "image_url", "image_url": {"url": "data:image/png;base64,iVBORw0KGgo="}},
+				{"type": "image_url", "image_url": {"url": "https://example.com/cat.png"}}
+			]},
+			{"role": "assistant", "content": null, "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{\"q\":\"cat\"}"}}
+			]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "a cat"}
+		],
+		"tools": [
+			{"type": "function", "function": {"name": "lookup", "parameters": {
+				"$schema": "http://json-schema.org/draft-07/schema#",
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {"q": {"type": "string"}, "additionalProperties": {"type": "string"}}
+			}}},
+			{"type": "function", "function": {"name": "now", "parameters": {"type": "object", "properties": {}}}}
+		],
+		"tool_choice": {"type": "function", "function": {"name": "lookup"}}
+	}`
+
+	out, translation, err := openAIToGeminiRequest([]byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, "gemini-2.5-flash", translation.model)
+	assert.Equal(t, "streamGenerateContent", translation.method())
+
+	var req geminiRequest
+	require.NoError(t, json.Unmarshal(out, &req))
+
+	require.NotNil(t, req.SystemInstruction)
+	assert.Equal(t, "Be brief.", req.SystemInstruction.Parts[0].Text)
+
+	config := req.GenerationConfig
+	require.NotNil(t, config)
+	assert.Equal(t, 256, *config.MaxOutputTokens)
+	assert.Equal(t, 2, *config.CandidateCount)
+	assert.Equal(t, []string{"END"}, config.StopSequences)
+	assert.Equal(t, "application/json", config.ResponseMimeType)
+
+	require.Len(t, req.Contents, 3)
+	user := req.Contents[0]
+	assert.Equal(t, "user", user.Role)
+	require.Len(t, user.Parts, 3)
+	assert.Equal(t, &geminiBlob{MimeType: "image/png", Data: "iVBORw0KGgo="}, user.Parts[1].InlineData)
+	assert.Equal(t, &geminiFileData{MimeType: "image/png", FileURI: "https://example.com/cat.png"}, user.Parts[2].FileData)
+
+	model := req.Contents[1]
+	assert.Equal(t, "model", model.Role)
+	assert.Equal(t, "lookup", model.Parts[0].FunctionCall.Name)
+	assert.JSONEq(t, `{"q":"cat"}`, string(model.Parts[0].FunctionCall.Args))
+
+	result := req.Contents[2].Parts[0].FunctionResponse
+	require.NotNil(t, result)
+	assert.Equal(t, "lookup", result.Name)
+	assert.JSONEq(t, `{"content":"a cat"}`, string(result.Response))
+
+	require.Len(t, req.Tools, 1)
+	declarations := req.Tools[0].FunctionDeclarations
+	require.Len(t, declarations, 2)
+	assert.JSONEq(t, `{"type":"object","properties":{"q":{"type":"string"},"additionalProperties":{"type":"string"}}}`, string(declarations[0].Parameters))
+	assert.Empty(t, declarations[1].Parameters)
+
+	require.NotNil(t, req.ToolConfig)
+	assert.Equal(t, "ANY", req.ToolConfig.FunctionCallingConfig.Mode)
+	assert.Equal(t, []string{"lookup"}, req.ToolConfig.FunctionCallingConfig.AllowedFunctionNames)
+}
+
+func TestOpenAIToGeminiChannel_TranslateRequest(t *testing.T) {
+	ch := &OpenAIToGeminiChannel{}
+
+	tests := []struct {
+		name, path, body, wantURL string
+	}{
+		{"chat", "/proxy/g1/v1/chat/completions?x=1", `{"model":"gemini-2.5-pro","messages":[{"role":"user","content":"hi"}]}`, "/proxy/g1/v1beta/models/gemini-2.5-pro:generateContent"},
+		{"stream", "/v1/chat/completions", `{"model":"gemini-2.5-pro","stream":true,"messages":[]}`, "/v1beta/models/gemini-2.5-pro:streamGenerateContent?alt=sse"},
+		{"embedding", "/proxy/g1/v1/embeddings", `{"model":"text-embedding-004","input":"hi"}`, "/proxy/g1/v1beta/models/text-embedding-004:embedContent"},
+		{"batch embedding", "/proxy/g1/v1/embeddings", `{"model":"text-embedding-004","input":["a","b"]}`, "/proxy/g1/v1beta/models/text-embedding-004:batchEmbedContents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			require.True(t, ch.TranslatesRequest(c))
+
+			translated, err := ch.TranslateRequest(c, []byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURL, translated.URL.String())
+			assert.NotEmpty(t, ch.ExtractModel(c, translated.Body))
+		})
+	}
+
+	for _, model := range []string{"../files/x", "gemini-2.5-pro?key=x", "a/b", "..", "gemini pro"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		_, err := ch.TranslateRequest(c, []byte(`{"model":"`+model+`","messages":[]}`))
+		assert.ErrorContains(t, err, "invalid model name", model)
+	}
+}
+
+func TestOpenAIToGeminiEmbedRequest(t *testing.T) {
+	out, translation, err := openAIToGeminiEmbedRequest([]byte(`{"model":"text-embedding-004","input":["a","b"],"dimensions":8}`))
+	require.NoError(t, err)
+	assert.True(t, translation.batch)
+	assert.JSONEq(t, `{"requests":[
+		{"model":"models/text-embedding-004","content":{"parts":[{"text":"a"}]},"outputDimensionality":8},
+		{"model":"models/text-embedding-004","content":{"parts":[{"text":"b"}]},"outputDimensionality":8}
+	]}`, string(out))
+
+	_, _, err = openAIToGeminiEmbedRequest([]byte(`{"model":"text-embedding-004","input":[1,2,3]}`))
+	assert.Error(t, err)
+}
+
+func TestGeminiToOpenAIResponse(t *testing.T) {
+	body := `{
+		"candidates": [{
+			"content": {"role": "model", "parts": [
+				{"text": "thinking...", "thought": true},
+				{"text": "Let me check."},
+				{"functionCall": {"name": "lookup", "args": {"q": "cat"}}}
+			]},
+			"finishReason": "STOP",
+			"index": 0
+		}],
+		"usageMetadata": {"promptTokenCount": 10, "candidatesTokenCount": 5, "thoughtsTokenCount": 2, "totalTokenCount": 17},
+		"modelVersion": "gemini-2.5-flash",
+		"responseId": "r1"
+	}`
+
+	out, err := geminiToOpenAIResponse([]byte(body), "gemini-flash", time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	var resp openAIChatResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "chatcmpl-r1", resp.ID)
+	assert.Equal(t, "gemini-2.5-flash", resp.Model)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	assert.JSONEq(t, `"Let me check."`, string(resp.Choices[0].Message.Content))
+	require.Len(t, resp.Choices[0].Message.ToolCalls, 1)
+	call := resp.Choices[0].Message.ToolCalls[0]
+	assert.True(t, strings.HasPrefix(call.ID, "call_"))
+	assert.JSONEq(t, `{"q":"cat"}`, call.Function.Arguments)
+	assert.Equal(t, openAIUsage{PromptTokens: 10, CompletionTokens: 7, TotalTokens: 17}, *resp.Usage)
+}
+
+func TestGeminiToOpenAIResponse_Safety(t *testing.T) {
+	out, err := geminiToOpenAIResponse([]byte(`{"candidates":[{"content":{},"finishReason":"SAFETY","index":0}]}`), "m", time.Now())
+	require.NoError(t, err)
+	var resp openAIChatResponse
+	require.NoError(t, json.Unmarshal(out, &resp))
+	assert.Equal(t, "content_filter", resp.Choices[0].FinishReason)
+
+	_, err = geminiToOpenAIResponse([]byte(`{"promptFeedback":{"blockReason":"SAFETY"}}`), "m", time.Now())
+	var translationErr *TranslationError
+	require.True(t, errors.As(err, &translationErr))
+	assert.Equal(t, http.StatusBadRequest, translationErr.StatusCode)
+	assert.JSONEq(t, `{"error":{"message":"The prompt was blocked by Gemini safety filters: SAFETY","type":"invalid_request_error","code":"content_filter"}}`, string(translationErr.Body))
+}
+
+func TestGeminiToOpenAIEmbeddings(t *testing.T) {
+	out, err := geminiToOpenAIEmbeddings([]byte(`{"embeddings":[{"values":[0.5,1]},{"values":[2]}]}`), &geminiTranslation{model: "text-embedding-004", embedding: true})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"object":"list","model":"text-embedding-004","data":[
+		{"object":"embedding","index":0,"embedding":[0.5,1]},
+		{"object":"embedding","index":1,"embedding":[2]}
+	],"usage":{"prompt_tokens":0,"completion_tokens":0,"total_tokens":0}}`, string(out))
+
+	out, err = geminiToOpenAIEmbeddings([]byte(`{"embedding":{"values":[1]}}`), &geminiTranslation{embedding: true, base64: true})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"embedding":"AACAPw=="`)
+}
+
+func TestGeminiToOpenAIStream(t *testing.T) {
+	upstream := strings.Join([]string{
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]},"index":0}],"responseId":"r1","modelVersion":"gemini-2.5-flash"}`,
+		``,
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"lo"},{"functionCall":{"name":"lookup","args":{"q":"cat"}}}]},"finishReason":"STOP","index":0}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":4,"totalTokenCount":7}}`,
+		``,
+	}, "\r\n")
+
+	stream := newGeminiToOpenAIStream("gemini-flash", time.Unix(1700000000, 0))
+	var out []SSEEvent
+	err := ReadSSE(strings.NewReader(upstream), func(event SSEEvent) error {
+		events, err := stream.TranslateEvent(event)
+		out = append(out, events...)
+		return err
+	})
+	require.NoError(t, err)
+	out = append(out, stream.Finish()...)
+
+	require.Len(t, out, 5)
+	var chunks []openAIChatChunk
+	for _, event := range out[:4] {
+		var chunk openAIChatChunk
+		require.NoError(t, json.Unmarshal(event.Data, &chunk))
+		assert.Equal(t, "chatcmpl-r1", chunk.ID)
+		assert.Equal(t, "gemini-2.5-flash", chunk.Model)
+		chunks = append(chunks, chunk)
+	}
+
+	assert.Equal(t, "assistant", chunks[0].Choices[0].Delta.Role)
+	assert.Equal(t, "Hel", *chunks[1].Choices[0].Delta.Content)
+	assert.Equal(t, "lo", *chunks[2].Choices[0].Delta.Content)
+	call := chunks[2].Choices[0].Delta.ToolCalls[0]
+	assert.Equal(t, 0, *call.Index)
+	assert.Equal(t, "lookup", call.Function.Name)
+	assert.Equal(t, "tool_calls", *chunks[3].Choices[0].FinishReason)
+	assert.Equal(t, 7, chunks[3].Usage.TotalTokens)
+	assert.Equal(t, "[DONE]", string(out[4].Data))
+}
+
+func TestGeminiToOpenAIStream_BlockedPrompt(t *testing.T) {
+	stream := newGeminiToOpenAIStream("m", time.Now())
+	events, err := stream.TranslateEvent(SSEEvent{Data: []byte(`{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT"}}`)})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Contains(t, string(events[0].Data), `"code":"content_filter"`)
+}
+
+func TestOpenAIToGeminiChannel_TranslateError(t *testing.T) {
+	ch := &OpenAIToGeminiChannel{}
+
+	out := ch.TranslateError(400, []byte(`{"error":{"code":400,"message":"API key not valid.","status":"INVALID_ARGUMENT"}}`))
+	assert.JSONEq(t, `{"error":{"message":"API key not valid.","type":"invalid_request_error","code":"invalid_argument"}}`, string(out))
+
+	out = ch.TranslateError(429, []byte(`[{"error":{"code":429,"message":"quota","status":"RESOURCE_EXHAUSTED"}}]`))
+	assert.JSONEq(t, `{"error":{"message":"quota","type":"rate_limit_error","code":"resource_exhausted"}}`, string(out))
+
+	assert.Equal(t, "not json", string(ch.TranslateError(500, []byte("not json"))))
+}
@@ -0,0 +1,150 @@
+package channel
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProtocolTranslator is implemented by channels that serve clients of one API protocol from an upstream
+// speaking another. The proxy translates the request before forwarding it and the response before returning it.
+type ProtocolTranslator interface {
+	// TranslatesRequest reports whether the request is subject to protocol translation.
+	TranslatesRequest(c *gin.Context) bool
+
+	// TranslateRequest converts the client request body into the upstream request.
+	TranslateRequest(c *gin.Context, bodyBytes []byte) (*TranslatedRequest, error)
+
+	// TranslateResponse converts a successful non-streaming upstream response body into the client format.
+	// A *TranslationError is returned when the upstream response must be reported to the client as an error.
+	TranslateResponse(c *gin.Context, body []byte) ([]byte, error)
+
+	// TranslateError converts an upstream error body into the client format, returning it unchanged if it cannot be parsed.
+	TranslateError(statusCode int, body []byte) []byte
+
+	// NewStreamTranslator returns a translator for the events of one streaming response.
+	NewStreamTranslator(c *gin.Context) StreamTranslator
+}
+
+// StreamTranslator converts the server-sent events of one upstream stream into client events.
+type StreamTranslator interface {
+	// TranslateEvent converts one upstream event into zero or more client events.
+	TranslateEvent(event SSEEvent) ([]SSEEvent, error)
+
+	// Finish returns the client events to send once the upstream stream has ended.
+	Finish() []SSEEvent
+}
+
+// StreamReader is implemented by stream translators whose upstream stream is not made of server-sent events.
+type StreamReader interface {
+	// ReadEvents decodes the upstream stream and calls fn for each event.
+	ReadEvents(r io.Reader, fn func(SSEEvent) error) error
+}
+
+// TranslatedRequest is the upstream form of a translated client request.
+type TranslatedRequest struct {
+	Body []byte
+	// URL replaces the client request URL when building the upstream URL.
+	URL *url.URL
+}
+
+// TranslationError is a translated upstream response that the client must receive as an error.
+type TranslationError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *TranslationError) Error() string {
+	return fmt.Sprintf("translated upstream error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// GetTranslator returns the channel's protocol translator when it applies to the request, or nil.
+func GetTranslator(ch ChannelProxy, c *gin.Context) ProtocolTranslator {
+	translator, ok := ch.(ProtocolTranslator)
+	if !ok || !translator.TranslatesRequest(c) {
+		return nil
+	}
+	return translator
+}
+
+// SSEEvent is a single server-sent event.
+type SSEEvent struct {
+	Event string
+	Data  []byte
+}
+
+// ReadSSE reads server-sent events from r and calls fn for each event carrying data.
+func ReadSSE(r io.Reader, fn func(SSEEvent) error) error {
+	reader := bufio.NewReader(r)
+	var event SSEEvent
+	var data [][]byte
+
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = SSEEvent{}
+			return nil
+		}
+		event.Data = bytes.Join(data, []byte("\n"))
+		err := fn(event)
+		event, data = SSEEvent{}, nil
+		return err
+	}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimRight(line, "\r\n")
+			switch {
+			case len(line) == 0:
+				if dispatchErr := dispatch(); dispatchErr != nil {
+					return dispatchErr
+				}
+			case line[0] == ':':
+				// 注释行，忽略
+			default:
+				field, value, _ := bytes.Cut(line, []byte(":"))
+				value = bytes.TrimPrefix(value, []byte(" "))
+				switch string(field) {
+				case "event":
+					event.Event = string(value)
+				case "data":
+					data = append(data, append([]byte(nil), value...))
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return dispatch()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ReadStream reads the upstream events of a translated stream, with the translator's own reader if it has one.
+func ReadStream(stream StreamTranslator, r io.Reader, fn func(SSEEvent) error) error {
+	if reader, ok := stream.(StreamReader); ok {
+		return reader.ReadEvents(r, fn)
+	}
+	return ReadSSE(r, fn)
+}
+
+// WriteSSE writes a server-sent event to w.
+func WriteSSE(w io.Writer, event SSEEvent) error {
+	var b strings.Builder
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event.Event)
+	}
+	for _, line := range bytes.Split(event.Data, []byte("\n")) {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
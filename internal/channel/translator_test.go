@@ -0,0 +1,44 @@
+package channel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive\r\n" +
+		"event: message\r\n" +
+		"data: {\"a\":1}\r\n" +
+		"\r\n" +
+		"data: line1\n" +
+		"data: line2\n" +
+		"\n" +
+		"event: empty\n" +
+		"\n" +
+		"data: [DONE]"
+
+	var events []SSEEvent
+	err := ReadSSE(strings.NewReader(input), func(event SSEEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+	assert.Equal(t, SSEEvent{Event: "message", Data: []byte(`{"a":1}`)}, events[0])
+	assert.Equal(t, "line1\nline2", string(events[1].Data))
+	assert.Equal(t, "", events[2].Event)
+	assert.Equal(t, "[DONE]", string(events[2].Data))
+}
+
+func TestWriteSSE(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSSE(&buf, SSEEvent{Event: "message_stop", Data: []byte(`{"type":"message_stop"}`)}))
+	require.NoError(t, WriteSSE(&buf, SSEEvent{Data: []byte("a\nb")}))
+
+	assert.Equal(t, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\ndata: a\ndata: b\n\n", buf.String())
+}
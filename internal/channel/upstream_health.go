@@ -0,0 +1,245 @@
+package channel
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gpt-load/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Circuit states of an upstream, as reported in group stats.
+const (
+	UpstreamStateClosed   = "closed"
+	UpstreamStateOpen     = "open"
+	UpstreamStateHalfOpen = "half_open"
+)
+
+// UpstreamHealth is a snapshot of the passive health of one upstream.
+type UpstreamHealth struct {
+	URL                 string     `json:"url"`
+	Weight              int        `json:"weight"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	EWMALatencyMs       float64    `json:"ewma_latency_ms"`
+	Outstanding         int        `json:"outstanding"`
+	OpenUntil           *time.Time `json:"open_until,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+}
+
+// upstreamState is the health and load of one upstream. It lives in the factory's upstreamRegistry rather than
+// in the channel, so that it survives the channel being rebuilt after a group edit. It is guarded by the
+// group's upstreamRegistry lock, which channels of the group share as BaseChannel.upstreamLock.
+type upstreamState struct {
+	health upstreamHealth
+	load   upstreamLoad
+}
+
+// upstreamRegistry holds the upstream states of one group, keyed by upstream URL.
+// Health is tracked in memory by each instance; nodes do not share it.
+type upstreamRegistry struct {
+	lock   sync.Mutex
+	states map[string]*upstreamState
+}
+
+// upstreamRegistry returns the upstream states of a group, keeping only the given upstream URLs.
+// The caller must hold f.cacheLock.
+func (f *Factory) upstreamRegistry(groupID uint, urls []string) *upstreamRegistry {
+	registry, ok := f.upstreamStates[groupID]
+	if !ok {
+		registry = &upstreamRegistry{states: make(map[string]*upstreamState)}
+		f.upstreamStates[groupID] = registry
+	}
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	states := make(map[string]*upstreamState, len(urls))
+	for _, u := range urls {
+		state, ok := registry.states[u]
+		if !ok {
+			state = &upstreamState{}
+		}
+		states[u] = state
+	}
+	registry.states = states
+	return registry
+}
+
+// upstreamHealth tracks the passive health of one upstream.
+type upstreamHealth struct {
+	consecutiveFailures int
+	openUntil           time.Time // zero while the circuit is closed
+	probeStartedAt      time.Time // zero when no half-open probe is in flight
+	lastError           string
+	lastFailureAt       time.Time
+}
+
+// state returns the circuit state; an open circuit whose cool-off has passed is half-open.
+func (h *upstreamHealth) state(now time.Time) string {
+	if h.openUntil.IsZero() {
+		return UpstreamStateClosed
+	}
+	if now.Before(h.openUntil) {
+		return UpstreamStateOpen
+	}
+	return UpstreamStateHalfOpen
+}
+
+// available reports whether the upstream may receive a request. A half-open upstream accepts a single probe;
+// a probe whose result never arrived is considered lost after one cool-off window.
+func (h *upstreamHealth) available(now time.Time, cooloff time.Duration) bool {
+	switch h.state(now) {
+	case UpstreamStateClosed:
+		return true
+	case UpstreamStateHalfOpen:
+		return h.probeStartedAt.IsZero() || now.Sub(h.probeStartedAt) > cooloff
+	default:
+		return false
+	}
+}
+
+// upstreamFailureThreshold returns the number of consecutive failures that ejects an upstream, 0 if disabled.
+func (b *BaseChannel) upstreamFailureThreshold() int {
+	if b.effectiveConfig == nil {
+		return 0
+	}
+	return b.effectiveConfig.UpstreamFailureThreshold
+}
+
+// upstreamCooloff returns how long an ejected upstream stays out of rotation before it is probed again.
+func (b *BaseChannel) upstreamCooloff() time.Duration {
+	if b.effectiveConfig == nil || b.effectiveConfig.UpstreamCooloffSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(b.effectiveConfig.UpstreamCooloffSeconds) * time.Second
+}
+
+// RecordUpstreamResult feeds the outcome of a request into the health of the upstream that served it.
+// Network errors and 5xx responses count as failures; any other response proves the upstream is reachable.
+// latency is the time until response headers arrived and is only sampled when there is a response.
+func (b *BaseChannel) RecordUpstreamResult(upstreamURL string, statusCode int, err error, latency time.Duration) {
+	b.upstreamLock.Lock()
+	defer b.upstreamLock.Unlock()
+
+	up := b.findUpstream(upstreamURL)
+	if up == nil {
+		return
+	}
+	h := &up.health
+	now := time.Now()
+	if err == nil && latency > 0 {
+		up.load.observe(latency, now)
+	}
+
+	if err == nil && statusCode < 500 {
+		if h.state(now) != UpstreamStateClosed {
+			logrus.WithField("upstream", up.URL.String()).Info("Upstream recovered, closing circuit")
+		}
+		h.consecutiveFailures = 0
+		h.openUntil = time.Time{}
+		h.probeStartedAt = time.Time{}
+		return
+	}
+
+	h.consecutiveFailures++
+	h.lastFailureAt = now
+	if err != nil {
+		h.lastError = err.Error()
+	} else {
+		h.lastError = "upstream returned status " + strconv.Itoa(statusCode)
+	}
+
+	threshold := b.upstreamFailureThreshold()
+	if threshold <= 0 {
+		return
+	}
+	if h.state(now) == UpstreamStateHalfOpen || h.consecutiveFailures >= threshold {
+		cooloff := b.upstreamCooloff()
+		h.openUntil = now.Add(cooloff)
+		h.probeStartedAt = time.Time{}
+		logrus.WithFields(logrus.Fields{
+			"upstream":            up.URL.String(),
+			"consecutiveFailures": h.consecutiveFailures,
+			"cooloff":             cooloff,
+		}).Warn("Upstream ejected after consecutive failures")
+	}
+}
+
+// UpstreamHealth returns a snapshot of the health of all upstreams.
+func (b *BaseChannel) UpstreamHealth() []UpstreamHealth {
+	b.upstreamLock.Lock()
+	defer b.upstreamLock.Unlock()
+
+	now := time.Now()
+	result := make([]UpstreamHealth, 0, len(b.Upstreams))
+	for i := range b.Upstreams {
+		up := &b.Upstreams[i]
+		h := up.health
+		snapshot := UpstreamHealth{
+			URL:                 up.URL.String(),
+			Weight:              up.Weight,
+			State:               h.state(now),
+			ConsecutiveFailures: h.consecutiveFailures,
+			EWMALatencyMs:       math.Round(up.load.latency(now)*100) / 100,
+			Outstanding:         up.load.outstanding,
+			LastError:           h.lastError,
+		}
+		if !h.openUntil.IsZero() {
+			openUntil := h.openUntil
+			snapshot.OpenUntil = &openUntil
+		}
+		if !h.lastFailureAt.IsZero() {
+			lastFailureAt := h.lastFailureAt
+			snapshot.LastFailureAt = &lastFailureAt
+		}
+		result = append(result, snapshot)
+	}
+	return result
+}
+
+// findUpstream returns the upstream whose base URL is the longest prefix of the request URL. The caller must hold the lock.
+func (b *BaseChannel) findUpstream(upstreamURL string) *UpstreamInfo {
+	var best *UpstreamInfo
+	bestLen := -1
+	for i := range b.Upstreams {
+		base := strings.TrimRight(b.Upstreams[i].URL.String(), "/")
+		if strings.HasPrefix(upstreamURL, base) && len(base) > bestLen {
+			best = &b.Upstreams[i]
+			bestLen = len(base)
+		}
+	}
+	return best
+}
+
+// GetUpstreamHealth returns the upstream health of the group's channel as seen by this instance only.
+// Groups that have not served any request on this instance yet report their configured upstreams as healthy.
+func (f *Factory) GetUpstreamHealth(group *models.Group) []UpstreamHealth {
+	f.cacheLock.Lock()
+	channel, ok := f.channelCache[group.ID]
+	f.cacheLock.Unlock()
+	if ok {
+		return channel.UpstreamHealth()
+	}
+
+	var defs []struct {
+		URL    string `json:"url"`
+		Weight int    `json:"weight"`
+	}
+	if err := json.Unmarshal(group.Upstreams, &defs); err != nil {
+		return nil
+	}
+	result := make([]UpstreamHealth, 0, len(defs))
+	for _, def := range defs {
+		if def.Weight <= 0 {
+			continue
+		}
+		result = append(result, UpstreamHealth{URL: def.URL, Weight: def.Weight, State: UpstreamStateClosed})
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package channel
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"gpt-load/internal/config"
+	"gpt-load/internal/httpclient"
+	"gpt-load/internal/models"
+	"gpt-load/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func newHealthTestChannel(t *testing.T, threshold int, urls ...string) *BaseChannel {
+	var upstreams []UpstreamInfo
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		upstreams = append(upstreams, UpstreamInfo{URL: u, Weight: 1, upstreamState: &upstreamState{}})
+	}
+	return &BaseChannel{
+		Name:            "test",
+		Upstreams:       upstreams,
+		upstreamLock:    &sync.Mutex{},
+		effectiveConfig: &types.SystemSettings{UpstreamFailureThreshold: threshold, UpstreamCooloffSeconds: 30},
+	}
+}
+
+func TestBaseChannel_UpstreamCircuitBreaker(t *testing.T) {
+	t.Run("consecutive failures eject the upstream", func(t *testing.T) {
+		b := newHealthTestChannel(t, 2, "http://a.example", "http://b.example")
+
+		b.RecordUpstreamResult("http://a.example/v1/chat/completions", 0, errors.New("connection refused"), 0)
+		b.RecordUpstreamResult("http://a.example/v1/chat/completions", http.StatusBadGateway, nil, 0)
+
+		for i := 0; i < 4; i++ {
+			assert.Equal(t, "http://b.example", b.getUpstreamURL().String())
+		}
+
+		health := b.UpstreamHealth()
+		assert.Equal(t, UpstreamStateOpen, health[0].State)
+		assert.Equal(t, 2, health[0].ConsecutiveFailures)
+		assert.NotNil(t, health[0].OpenUntil)
+		assert.Equal(t, UpstreamStateClosed, health[1].State)
+	})
+
+	t.Run("client errors keep the upstream healthy", func(t *testing.T) {
+		b := newHealthTestChannel(t, 1, "http://a.example", "http://b.example")
+
+		b.RecordUpstreamResult("http://a.example/v1/chat/completions", http.StatusUnauthorized, nil, 0)
+		assert.Equal(t, UpstreamStateClosed, b.UpstreamHealth()[0].State)
+	})
+
+	t.Run("half-open probe closes the circuit on success", func(t *testing.T) {
+		b := newHealthTestChannel(t, 1, "http://a.example", "http://b.example")
+		b.RecordUpstreamResult("http://a.example/x", 0, errors.New("timeout"), 0)
+		b.Upstreams[0].health.openUntil = time.Now().Add(-time.Second)
+
+		seen := map[string]int{}
+		for i := 0; i < 4; i++ {
+			seen[b.getUpstreamURL().String()]++
+		}
+		assert.Equal(t, 1, seen["http://a.example"], "only a single probe goes to the half-open upstream")
+
+		b.RecordUpstreamResult("http://a.example/x", http.StatusOK, nil, 0)
+		assert.Equal(t, UpstreamStateClosed, b.UpstreamHealth()[0].State)
+		assert.Equal(t, 0, b.UpstreamHealth()[0].ConsecutiveFailures)
+	})
+
+	t.Run("failed probe reopens the circuit", func(t *testing.T) {
+		b := newHealthTestChannel(t, 3, "http://a.example", "http://b.example")
+		for i := 0; i < 3; i++ {
+			b.RecordUpstreamResult("http://a.example/x", http.StatusServiceUnavailable, nil, 0)
+		}
+		b.Upstreams[0].health.openUntil = time.Now().Add(-time.Second)
+
+		b.RecordUpstreamResult("http://a.example/x", http.StatusServiceUnavailable, nil, 0)
+		assert.Equal(t, UpstreamStateOpen, b.UpstreamHealth()[0].State)
+	})
+
+	t.Run("all upstreams ejected falls back to all", func(t *testing.T) {
+		b := newHealthTestChannel(t, 1, "http://a.example")
+		b.RecordUpstreamResult("http://a.example/x", 0, errors.New("connection reset"), 0)
+
+		assert.Equal(t, "http://a.example", b.getUpstreamURL().String())
+	})
+
+	t.Run("threshold zero disables ejection", func(t *testing.T) {
+		b := newHealthTestChannel(t, 0, "http://a.example", "http://b.example")
+		for i := 0; i < 10; i++ {
+			b.RecordUpstreamResult("http://a.example/x", http.StatusInternalServerError, nil, 0)
+		}
+		assert.Equal(t, UpstreamStateClosed, b.UpstreamHealth()[0].State)
+		assert.Equal(t, 10, b.UpstreamHealth()[0].ConsecutiveFailures)
+	})
+
+	t.Run("longest matching upstream is charged", func(t *testing.T) {
+		b := newHealthTestChannel(t, 1, "http://a.example", "http://a.example/v2")
+		b.RecordUpstreamResult("http://a.example/v2/chat", 0, errors.New("eof"), 0)
+
+		health := b.UpstreamHealth()
+		assert.Equal(t, UpstreamStateClosed, health[0].State)
+		assert.Equal(t, UpstreamStateOpen, health[1].State)
+	})
+}
+
+func TestFactory_UpstreamHealthSurvivesRebuild(t *testing.T) {
+	f := NewFactory(&config.SystemSettingsManager{}, httpclient.NewHTTPClientManager())
+	group := &models.Group{
+		ID:              1,
+		ChannelType:     "openai",
+		TestModel:       "gpt-4o",
+		Upstreams:       datatypes.JSON(`[{"url":"http://a.example","weight":1},{"url":"http://b.example","weight":1}]`),
+		EffectiveConfig: types.SystemSettings{UpstreamFailureThreshold: 1, UpstreamCooloffSeconds: 30},
+	}
+	ch, err := f.GetChannel(group)
+	require.NoError(t, err)
+	ch.RecordUpstreamResult("http://a.example/v1/chat/completions", http.StatusBadGateway, nil, 0)
+
+	// Editing the group rebuilds the channel; the ejected upstream stays ejected.
+	edited := *group
+	edited.TestModel = "gpt-4o-mini"
+	edited.Upstreams = datatypes.JSON(`[{"url":"http://a.example","weight":1},{"url":"http://b.example","weight":1},{"url":"http://c.example","weight":1}]`)
+	rebuilt, err := f.GetChannel(&edited)
+	require.NoError(t, err)
+	require.NotSame(t, ch, rebuilt)
+
+	health := rebuilt.UpstreamHealth()
+	require.Len(t, health, 3)
+	assert.Equal(t, UpstreamStateOpen, health[0].State)
+	assert.Equal(t, 1, health[0].ConsecutiveFailures)
+	assert.Equal(t, UpstreamStateClosed, health[1].State)
+	assert.Equal(t, UpstreamStateClosed, health[2].State)
+
+	// Results still reported through the old channel reach the same state.
+	ch.RecordUpstreamResult("http://b.example/v1/chat/completions", 0, errors.New("connection refused"), 0)
+	assert.Equal(t, UpstreamStateOpen, rebuilt.UpstreamHealth()[1].State)
+
+	// An upstream removed from the group starts fresh when it is added back.
+	removed := edited
+	removed.Upstreams = datatypes.JSON(`[{"url":"http://c.example","weight":1}]`)
+	_, err = f.GetChannel(&removed)
+	require.NoError(t, err)
+	readded, err := f.GetChannel(&edited)
+	require.NoError(t, err)
+	assert.Equal(t, UpstreamStateClosed, readded.UpstreamHealth()[0].State)
+}
@@ -0,0 +1,94 @@
+package channel
+
+import (
+	"sync"
+	"time"
+)
+
+// Upstream selection strategies supported by BaseChannel.
+const (
+	UpstreamSelectionWeightedRoundRobin = "weighted_round_robin"
+	UpstreamSelectionEWMALatency        = "ewma_latency"
+	UpstreamSelectionLeastOutstanding   = "least_outstanding"
+)
+
+const (
+	// ewmaAlpha is the weight of the newest latency sample in the moving average.
+	ewmaAlpha = 0.3
+	// ewmaStaleAfter is how long a latency average stays valid without new samples. Stale upstreams
+	// are scored as unknown so that a slow upstream gets re-measured once it may have recovered.
+	ewmaStaleAfter = 30 * time.Second
+)
+
+// upstreamLoad tracks the observed latency and in-flight requests of one upstream.
+// It is part of upstreamState and guarded the same way.
+type upstreamLoad struct {
+	ewmaLatency  float64 // milliseconds until response headers arrived
+	lastSampleAt time.Time
+	outstanding  int
+}
+
+// observe folds a response-header latency sample into the moving average.
+func (l *upstreamLoad) observe(latency time.Duration, now time.Time) {
+	sample := float64(latency) / float64(time.Millisecond)
+	if l.lastSampleAt.IsZero() {
+		l.ewmaLatency = sample
+	} else {
+		l.ewmaLatency = ewmaAlpha*sample + (1-ewmaAlpha)*l.ewmaLatency
+	}
+	l.lastSampleAt = now
+}
+
+// latency returns the moving average, or 0 if there is no recent sample.
+func (l *upstreamLoad) latency(now time.Time) float64 {
+	if l.lastSampleAt.IsZero() || now.Sub(l.lastSampleAt) > ewmaStaleAfter {
+		return 0
+	}
+	return l.ewmaLatency
+}
+
+// upstreamSelectionStrategy returns the configured strategy of the channel.
+func (b *BaseChannel) upstreamSelectionStrategy() string {
+	if b.effectiveConfig == nil {
+		return UpstreamSelectionWeightedRoundRobin
+	}
+	return b.effectiveConfig.UpstreamSelectionStrategy
+}
+
+// selectionScore returns the cost of sending the next request to the upstream; lower is better.
+// Weights scale the cost so that heavier upstreams are still preferred at equal load.
+func selectionScore(strategy string, up *UpstreamInfo, now time.Time) (float64, bool) {
+	switch strategy {
+	case UpstreamSelectionEWMALatency:
+		return up.load.latency(now) / float64(up.Weight), true
+	case UpstreamSelectionLeastOutstanding:
+		return float64(up.load.outstanding+1) / float64(up.Weight), true
+	default:
+		return 0, false
+	}
+}
+
+// TrackUpstreamRequest marks a request to upstreamURL as in flight for the least-outstanding strategy.
+// The returned function ends the request; it is safe to call more than once.
+func (b *BaseChannel) TrackUpstreamRequest(upstreamURL string) func() {
+	b.upstreamLock.Lock()
+	up := b.findUpstream(upstreamURL)
+	if up != nil {
+		up.load.outstanding++
+	}
+	b.upstreamLock.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if up == nil {
+				return
+			}
+			b.upstreamLock.Lock()
+			if up.load.outstanding > 0 {
+				up.load.outstanding--
+			}
+			b.upstreamLock.Unlock()
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package channel
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseChannel_UpstreamSelectionStrategies(t *testing.T) {
+	t.Run("ewma latency prefers the faster upstream", func(t *testing.T) {
+		b := newHealthTestChannel(t, 5, "http://near.example", "http://far.example")
+		b.effectiveConfig.UpstreamSelectionStrategy = UpstreamSelectionEWMALatency
+
+		b.RecordUpstreamResult("http://near.example/x", http.StatusOK, nil, 20*time.Millisecond)
+		b.RecordUpstreamResult("http://far.example/x", http.StatusOK, nil, 400*time.Millisecond)
+
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, "http://near.example", b.getUpstreamURL().String())
+		}
+
+		// The near upstream slows down and the moving average adapts.
+		for i := 0; i < 10; i++ {
+			b.RecordUpstreamResult("http://near.example/x", http.StatusOK, nil, 2*time.Second)
+		}
+		assert.Equal(t, "http://far.example", b.getUpstreamURL().String())
+	})
+
+	t.Run("ewma latency re-measures stale upstreams", func(t *testing.T) {
+		b := newHealthTestChannel(t, 5, "http://near.example", "http://far.example")
+		b.effectiveConfig.UpstreamSelectionStrategy = UpstreamSelectionEWMALatency
+
+		b.RecordUpstreamResult("http://near.example/x", http.StatusOK, nil, 20*time.Millisecond)
+		b.RecordUpstreamResult("http://far.example/x", http.StatusOK, nil, 400*time.Millisecond)
+		b.Upstreams[1].load.lastSampleAt = time.Now().Add(-2 * ewmaStaleAfter)
+
+		assert.Equal(t, "http://far.example", b.getUpstreamURL().String())
+	})
+
+	t.Run("least outstanding spreads in-flight requests", func(t *testing.T) {
+		b := newHealthTestChannel(t, 5, "http://a.example", "http://b.example")
+		b.effectiveConfig.UpstreamSelectionStrategy = UpstreamSelectionLeastOutstanding
+
+		endA := b.TrackUpstreamRequest("http://a.example/x")
+		endA2 := b.TrackUpstreamRequest("http://a.example/x")
+		assert.Equal(t, "http://b.example", b.getUpstreamURL().String())
+
+		endB := b.TrackUpstreamRequest("http://b.example/x")
+		assert.Equal(t, "http://b.example", b.getUpstreamURL().String())
+
+		endA()
+		endA() // ending twice must not release more than once
+		endA2()
+		assert.Equal(t, "http://a.example", b.getUpstreamURL().String())
+		endB()
+
+		health := b.UpstreamHealth()
+		assert.Equal(t, 0, health[0].Outstanding)
+		assert.Equal(t, 0, health[1].Outstanding)
+	})
+
+	t.Run("weighted round robin ignores latency", func(t *testing.T) {
+		b := newHealthTestChannel(t, 5, "http://a.example", "http://b.example")
+		b.effectiveConfig.UpstreamSelectionStrategy = UpstreamSelectionWeightedRoundRobin
+		b.RecordUpstreamResult("http://a.example/x", http.StatusOK, nil, time.Millisecond)
+
+		seen := map[string]int{}
+		for i := 0; i < 4; i++ {
+			seen[b.getUpstreamURL().String()]++
+		}
+		assert.Equal(t, 2, seen["http://a.example"])
+		assert.Equal(t, 2, seen["http://b.example"])
+	})
+}
@@ -0,0 +1,362 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	Register("vertex", newVertexChannel)
+	RegisterConfigValidator("vertex", validateVertexConfig)
+}
+
+const (
+	vertexDefaultLocation    = "us-central1"
+	vertexAnthropicVersion   = "vertex-2023-10-16"
+	vertexGlobalHost         = "aiplatform.googleapis.com"
+	vertexRegionalHostSuffix = "-aiplatform.googleapis.com"
+	vertexModelKey           = "vertexModel"
+)
+
+var (
+	vertexLocationPattern = regexp.MustCompile(`^[a-z0-9-]{1,63}$`)
+	vertexProjectPattern  = regexp.MustCompile(`^[A-Za-z0-9.:-]{1,100}$`)
+	// vertexModelPathPattern matches model method paths without a project, such as Gemini's
+	// /v1beta/models/<model>:generateContent or /v1/publishers/anthropic/models/<model>:rawPredict.
+	vertexModelPathPattern = regexp.MustCompile(`/v1(?:beta1?)?/(?:publishers/([^/]+)/)?models/([^/:]+):([A-Za-z]+)$`)
+	vertexLocationSegment  = regexp.MustCompile(`/locations/([^/]+)/`)
+)
+
+// vertexConfig is the channel_config of a vertex group.
+type vertexConfig struct {
+	// ProjectID overrides the project_id of the service account keys.
+	ProjectID string `json:"project_id"`
+	// Location is the region serving the models, us-central1 by default. "global" uses the global endpoint.
+	Location string `json:"location"`
+	// ModelLocations overrides the location per model, e.g. for Anthropic models only served in us-east5.
+	ModelLocations map[string]string `json:"model_locations"`
+	// TokenURL overrides the OAuth token endpoint of the service account keys.
+	TokenURL string `json:"token_url"`
+}
+
+// VertexChannel serves Gemini and Anthropic Messages API clients from Google Vertex AI. Each key is a service
+// account JSON key, exchanged for cached OAuth access tokens. Requests for aiplatform.googleapis.com are sent to
+// the regional endpoint of the model's location; other upstreams are used as they are.
+type VertexChannel struct {
+	*GeminiChannel
+	config vertexConfig
+	tokens *googleTokenCache
+}
+
+func newVertexChannel(f *Factory, group *models.Group) (ChannelProxy, error) {
+	var config vertexConfig
+	if err := decodeChannelConfig(group.ChannelConfig, &config); err != nil {
+		return nil, fmt.Errorf("invalid channel config for vertex channel: %w", err)
+	}
+	if config.Location == "" {
+		config.Location = vertexDefaultLocation
+	}
+
+	base, err := f.newBaseChannel("vertex", group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VertexChannel{
+		GeminiChannel: &GeminiChannel{BaseChannel: base},
+		config:        config,
+		tokens:        f.googleTokens,
+	}, nil
+}
+
+func validateVertexConfig(config map[string]any) error {
+	var cfg vertexConfig
+	if err := decodeChannelConfig(config, &cfg); err != nil {
+		return err
+	}
+	if cfg.ProjectID != "" && !vertexProjectPattern.MatchString(cfg.ProjectID) {
+		return fmt.Errorf("invalid project ID %q", cfg.ProjectID)
+	}
+	if cfg.Location != "" && !vertexLocationPattern.MatchString(cfg.Location) {
+		return fmt.Errorf("invalid location %q", cfg.Location)
+	}
+	for model, location := range cfg.ModelLocations {
+		if strings.TrimSpace(model) == "" {
+			return errors.New("model location mapping has an empty model name")
+		}
+		if !vertexLocationPattern.MatchString(location) {
+			return fmt.Errorf("invalid location %q for model %q", location, model)
+		}
+	}
+	if cfg.TokenURL != "" {
+		u, err := url.Parse(cfg.TokenURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid token URL %q", cfg.TokenURL)
+		}
+	}
+	return nil
+}
+
+// location returns the location serving the model.
+func (ch *VertexChannel) location(model string) string {
+	if location, ok := ch.config.ModelLocations[model]; ok {
+		return location
+	}
+	return ch.config.Location
+}
+
+// tokenURL returns the OAuth token endpoint for the service account.
+func (ch *VertexChannel) tokenURL(account *googleServiceAccount) string {
+	if ch.config.TokenURL != "" {
+		return ch.config.TokenURL
+	}
+	if account.TokenURI != "" {
+		return account.TokenURI
+	}
+	return googleDefaultTokenURL
+}
+
+// vertexHost returns the endpoint host of the location for Google hosted upstreams.
+func vertexHost(host, location string) string {
+	if host != vertexGlobalHost && !strings.HasSuffix(host, vertexRegionalHostSuffix) {
+		return host
+	}
+	if location == "global" {
+		return vertexGlobalHost
+	}
+	return location + vertexRegionalHostSuffix
+}
+
+// prepareRequest points the request at the project's publisher model path and regional host, then
+// authorizes it with an access token of the service account.
+func (ch *VertexChannel) prepareRequest(req *http.Request, account *googleServiceAccount) error {
+	if m := vertexModelPathPattern.FindStringSubmatchIndex(req.URL.Path); m != nil && !strings.Contains(req.URL.Path, "/projects/") {
+		project := ch.config.ProjectID
+		if project == "" {
+			project = account.ProjectID
+		}
+		if project == "" {
+			return errors.New("no project_id in the service account key or channel config")
+		}
+
+		publisher := "google"
+		if m[2] >= 0 {
+			publisher = req.URL.Path[m[2]:m[3]]
+		}
+		model := req.URL.Path[m[4]:m[5]]
+		method := req.URL.Path[m[6]:m[7]]
+		req.URL.Path = req.URL.Path[:m[0]] + "/v1/projects/" + project + "/locations/" + ch.location(model) +
+			"/publishers/" + publisher + "/models/" + model + ":" + method
+		req.URL.RawPath = ""
+	}
+
+	if m := vertexLocationSegment.FindStringSubmatch(req.URL.Path); m != nil {
+		req.URL.Host = vertexHost(req.URL.Host, m[1])
+		req.Host = req.URL.Host
+	}
+
+	token, err := ch.tokens.Token(req.Context(), ch.HTTPClient, account, ch.tokenURL(account))
+	if err != nil {
+		return fmt.Errorf("failed to get access token for %s: %w", account.ClientEmail, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// ModifyRequest rewrites the request for Vertex AI and sets the service account's access token.
+func (ch *VertexChannel) ModifyRequest(req *http.Request, apiKey *models.APIKey, group *models.Group) {
+	account, err := parseGoogleServiceAccount(apiKey.KeyValue)
+	if err == nil {
+		err = ch.prepareRequest(req, account)
+	}
+	if err != nil {
+		// 未授权的请求会被上游拒绝，按密钥失败处理
+		logrus.WithField("key_id", apiKey.ID).WithError(err).Warn("Failed to authorize Vertex request")
+	}
+}
+
+// ExtractModel also resolves the model of translated Anthropic requests, which carry it in the upstream path only.
+func (ch *VertexChannel) ExtractModel(c *gin.Context, bodyBytes []byte) string {
+	if model := ch.GeminiChannel.ExtractModel(c, bodyBytes); model != "" {
+		return model
+	}
+	return c.GetString(vertexModelKey)
+}
+
+// IsQuotaExhausted always returns false: Vertex AI reports rate limits as RESOURCE_EXHAUSTED,
+// which recover without any change to the key.
+func (ch *VertexChannel) IsQuotaExhausted(statusCode int, errorBody []byte) bool {
+	return false
+}
+
+// TranslatesRequest reports whether the request is an Anthropic Messages API call.
+func (ch *VertexChannel) TranslatesRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodPost && strings.HasSuffix(c.Request.URL.Path, "/v1/messages")
+}
+
+// TranslateRequest turns a Messages API request into a rawPredict call of the Anthropic publisher model.
+func (ch *VertexChannel) TranslateRequest(c *gin.Context, bodyBytes []byte) (*TranslatedRequest, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		return nil, fmt.Errorf("invalid messages request: %w", err)
+	}
+
+	var model string
+	if err := json.Unmarshal(body["model"], &model); err != nil || model == "" {
+		return nil, errors.New("model is required")
+	}
+	var stream bool
+	if raw, ok := body["stream"]; ok {
+		if err := json.Unmarshal(raw, &stream); err != nil {
+			return nil, fmt.Errorf("invalid stream field: %w", err)
+		}
+	}
+	delete(body, "model")
+	if _, ok := body["anthropic_version"]; !ok {
+		body["anthropic_version"] = jsonString(vertexAnthropicVersion)
+	}
+
+	out, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(vertexModelKey, model)
+
+	// /proxy/<group>/v1/messages -> /proxy/<group>/v1/publishers/anthropic/models/<model>:rawPredict,
+	// completed with the project and location once the key is known
+	method := ":rawPredict"
+	if stream {
+		method = ":streamRawPredict"
+	}
+	upstreamURL := *c.Request.URL
+	upstreamURL.Path = strings.TrimSuffix(upstreamURL.Path, "/v1/messages") + "/v1/publishers/anthropic/models/" + model + method
+	upstreamURL.RawPath = ""
+	upstreamURL.RawQuery = ""
+	return &TranslatedRequest{Body: out, URL: &upstreamURL}, nil
+}
+
+// TranslateResponse returns the response unchanged, as Vertex AI returns Anthropic messages as is.
+func (ch *VertexChannel) TranslateResponse(c *gin.Context, body []byte) ([]byte, error) {
+	return body, nil
+}
+
+// TranslateError converts Google API errors, such as authorization failures, into Anthropic error bodies.
+func (ch *VertexChannel) TranslateError(statusCode int, body []byte) []byte {
+	var googleErr geminiErrorResponse
+	if err := json.Unmarshal(body, &googleErr); err != nil || googleErr.Error.Message == "" {
+		return body
+	}
+	return newAnthropicError(statusCode, googleErr.Error.Message)
+}
+
+// NewStreamTranslator returns a translator passing the Anthropic events through.
+func (ch *VertexChannel) NewStreamTranslator(c *gin.Context) StreamTranslator {
+	return passthroughStream{}
+}
+
+// passthroughStream forwards the upstream events unchanged.
+type passthroughStream struct{}
+
+func (passthroughStream) TranslateEvent(event SSEEvent) ([]SSEEvent, error) {
+	return []SSEEvent{event}, nil
+}
+
+func (passthroughStream) Finish() []SSEEvent {
+	return nil
+}
+
+// ValidateKey checks if the given service account key is valid by minting a token and calling the test model.
+func (ch *VertexChannel) ValidateKey(ctx context.Context, apiKey *models.APIKey, group *models.Group) (bool, error) {
+	upstreamURL := ch.getUpstreamURL()
+	if upstreamURL == nil {
+		return false, fmt.Errorf("no upstream URL configured for channel %s", ch.Name)
+	}
+	account, err := parseGoogleServiceAccount(apiKey.KeyValue)
+	if err != nil {
+		return false, err
+	}
+
+	// Use a minimal, low-cost payload for validation
+	var payload gin.H
+	validationEndpoint := ch.ValidationEndpoint
+	if strings.HasPrefix(ch.TestModel, "claude") {
+		if validationEndpoint == "" {
+			validationEndpoint = "/v1/publishers/anthropic/models/" + ch.TestModel + ":rawPredict"
+		}
+		payload = gin.H{
+			"anthropic_version": vertexAnthropicVersion,
+			"max_tokens":        1,
+			"messages": []gin.H{
+				{"role": "user", "content": "hi"},
+			},
+		}
+	} else {
+		if validationEndpoint == "" {
+			validationEndpoint = "/v1/publishers/google/models/" + ch.TestModel + ":generateContent"
+		}
+		payload = gin.H{
+			"contents": []gin.H{
+				{"role": "user", "parts": []gin.H{{"text": "hi"}}},
+			},
+		}
+	}
+	reqURL, err := url.JoinPath(upstreamURL.String(), validationEndpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to join upstream URL and validation endpoint: %w", err)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal validation payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL, bytes.NewBuffer(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create validation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := ch.prepareRequest(req, account); err != nil {
+		return false, err
+	}
+
+	// Apply custom header rules if available
+	if len(group.HeaderRuleList) > 0 {
+		headerCtx := utils.NewHeaderVariableContext(group, apiKey)
+		utils.ApplyHeaderRules(req, group.HeaderRuleList, headerCtx)
+	}
+
+	resp, err := ch.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send validation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Any 2xx status code indicates the key is valid.
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+
+	// For non-200 responses, parse the body to provide a more specific error reason.
+	errorBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("key is invalid (status %d), but failed to read error body: %w", resp.StatusCode, err)
+	}
+
+	parsedError := app_errors.ParseUpstreamError(errorBody)
+
+	return false, fmt.Errorf("[status %d] %s", resp.StatusCode, parsedError)
+}
@@ -0,0 +1,137 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"gpt-load/internal/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVertexChannel(t *testing.T, upstream, tokenURL string) *VertexChannel {
+	base := newHealthTestChannel(t, 5, upstream)
+	base.HTTPClient = http.DefaultClient
+	base.TestModel = "gemini-2.0-flash"
+	return &VertexChannel{
+		GeminiChannel: &GeminiChannel{BaseChannel: base},
+		config: vertexConfig{
+			Location:       "us-central1",
+			ModelLocations: map[string]string{"claude-sonnet-4@20250514": "us-east5"},
+			TokenURL:       tokenURL,
+		},
+		tokens: newGoogleTokenCache(),
+	}
+}
+
+func TestVertexChannel_ModifyRequest(t *testing.T) {
+	key, publicKey := newTestServiceAccountKey(t, "proj")
+	var minted atomic.Int32
+	tokenServer := newTokenStub(t, publicKey, &minted)
+	defer tokenServer.Close()
+
+	ch := newTestVertexChannel(t, "https://aiplatform.googleapis.com", tokenServer.URL+"/token")
+
+	tests := []struct {
+		name, url, want string
+	}{
+		{
+			"gemini path",
+			"https://aiplatform.googleapis.com/v1beta/models/gemini-2.0-flash:streamGenerateContent?alt=sse",
+			"https://us-central1-aiplatform.googleapis.com/v1/projects/proj/locations/us-central1/publishers/google/models/gemini-2.0-flash:streamGenerateContent?alt=sse",
+		},
+		{
+			"anthropic model location",
+			"https://aiplatform.googleapis.com/v1/publishers/anthropic/models/claude-sonnet-4@20250514:rawPredict",
+			"https://us-east5-aiplatform.googleapis.com/v1/projects/proj/locations/us-east5/publishers/anthropic/models/claude-sonnet-4@20250514:rawPredict",
+		},
+		{
+			"native path",
+			"https://aiplatform.googleapis.com/v1/projects/other/locations/global/publishers/google/models/gemini-2.5-pro:generateContent",
+			"https://aiplatform.googleapis.com/v1/projects/other/locations/global/publishers/google/models/gemini-2.5-pro:generateContent",
+		},
+		{
+			"custom upstream host",
+			"http://127.0.0.1:8080/vertex/v1beta/models/gemini-2.0-flash:generateContent",
+			"http://127.0.0.1:8080/vertex/v1/projects/proj/locations/us-central1/publishers/google/models/gemini-2.0-flash:generateContent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.url, nil)
+			ch.ModifyRequest(req, &models.APIKey{KeyValue: key}, &models.Group{})
+			assert.Equal(t, tt.want, req.URL.String())
+			assert.Equal(t, req.URL.Host, req.Host)
+			assert.Equal(t, "Bearer tok-1", req.Header.Get("Authorization"))
+		})
+	}
+	assert.Equal(t, int32(1), minted.Load())
+}
+
+func TestVertexChannel_TranslateRequest(t *testing.T) {
+	ch := newTestVertexChannel(t, "https://aiplatform.googleapis.com", "")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/proxy/vx/v1/messages", nil)
+	require.True(t, ch.TranslatesRequest(c))
+
+	translated, err := ch.TranslateRequest(c, []byte(`{"model":"claude-sonnet-4@20250514","stream":true,"max_tokens":5,"messages":[]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"anthropic_version":"vertex-2023-10-16","stream":true,"max_tokens":5,"messages":[]}`, string(translated.Body))
+	assert.Equal(t, "/proxy/vx/v1/publishers/anthropic/models/claude-sonnet-4@20250514:streamRawPredict", translated.URL.String())
+	assert.Equal(t, "claude-sonnet-4@20250514", ch.ExtractModel(c, translated.Body))
+
+	out := ch.TranslateError(401, []byte(`{"error":{"code":401,"message":"Request had invalid authentication credentials.","status":"UNAUTHENTICATED"}}`))
+	assert.JSONEq(t, `{"type":"error","error":{"type":"authentication_error","message":"Request had invalid authentication credentials."}}`, string(out))
+}
+
+func TestVertexChannel_ValidateKey(t *testing.T) {
+	key, publicKey := newTestServiceAccountKey(t, "proj")
+	var minted atomic.Int32
+	tokenServer := newTokenStub(t, publicKey, &minted)
+	defer tokenServer.Close()
+
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Contains(t, body, "contents")
+		_, _ = io.WriteString(w, `{"candidates":[]}`)
+	}))
+	defer server.Close()
+
+	ch := newTestVertexChannel(t, server.URL, tokenServer.URL+"/token")
+
+	valid, err := ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: key}, &models.Group{})
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, "/v1/projects/proj/locations/us-central1/publishers/google/models/gemini-2.0-flash:generateContent", gotPath)
+	assert.Equal(t, "Bearer tok-1", gotAuth)
+
+	otherKey, _ := newTestServiceAccountKey(t, "proj")
+	valid, err = ch.ValidateKey(context.Background(), &models.APIKey{KeyValue: otherKey}, &models.Group{})
+	assert.False(t, valid)
+	assert.ErrorContains(t, err, "Invalid JWT Signature.")
+}
+
+func TestValidateVertexConfig(t *testing.T) {
+	assert.NoError(t, validateVertexConfig(nil))
+	assert.NoError(t, validateVertexConfig(map[string]any{
+		"project_id":      "my-project",
+		"location":        "global",
+		"model_locations": map[string]any{"claude-sonnet-4@20250514": "us-east5"},
+		"token_url":       "http://127.0.0.1:9000/token",
+	}))
+	assert.Error(t, validateVertexConfig(map[string]any{"location": "us-central1/../x"}))
+	assert.Error(t, validateVertexConfig(map[string]any{"token_url": "ftp://x"}))
+	assert.Error(t, validateVertexConfig(map[string]any{"region": "us-central1"}))
+	assert.False(t, strings.Contains(vertexHost("127.0.0.1:9000", "us-east5"), "aiplatform"))
+}
@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/datatypes"
@@ -299,13 +300,8 @@ func (sm *SystemSettingsManager) ValidateSettings(settingsMap map[string]any) er
 			if !ok {
 				return fmt.Errorf("invalid type for %s: expected a string, got %T", key, value)
 			}
-			for _, rule := range rules {
-				trimmedRule := strings.TrimSpace(rule)
-				if trimmedRule == "required" {
-					if strVal == "" {
-						return fmt.Errorf("value for %s is required", key)
-					}
-				}
+			if err := validateStringRules(key, strVal, rules); err != nil {
+				return err
 			}
 		default:
 			return fmt.Errorf("unsupported type for setting key validation: %s", key)
@@ -369,13 +365,8 @@ func (sm *SystemSettingsManager) ValidateGroupConfigOverrides(configMap map[stri
 			if !ok {
 				continue
 			}
-			for _, rule := range rules {
-				trimmedRule := strings.TrimSpace(rule)
-				if trimmedRule == "required" {
-					if strVal == "" {
-						return fmt.Errorf("value for %s is required", key)
-					}
-				}
+			if err := validateStringRules(key, strVal, rules); err != nil {
+				return err
 			}
 		case reflect.Bool:
 			_, ok := value.(bool)
@@ -390,6 +381,43 @@ func (sm *SystemSettingsManager) ValidateGroupConfigOverrides(configMap map[stri
 	return nil
 }
 
+// validateStringRules applies the validate tag rules supported for string settings.
+func validateStringRules(key, value string, rules []string) error {
+	for _, rule := range rules {
+		trimmedRule := strings.TrimSpace(rule)
+		switch {
+		case trimmedRule == "required":
+			if value == "" {
+				return fmt.Errorf("value for %s is required", key)
+			}
+		case strings.HasPrefix(trimmedRule, "oneof="):
+			if err := validateOneOf(key, value, strings.TrimPrefix(trimmedRule, "oneof=")); err != nil {
+				return err
+			}
+		case trimmedRule == "time_of_day":
+			if _, _, err := utils.ParseTimeOfDay(value); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+		case trimmedRule == "timezone":
+			if _, err := time.LoadLocation(value); err != nil {
+				return fmt.Errorf("invalid value for %s: unknown time zone %q", key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// validateOneOf checks that a string value is one of the space-separated allowed values.
+func validateOneOf(key, value, allowed string) error {
+	options := strings.Fields(allowed)
+	for _, option := range options {
+		if value == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value for %s: %q, must be one of: %s", key, value, strings.Join(options, ", "))
+}
+
 // DisplaySystemConfig displays the current system settings.
 func (sm *SystemSettingsManager) DisplaySystemConfig(settings types.SystemSettings) {
 	logrus.Info("")
@@ -411,6 +439,8 @@ func (sm *SystemSettingsManager) DisplaySystemConfig(settings types.SystemSettin
 	logrus.Infof("    Max Retries: %d", settings.MaxRetries)
 	logrus.Infof("    Blacklist Threshold: %d", settings.BlacklistThreshold)
 	logrus.Infof("    Key Validation Interval: %d minutes", settings.KeyValidationIntervalMinutes)
+	logrus.Infof("    Key Selection Strategy: %s", settings.KeySelectionStrategy)
+	logrus.Infof("    Quota Reset: %s %s", settings.QuotaResetTime, settings.QuotaResetTimezone)
 	logrus.Info("====================================")
 	logrus.Info("")
 }
@@ -201,6 +201,29 @@ func TestSystemSettingsManager_ValidateSettings(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid type for app_url")
 	})
+
+	t.Run("string field outside allowed options", func(t *testing.T) {
+		assert.NoError(t, manager.ValidateSettings(map[string]any{"key_selection_strategy": "lru"}))
+
+		err := manager.ValidateSettings(map[string]any{"key_selection_strategy": "fastest"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be one of")
+
+		err = manager.ValidateGroupConfigOverrides(map[string]any{"key_selection_strategy": "fastest"})
+		assert.Error(t, err)
+	})
+
+	t.Run("quota reset time and time zone", func(t *testing.T) {
+		assert.NoError(t, manager.ValidateSettings(map[string]any{"quota_reset_time": "07:30", "quota_reset_timezone": "America/Los_Angeles"}))
+
+		err := manager.ValidateSettings(map[string]any{"quota_reset_time": "25:00"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "HH:MM")
+
+		err = manager.ValidateGroupConfigOverrides(map[string]any{"quota_reset_timezone": "Mars/Olympus"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown time zone")
+	})
 }
 
 func TestSystemSettingsManager_UpdateSettings(t *testing.T) {
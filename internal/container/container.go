@@ -80,12 +80,16 @@ func BuildContainer() (*dig.Container, error) {
 	if err := container.Provide(services.NewGroupManager); err != nil {
 		return nil, err
 	}
+	if err := container.Provide(services.NewModelRouter); err != nil {
+		return nil, err
+	}
+	if err := container.Provide(services.NewSecretManager); err != nil {
+		return nil, err
+	}
 	if err := container.Provide(services.NewKeyStateService); err != nil {
 		return nil, err
 	}
-	if err := container.Provide(func(db *gorm.DB) *policy.PolicyEngine {
-		return policy.NewPolicyEngine(db)
-	}); err != nil {
+	if err := container.Provide(policy.NewPolicyEngine); err != nil {
 		return nil, err
 	}
 	if err := container.Provide(func(db *gorm.DB) *policy.PolicyService {
@@ -93,6 +97,9 @@ func BuildContainer() (*dig.Container, error) {
 	}); err != nil {
 		return nil, err
 	}
+	if err := container.Provide(policy.NewRateLimiter); err != nil {
+		return nil, err
+	}
 	if err := container.Provide(services.NewKeyPolicyHandler); err != nil {
 		return nil, err
 	}
@@ -123,9 +130,24 @@ func BuildContainer() (*dig.Container, error) {
 	if err := container.Provide(keypool.NewProvider); err != nil {
 		return nil, err
 	}
+	if err := container.Provide(func(provider *keypool.KeyProvider) interfaces.KeyProviderInterface {
+		return provider
+	}); err != nil {
+		return nil, err
+	}
+	if err := container.Provide(func(provider *keypool.KeyProvider) interfaces.KeyStatusUpdater {
+		return provider
+	}); err != nil {
+		return nil, err
+	}
 	if err := container.Provide(validator.NewKeyValidator); err != nil {
 		return nil, err
 	}
+	if err := container.Provide(func(keyValidator *validator.KeyValidator) interfaces.KeyValidatorInterface {
+		return keyValidator
+	}); err != nil {
+		return nil, err
+	}
 	if err := container.Provide(keypool.NewCronChecker); err != nil {
 		return nil, err
 	}
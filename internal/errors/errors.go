@@ -37,6 +37,11 @@ var (
 	ErrNoActiveKeys       = &APIError{HTTPStatus: http.StatusServiceUnavailable, Code: "NO_ACTIVE_KEYS", Message: "No active API keys available for this group"}
 	ErrMaxRetriesExceeded = &APIError{HTTPStatus: http.StatusBadGateway, Code: "MAX_RETRIES_EXCEEDED", Message: "Request failed after maximum retries"}
 	ErrNoKeysAvailable    = &APIError{HTTPStatus: http.StatusServiceUnavailable, Code: "NO_KEYS_AVAILABLE", Message: "No API keys available to process the request"}
+	ErrModelNotAllowed    = &APIError{HTTPStatus: http.StatusForbidden, Code: "MODEL_NOT_ALLOWED", Message: "The requested model is not allowed for this group"}
+	ErrRateLimited        = &APIError{HTTPStatus: http.StatusTooManyRequests, Code: "RATE_LIMITED", Message: "Rate limit exceeded, please retry later"}
+	ErrKeysCoolingDown    = &APIError{HTTPStatus: http.StatusTooManyRequests, Code: "KEYS_COOLING_DOWN", Message: "All API keys of this group are cooling down after upstream rate limits"}
+	ErrKeysSaturated      = &APIError{HTTPStatus: http.StatusTooManyRequests, Code: "KEYS_SATURATED", Message: "All API keys of this group have reached their concurrent request limit"}
+	ErrModelNotRouted     = &APIError{HTTPStatus: http.StatusNotFound, Code: "MODEL_NOT_ROUTED", Message: "No group is configured to serve the requested model"}
 )
 
 // NewAPIError creates a new APIError with a custom message.
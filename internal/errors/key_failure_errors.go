@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Key failure kinds, deciding how a failed request affects the key that served it.
+const (
+	KeyFailureCounted   = "counted"   // 计入连续失败次数，达到拉黑阈值后标记为无效
+	KeyFailureAuth      = "auth"      // 认证或权限失败，Key 已失效
+	KeyFailureTransient = "transient" // 上游临时故障，只退避，不会使 Key 失效
+)
+
+// authFailureSubstrings identify rejected keys of upstreams that report them without a 401 or 403.
+var authFailureSubstrings = []string{
+	"api key not valid",
+	"api_key_invalid",
+	"invalid api key",
+	"incorrect api key",
+	"invalid x-api-key",
+}
+
+// statusCodePattern extracts the status code from validation errors such as "[status 401] ...".
+var statusCodePattern = regexp.MustCompile(`status (\d{3})\b`)
+
+// ClassifyKeyFailure returns the key failure kind of a failed request.
+// When statusCode is 0 it is taken from the error message if present.
+func ClassifyKeyFailure(statusCode int, errorMsg string) string {
+	if statusCode == 0 {
+		if match := statusCodePattern.FindStringSubmatch(errorMsg); match != nil {
+			statusCode, _ = strconv.Atoi(match[1])
+		}
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return KeyFailureAuth
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError:
+		return KeyFailureTransient
+	}
+
+	errorLower := strings.ToLower(errorMsg)
+	for _, pattern := range authFailureSubstrings {
+		if strings.Contains(errorLower, pattern) {
+			return KeyFailureAuth
+		}
+	}
+	return KeyFailureCounted
+}
@@ -0,0 +1,36 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyKeyFailure(t *testing.T) {
+	testCases := []struct {
+		name         string
+		statusCode   int
+		errorMessage string
+		expected     string
+	}{
+		{"unauthorized", 401, "invalid token", KeyFailureAuth},
+		{"forbidden", 403, "permission denied", KeyFailureAuth},
+		{"gemini invalid key", 400, "API key not valid. Please pass a valid API key.", KeyFailureAuth},
+		{"validation unauthorized", 0, "[status 401] Incorrect API key provided", KeyFailureAuth},
+		{"validation without body", 0, "key is invalid (status 403), but failed to read error body: EOF", KeyFailureAuth},
+		{"rate limited", 429, "rate limit exceeded", KeyFailureTransient},
+		{"server error", 500, "internal server error", KeyFailureTransient},
+		{"bad gateway", 502, "bad gateway", KeyFailureTransient},
+		{"timeout", 408, "request timeout", KeyFailureTransient},
+		{"validation server error", 0, "[status 503] overloaded", KeyFailureTransient},
+		{"bad request", 400, "bad request", KeyFailureCounted},
+		{"unknown", 0, "something went wrong", KeyFailureCounted},
+		{"empty", 0, "", KeyFailureCounted},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ClassifyKeyFailure(tc.statusCode, tc.errorMessage))
+		})
+	}
+}
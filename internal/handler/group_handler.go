@@ -15,6 +15,7 @@ import (
 	"gpt-load/internal/utils"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +25,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // isValidChannelType checks if the channel type is valid by checking against the registered channels.
@@ -164,20 +166,152 @@ func (s *Server) validateAndCleanConfig(configMap map[string]any) (map[string]an
 	return finalMap, nil
 }
 
+// validateFallbackGroups checks that the fallback groups exist, are unique and do not include the group itself.
+func (s *Server) validateFallbackGroups(c *gin.Context, groupID uint, fallbackGroups []uint) (datatypes.JSON, bool) {
+	seen := make(map[uint]bool, len(fallbackGroups))
+	for _, id := range fallbackGroups {
+		if id == groupID {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.fallback_group_self")
+			return nil, false
+		}
+		if seen[id] {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.duplicate_fallback_group", map[string]any{"id": id})
+			return nil, false
+		}
+		seen[id] = true
+	}
+
+	if len(fallbackGroups) > 0 {
+		var count int64
+		if err := s.DB.Model(&models.Group{}).Where("id IN ?", fallbackGroups).Count(&count).Error; err != nil {
+			response.Error(c, app_errors.ParseDBError(err))
+			return nil, false
+		}
+		if int(count) != len(fallbackGroups) {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.fallback_group_not_found")
+			return nil, false
+		}
+	}
+
+	if fallbackGroups == nil {
+		fallbackGroups = make([]uint, 0)
+	}
+	fallbackGroupsJSON, err := json.Marshal(fallbackGroups)
+	if err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInternalServer, err.Error()))
+		return nil, false
+	}
+	return fallbackGroupsJSON, true
+}
+
+// validateModelAliases trims the model alias table and rejects empty alias or target model names.
+func validateModelAliases(aliases map[string]string) (datatypes.JSONMap, error) {
+	cleaned := make(datatypes.JSONMap, len(aliases))
+	for alias, target := range aliases {
+		alias = strings.TrimSpace(alias)
+		target = strings.TrimSpace(target)
+		if alias == "" {
+			return nil, fmt.Errorf("alias name cannot be empty")
+		}
+		if target == "" {
+			return nil, fmt.Errorf("target model of alias '%s' cannot be empty", alias)
+		}
+		if _, ok := cleaned[alias]; ok {
+			return nil, fmt.Errorf("duplicate alias '%s'", alias)
+		}
+		cleaned[alias] = target
+	}
+	return cleaned, nil
+}
+
+// normalizeHeaderRules converts header rule keys to canonical form, drops rules without a key and rejects duplicate keys.
+func normalizeHeaderRules(c *gin.Context, rules []models.HeaderRule) (datatypes.JSON, bool) {
+	normalizedHeaderRules := make([]models.HeaderRule, 0)
+	seenKeys := make(map[string]bool)
+
+	for _, rule := range rules {
+		key := strings.TrimSpace(rule.Key)
+		if key == "" {
+			continue
+		}
+
+		// Normalize to canonical form
+		canonicalKey := http.CanonicalHeaderKey(key)
+
+		// Check for duplicate keys
+		if seenKeys[canonicalKey] {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.duplicate_header", map[string]any{"key": canonicalKey})
+			return nil, false
+		}
+		seenKeys[canonicalKey] = true
+
+		// ${HEADER:Authorization} 等会将客户端的代理密钥复制到其他请求头
+		if authHeader, ok := utils.ClientAuthHeaderReference(rule.Value); ok {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.auth_header_reference", map[string]any{"key": canonicalKey, "header": authHeader})
+			return nil, false
+		}
+
+		normalizedHeaderRules = append(normalizedHeaderRules, models.HeaderRule{
+			Key:    canonicalKey,
+			Value:  rule.Value,
+			Action: rule.Action,
+		})
+	}
+
+	headerRulesBytes, err := json.Marshal(normalizedHeaderRules)
+	if err != nil {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrInternalServer, "error.process_header_rules", map[string]any{"error": err.Error()})
+		return nil, false
+	}
+	return headerRulesBytes, true
+}
+
+// validateBodyRules trims the body rules and checks each of them, keeping their order.
+func validateBodyRules(c *gin.Context, rules []models.BodyRule) (datatypes.JSON, bool) {
+	normalizedRules := make([]models.BodyRule, 0, len(rules))
+	for i, rule := range rules {
+		rule.Action = strings.TrimSpace(rule.Action)
+		rule.Path = strings.TrimSpace(rule.Path)
+		for j := range rule.Models {
+			rule.Models[j] = strings.TrimSpace(rule.Models[j])
+		}
+		for j := range rule.Paths {
+			rule.Paths[j] = strings.TrimSpace(rule.Paths[j])
+		}
+		if err := utils.ValidateBodyRule(rule); err != nil {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_body_rule", map[string]any{"index": i + 1, "error": err.Error()})
+			return nil, false
+		}
+		normalizedRules = append(normalizedRules, rule)
+	}
+
+	bodyRulesJSON, err := json.Marshal(normalizedRules)
+	if err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInternalServer, err.Error()))
+		return nil, false
+	}
+	return bodyRulesJSON, true
+}
+
 // GroupCreateRequest defines the payload for creating a group.
 type GroupCreateRequest struct {
-	Name               string              `json:"name"`
-	DisplayName        string              `json:"display_name"`
-	Description        string              `json:"description"`
-	Upstreams          json.RawMessage     `json:"upstreams"`
-	ChannelType        string              `json:"channel_type"`
-	Sort               int                 `json:"sort"`
-	TestModel          string              `json:"test_model"`
-	ValidationEndpoint string              `json:"validation_endpoint"`
-	ParamOverrides     map[string]any      `json:"param_overrides"`
-	Config             map[string]any      `json:"config"`
-	HeaderRules        []models.HeaderRule `json:"header_rules"`
-	ProxyKeys          string              `json:"proxy_keys"`
+	Name                string              `json:"name"`
+	DisplayName         string              `json:"display_name"`
+	Description         string              `json:"description"`
+	Upstreams           json.RawMessage     `json:"upstreams"`
+	ChannelType         string              `json:"channel_type"`
+	Sort                int                 `json:"sort"`
+	TestModel           string              `json:"test_model"`
+	ValidationEndpoint  string              `json:"validation_endpoint"`
+	ParamOverrides      map[string]any      `json:"param_overrides"`
+	ModelAliases        map[string]string   `json:"model_aliases"`
+	Config              map[string]any      `json:"config"`
+	ChannelConfig       map[string]any      `json:"channel_config"`
+	HeaderRules         []models.HeaderRule `json:"header_rules"`
+	ResponseHeaderRules []models.HeaderRule `json:"response_header_rules"`
+	BodyRules           []models.BodyRule   `json:"body_rules"`
+	FallbackGroups      []uint              `json:"fallback_groups"`
+	ProxyKeys           string              `json:"proxy_keys"`
 }
 
 // CreateGroup handles the creation of a new group.
@@ -226,61 +360,55 @@ func (s *Server) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	// Validate and normalize header rules if provided
-	var headerRulesJSON datatypes.JSON
-	if len(req.HeaderRules) > 0 {
-		normalizedHeaderRules := make([]models.HeaderRule, 0)
-		seenKeys := make(map[string]bool)
-
-		for _, rule := range req.HeaderRules {
-			key := strings.TrimSpace(rule.Key)
-			if key == "" {
-				continue
-			}
-
-			// Normalize to canonical form
-			canonicalKey := http.CanonicalHeaderKey(key)
+	if err := channel.ValidateChannelConfig(channelType, req.ChannelConfig); err != nil {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_channel_config", map[string]any{"error": err.Error()})
+		return
+	}
 
-			// Check for duplicate keys
-			if seenKeys[canonicalKey] {
-				response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.duplicate_header", map[string]any{"key": canonicalKey})
-				return
-			}
-			seenKeys[canonicalKey] = true
+	modelAliases, err := validateModelAliases(req.ModelAliases)
+	if err != nil {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_model_aliases", map[string]any{"error": err.Error()})
+		return
+	}
 
-			normalizedHeaderRules = append(normalizedHeaderRules, models.HeaderRule{
-				Key:    canonicalKey,
-				Value:  rule.Value,
-				Action: rule.Action,
-			})
-		}
+	// Validate and normalize header rules if provided
+	headerRulesJSON, ok := normalizeHeaderRules(c, req.HeaderRules)
+	if !ok {
+		return
+	}
+	responseHeaderRulesJSON, ok := normalizeHeaderRules(c, req.ResponseHeaderRules)
+	if !ok {
+		return
+	}
 
-		if len(normalizedHeaderRules) > 0 {
-			headerRulesBytes, err := json.Marshal(normalizedHeaderRules)
-			if err != nil {
-				response.ErrorI18nFromAPIError(c, app_errors.ErrInternalServer, "error.process_header_rules", map[string]any{"error": err.Error()})
-				return
-			}
-			headerRulesJSON = headerRulesBytes
-		}
+	bodyRulesJSON, ok := validateBodyRules(c, req.BodyRules)
+	if !ok {
+		return
 	}
-	if headerRulesJSON == nil {
-		headerRulesJSON = datatypes.JSON("[]")
+
+	fallbackGroupsJSON, ok := s.validateFallbackGroups(c, 0, req.FallbackGroups)
+	if !ok {
+		return
 	}
 
 	group := models.Group{
-		Name:               name,
-		DisplayName:        strings.TrimSpace(req.DisplayName),
-		Description:        strings.TrimSpace(req.Description),
-		Upstreams:          cleanedUpstreams,
-		ChannelType:        channelType,
-		Sort:               req.Sort,
-		TestModel:          testModel,
-		ValidationEndpoint: validationEndpoint,
-		ParamOverrides:     req.ParamOverrides,
-		Config:             cleanedConfig,
-		HeaderRules:        headerRulesJSON,
-		ProxyKeys:          strings.TrimSpace(req.ProxyKeys),
+		Name:                name,
+		DisplayName:         strings.TrimSpace(req.DisplayName),
+		Description:         strings.TrimSpace(req.Description),
+		Upstreams:           cleanedUpstreams,
+		ChannelType:         channelType,
+		Sort:                req.Sort,
+		TestModel:           testModel,
+		ValidationEndpoint:  validationEndpoint,
+		ParamOverrides:      req.ParamOverrides,
+		ModelAliases:        modelAliases,
+		Config:              cleanedConfig,
+		ChannelConfig:       req.ChannelConfig,
+		HeaderRules:         headerRulesJSON,
+		ResponseHeaderRules: responseHeaderRulesJSON,
+		BodyRules:           bodyRulesJSON,
+		FallbackGroups:      fallbackGroupsJSON,
+		ProxyKeys:           strings.TrimSpace(req.ProxyKeys),
 	}
 
 	if err := s.DB.Create(&group).Error; err != nil {
@@ -313,18 +441,23 @@ func (s *Server) ListGroups(c *gin.Context) {
 // GroupUpdateRequest defines the payload for updating a group.
 // Using a dedicated struct avoids issues with zero values being ignored by GORM's Update.
 type GroupUpdateRequest struct {
-	Name               *string             `json:"name,omitempty"`
-	DisplayName        *string             `json:"display_name,omitempty"`
-	Description        *string             `json:"description,omitempty"`
-	Upstreams          json.RawMessage     `json:"upstreams"`
-	ChannelType        *string             `json:"channel_type,omitempty"`
-	Sort               *int                `json:"sort"`
-	TestModel          string              `json:"test_model"`
-	ValidationEndpoint *string             `json:"validation_endpoint,omitempty"`
-	ParamOverrides     map[string]any      `json:"param_overrides"`
-	Config             map[string]any      `json:"config"`
-	HeaderRules        []models.HeaderRule `json:"header_rules"`
-	ProxyKeys          *string             `json:"proxy_keys,omitempty"`
+	Name                *string             `json:"name,omitempty"`
+	DisplayName         *string             `json:"display_name,omitempty"`
+	Description         *string             `json:"description,omitempty"`
+	Upstreams           json.RawMessage     `json:"upstreams"`
+	ChannelType         *string             `json:"channel_type,omitempty"`
+	Sort                *int                `json:"sort"`
+	TestModel           string              `json:"test_model"`
+	ValidationEndpoint  *string             `json:"validation_endpoint,omitempty"`
+	ParamOverrides      map[string]any      `json:"param_overrides"`
+	ModelAliases        map[string]string   `json:"model_aliases"`
+	Config              map[string]any      `json:"config"`
+	ChannelConfig       map[string]any      `json:"channel_config"`
+	HeaderRules         []models.HeaderRule `json:"header_rules"`
+	ResponseHeaderRules []models.HeaderRule `json:"response_header_rules"`
+	BodyRules           []models.BodyRule   `json:"body_rules"`
+	FallbackGroups      []uint              `json:"fallback_groups"`
+	ProxyKeys           *string             `json:"proxy_keys,omitempty"`
 }
 
 // UpdateGroup handles updating an existing group.
@@ -405,6 +538,14 @@ func (s *Server) UpdateGroup(c *gin.Context) {
 	if req.ParamOverrides != nil {
 		group.ParamOverrides = req.ParamOverrides
 	}
+	if req.ModelAliases != nil {
+		modelAliases, err := validateModelAliases(req.ModelAliases)
+		if err != nil {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_model_aliases", map[string]any{"error": err.Error()})
+			return
+		}
+		group.ModelAliases = modelAliases
+	}
 	if req.ValidationEndpoint != nil {
 		validationEndpoint := strings.TrimSpace(*req.ValidationEndpoint)
 		if !isValidValidationEndpoint(validationEndpoint) {
@@ -423,50 +564,52 @@ func (s *Server) UpdateGroup(c *gin.Context) {
 		group.Config = cleanedConfig
 	}
 
+	if req.ChannelConfig != nil {
+		group.ChannelConfig = req.ChannelConfig
+	}
+	// 渠道类型或渠道配置变化时都需要重新校验
+	if req.ChannelType != nil || req.ChannelConfig != nil {
+		if err := channel.ValidateChannelConfig(group.ChannelType, group.ChannelConfig); err != nil {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_channel_config", map[string]any{"error": err.Error()})
+			return
+		}
+	}
+
 	if req.ProxyKeys != nil {
 		group.ProxyKeys = strings.TrimSpace(*req.ProxyKeys)
 	}
 
 	// Handle header rules update
 	if req.HeaderRules != nil {
-		var headerRulesJSON datatypes.JSON
-		normalizedHeaderRules := make([]models.HeaderRule, 0)
-		seenKeys := make(map[string]bool)
-
-		for _, rule := range req.HeaderRules {
-			key := strings.TrimSpace(rule.Key)
-			if key == "" {
-				continue
-			}
-
-			// Normalize to canonical form
-			canonicalKey := http.CanonicalHeaderKey(key)
+		headerRulesJSON, ok := normalizeHeaderRules(c, req.HeaderRules)
+		if !ok {
+			return
+		}
+		group.HeaderRules = headerRulesJSON
+	}
 
-			// Check for duplicate keys
-			if seenKeys[canonicalKey] {
-				response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.duplicate_header", map[string]any{"key": canonicalKey})
-				return
-			}
-			seenKeys[canonicalKey] = true
+	if req.ResponseHeaderRules != nil {
+		responseHeaderRulesJSON, ok := normalizeHeaderRules(c, req.ResponseHeaderRules)
+		if !ok {
+			return
+		}
+		group.ResponseHeaderRules = responseHeaderRulesJSON
+	}
 
-			normalizedHeaderRules = append(normalizedHeaderRules, models.HeaderRule{
-				Key:    canonicalKey,
-				Value:  rule.Value,
-				Action: rule.Action,
-			})
+	if req.BodyRules != nil {
+		bodyRulesJSON, ok := validateBodyRules(c, req.BodyRules)
+		if !ok {
+			return
 		}
+		group.BodyRules = bodyRulesJSON
+	}
 
-		if len(normalizedHeaderRules) > 0 {
-			headerRulesBytes, err := json.Marshal(normalizedHeaderRules)
-			if err != nil {
-				response.ErrorI18nFromAPIError(c, app_errors.ErrInternalServer, "error.process_header_rules", map[string]any{"error": err.Error()})
-				return
-			}
-			headerRulesJSON = headerRulesBytes
-		} else {
-			headerRulesJSON = datatypes.JSON("[]")
+	if req.FallbackGroups != nil {
+		fallbackGroupsJSON, ok := s.validateFallbackGroups(c, group.ID, req.FallbackGroups)
+		if !ok {
+			return
 		}
-		group.HeaderRules = headerRulesJSON
+		group.FallbackGroups = fallbackGroupsJSON
 	}
 
 	// Save the updated group object
@@ -488,23 +631,28 @@ func (s *Server) UpdateGroup(c *gin.Context) {
 
 // GroupResponse defines the structure for a group response, excluding sensitive or large fields.
 type GroupResponse struct {
-	ID                 uint                `json:"id"`
-	Name               string              `json:"name"`
-	Endpoint           string              `json:"endpoint"`
-	DisplayName        string              `json:"display_name"`
-	Description        string              `json:"description"`
-	Upstreams          datatypes.JSON      `json:"upstreams"`
-	ChannelType        string              `json:"channel_type"`
-	Sort               int                 `json:"sort"`
-	TestModel          string              `json:"test_model"`
-	ValidationEndpoint string              `json:"validation_endpoint"`
-	ParamOverrides     datatypes.JSONMap   `json:"param_overrides"`
-	Config             datatypes.JSONMap   `json:"config"`
-	HeaderRules        []models.HeaderRule `json:"header_rules"`
-	ProxyKeys          string              `json:"proxy_keys"`
-	LastValidatedAt    *time.Time          `json:"last_validated_at"`
-	CreatedAt          time.Time           `json:"created_at"`
-	UpdatedAt          time.Time           `json:"updated_at"`
+	ID                  uint                `json:"id"`
+	Name                string              `json:"name"`
+	Endpoint            string              `json:"endpoint"`
+	DisplayName         string              `json:"display_name"`
+	Description         string              `json:"description"`
+	Upstreams           datatypes.JSON      `json:"upstreams"`
+	ChannelType         string              `json:"channel_type"`
+	Sort                int                 `json:"sort"`
+	TestModel           string              `json:"test_model"`
+	ValidationEndpoint  string              `json:"validation_endpoint"`
+	ParamOverrides      datatypes.JSONMap   `json:"param_overrides"`
+	ModelAliases        datatypes.JSONMap   `json:"model_aliases"`
+	Config              datatypes.JSONMap   `json:"config"`
+	ChannelConfig       datatypes.JSONMap   `json:"channel_config"`
+	HeaderRules         []models.HeaderRule `json:"header_rules"`
+	ResponseHeaderRules []models.HeaderRule `json:"response_header_rules"`
+	BodyRules           []models.BodyRule   `json:"body_rules"`
+	FallbackGroups      []uint              `json:"fallback_groups"`
+	ProxyKeys           string              `json:"proxy_keys"`
+	LastValidatedAt     *time.Time          `json:"last_validated_at"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
 }
 
 // newGroupResponse creates a new GroupResponse from a models.Group.
@@ -528,24 +676,53 @@ func (s *Server) newGroupResponse(group *models.Group) *GroupResponse {
 		}
 	}
 
+	responseHeaderRules := make([]models.HeaderRule, 0)
+	if len(group.ResponseHeaderRules) > 0 {
+		if err := json.Unmarshal(group.ResponseHeaderRules, &responseHeaderRules); err != nil {
+			logrus.WithError(err).Error("Failed to unmarshal response header rules")
+			responseHeaderRules = make([]models.HeaderRule, 0)
+		}
+	}
+
+	bodyRules := make([]models.BodyRule, 0)
+	if len(group.BodyRules) > 0 {
+		if err := json.Unmarshal(group.BodyRules, &bodyRules); err != nil {
+			logrus.WithError(err).Error("Failed to unmarshal body rules")
+			bodyRules = make([]models.BodyRule, 0)
+		}
+	}
+
+	fallbackGroups := make([]uint, 0)
+	if len(group.FallbackGroups) > 0 {
+		if err := json.Unmarshal(group.FallbackGroups, &fallbackGroups); err != nil {
+			logrus.WithError(err).Error("Failed to unmarshal fallback groups")
+			fallbackGroups = make([]uint, 0)
+		}
+	}
+
 	return &GroupResponse{
-		ID:                 group.ID,
-		Name:               group.Name,
-		Endpoint:           endpoint,
-		DisplayName:        group.DisplayName,
-		Description:        group.Description,
-		Upstreams:          group.Upstreams,
-		ChannelType:        group.ChannelType,
-		Sort:               group.Sort,
-		TestModel:          group.TestModel,
-		ValidationEndpoint: group.ValidationEndpoint,
-		ParamOverrides:     group.ParamOverrides,
-		Config:             group.Config,
-		HeaderRules:        headerRules,
-		ProxyKeys:          group.ProxyKeys,
-		LastValidatedAt:    group.LastValidatedAt,
-		CreatedAt:          group.CreatedAt,
-		UpdatedAt:          group.UpdatedAt,
+		ID:                  group.ID,
+		Name:                group.Name,
+		Endpoint:            endpoint,
+		DisplayName:         group.DisplayName,
+		Description:         group.Description,
+		Upstreams:           group.Upstreams,
+		ChannelType:         group.ChannelType,
+		Sort:                group.Sort,
+		TestModel:           group.TestModel,
+		ValidationEndpoint:  group.ValidationEndpoint,
+		ParamOverrides:      group.ParamOverrides,
+		ModelAliases:        group.ModelAliases,
+		Config:              group.Config,
+		ChannelConfig:       group.ChannelConfig,
+		HeaderRules:         headerRules,
+		ResponseHeaderRules: responseHeaderRules,
+		BodyRules:           bodyRules,
+		FallbackGroups:      fallbackGroups,
+		ProxyKeys:           group.ProxyKeys,
+		LastValidatedAt:     group.LastValidatedAt,
+		CreatedAt:           group.CreatedAt,
+		UpdatedAt:           group.UpdatedAt,
 	}
 }
 
@@ -597,6 +774,27 @@ func (s *Server) DeleteGroup(c *gin.Context) {
 		return
 	}
 
+	// Delete policy bindings of the group
+	if err := tx.Where("group_id = ?", id).Delete(&models.GroupPolicy{}).Error; err != nil {
+		tx.Rollback()
+		response.Error(c, app_errors.ErrDatabase)
+		return
+	}
+
+	// Delete model routes pointing to the group
+	if err := tx.Where("group_id = ?", id).Delete(&models.ModelRoute{}).Error; err != nil {
+		tx.Rollback()
+		response.Error(c, app_errors.ErrDatabase)
+		return
+	}
+
+	// Remove the group from the fallback chains of other groups
+	if err := removeFallbackGroupReferences(tx, uint(id)); err != nil {
+		tx.Rollback()
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
 	// Then delete the group
 	if err := tx.Delete(&models.Group{}, id).Error; err != nil {
 		tx.Rollback()
@@ -630,15 +828,50 @@ func (s *Server) DeleteGroup(c *gin.Context) {
 	if err := s.GroupManager.Invalidate(); err != nil {
 		logrus.WithContext(c.Request.Context()).WithError(err).Error("failed to invalidate group cache")
 	}
+	if err := s.ModelRouter.Invalidate(); err != nil {
+		logrus.WithContext(c.Request.Context()).WithError(err).Error("failed to invalidate model route cache")
+	}
+	s.invalidatePolicies(c)
 	response.SuccessI18n(c, "success.group_deleted", nil)
 }
 
+// removeFallbackGroupReferences strips a group ID from the fallback_groups of every other group.
+func removeFallbackGroupReferences(tx *gorm.DB, groupID uint) error {
+	var groups []models.Group
+	if err := tx.Select("id", "fallback_groups").Where("id <> ?", groupID).Find(&groups).Error; err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if len(group.FallbackGroups) == 0 {
+			continue
+		}
+		var fallbackGroups []uint
+		if err := json.Unmarshal(group.FallbackGroups, &fallbackGroups); err != nil {
+			return fmt.Errorf("failed to parse fallback groups of group %d: %w", group.ID, err)
+		}
+		remaining := slices.DeleteFunc(slices.Clone(fallbackGroups), func(id uint) bool { return id == groupID })
+		if len(remaining) == len(fallbackGroups) {
+			continue
+		}
+		fallbackGroupsJSON, err := json.Marshal(remaining)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Group{}).Where("id = ?", group.ID).Update("fallback_groups", datatypes.JSON(fallbackGroupsJSON)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ConfigOption represents a single configurable option for a group.
 type ConfigOption struct {
-	Key          string `json:"key"`
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	DefaultValue any    `json:"default_value"`
+	Key          string   `json:"key"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	DefaultValue any      `json:"default_value"`
+	Options      []string `json:"options,omitempty"`
 }
 
 // GetGroupConfigOptions returns a list of available configuration options for groups.
@@ -699,6 +932,7 @@ func (s *Server) GetGroupConfigOptions(c *gin.Context) {
 				Name:         name,
 				Description:  description,
 				DefaultValue: defaultValue,
+				Options:      definition.Options,
 			}
 			options = append(options, option)
 		}
@@ -727,6 +961,8 @@ type GroupStatsResponse struct {
 	HourlyStats RequestStats `json:"hourly_stats"` // 1 hour
 	DailyStats  RequestStats `json:"daily_stats"`  // 24 hours
 	WeeklyStats RequestStats `json:"weekly_stats"` // 7 days
+
+	UpstreamHealth []channel.UpstreamHealth `json:"upstream_health"` // 本实例观察到的上游健康状态
 }
 
 // calculateRequestStats is a helper to compute request statistics.
@@ -875,6 +1111,9 @@ func (s *Server) GetGroupStats(c *gin.Context) {
 
 	wg.Wait()
 
+	// 5. 上游健康状态（被动统计，仅反映本实例）
+	resp.UpstreamHealth = s.ChannelFactory.GetUpstreamHealth(&group)
+
 	if len(errors) > 0 {
 		// 只记录第一个错误，但表明可能存在多个错误
 		logrus.WithContext(c.Request.Context()).WithError(errors[0]).Error("Errors occurred while fetching group stats")
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"time"
 
+	"gpt-load/internal/channel"
 	"gpt-load/internal/config"
 	"gpt-load/internal/encryption"
 	"gpt-load/internal/i18n"
+	"gpt-load/internal/policy"
 	"gpt-load/internal/services"
 	"gpt-load/internal/types"
 
@@ -23,6 +25,8 @@ type Server struct {
 	config                     types.ConfigManager
 	SettingsManager            *config.SystemSettingsManager
 	GroupManager               *services.GroupManager
+	ModelRouter                *services.ModelRouter
+	SecretManager              *services.SecretManager
 	KeyManualValidationService *services.KeyManualValidationService
 	TaskService                *services.TaskService
 	KeyService                 *services.KeyService
@@ -31,6 +35,9 @@ type Server struct {
 	LogService                 *services.LogService
 	CommonHandler              *CommonHandler
 	EncryptionSvc              encryption.Service
+	PolicyService              *policy.PolicyService
+	PolicyEngine               *policy.PolicyEngine
+	ChannelFactory             *channel.Factory
 }
 
 // NewServerParams defines the dependencies for the NewServer constructor.
@@ -40,6 +47,8 @@ type NewServerParams struct {
 	Config                     types.ConfigManager
 	SettingsManager            *config.SystemSettingsManager
 	GroupManager               *services.GroupManager
+	ModelRouter                *services.ModelRouter
+	SecretManager              *services.SecretManager
 	KeyManualValidationService *services.KeyManualValidationService
 	TaskService                *services.TaskService
 	KeyService                 *services.KeyService
@@ -48,6 +57,9 @@ type NewServerParams struct {
 	LogService                 *services.LogService
 	CommonHandler              *CommonHandler
 	EncryptionSvc              encryption.Service
+	PolicyService              *policy.PolicyService
+	PolicyEngine               *policy.PolicyEngine
+	ChannelFactory             *channel.Factory
 }
 
 // NewServer creates a new handler instance with dependencies injected by dig.
@@ -57,6 +69,8 @@ func NewServer(params NewServerParams) *Server {
 		config:                     params.Config,
 		SettingsManager:            params.SettingsManager,
 		GroupManager:               params.GroupManager,
+		ModelRouter:                params.ModelRouter,
+		SecretManager:              params.SecretManager,
 		KeyManualValidationService: params.KeyManualValidationService,
 		TaskService:                params.TaskService,
 		KeyService:                 params.KeyService,
@@ -65,6 +79,9 @@ func NewServer(params NewServerParams) *Server {
 		LogService:                 params.LogService,
 		CommonHandler:              params.CommonHandler,
 		EncryptionSvc:              params.EncryptionSvc,
+		PolicyService:              params.PolicyService,
+		PolicyEngine:               params.PolicyEngine,
+		ChannelFactory:             params.ChannelFactory,
 	}
 }
 
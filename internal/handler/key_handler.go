@@ -75,6 +75,11 @@ type ValidateGroupKeysRequest struct {
 	Status  string `json:"status,omitempty"`
 }
 
+// KeyWeightRequest defines the payload for updating a key's selection weight.
+type KeyWeightRequest struct {
+	Weight int `json:"weight"`
+}
+
 // AddMultipleKeys handles creating new keys from a text block within a specific group.
 func (s *Server) AddMultipleKeys(c *gin.Context) {
 	var req KeyTextRequest
@@ -144,7 +149,9 @@ func (s *Server) ListKeysInGroup(c *gin.Context) {
 	}
 
 	statusFilter := c.Query("status")
-	if statusFilter != "" && statusFilter != models.KeyStatusActive && statusFilter != models.KeyStatusInvalid {
+	switch statusFilter {
+	case "", models.KeyStatusActive, models.KeyStatusDegraded, models.KeyStatusDisabled, models.KeyStatusInvalid:
+	default:
 		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_status_filter")
 		return
 	}
@@ -267,6 +274,33 @@ func (s *Server) RestoreMultipleKeys(c *gin.Context) {
 	response.Success(c, result)
 }
 
+// UpdateKeyWeight handles updating the weight of a single key.
+func (s *Server) UpdateKeyWeight(c *gin.Context) {
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || keyID <= 0 {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrBadRequest, "validation.invalid_key_id")
+		return
+	}
+
+	var req KeyWeightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	if req.Weight < 1 {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_key_weight")
+		return
+	}
+
+	if err := s.KeyService.UpdateKeyWeight(uint(keyID), req.Weight); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
+	response.Success(c, req)
+}
+
 // TestMultipleKeys handles a one-off validation test for multiple keys.
 func (s *Server) TestMultipleKeys(c *gin.Context) {
 	var req KeyTextRequest
@@ -420,7 +454,7 @@ func (s *Server) ExportKeys(c *gin.Context) {
 	}
 
 	switch statusFilter {
-	case "all", models.KeyStatusActive, models.KeyStatusInvalid:
+	case "all", models.KeyStatusActive, models.KeyStatusDegraded, models.KeyStatusDisabled, models.KeyStatusInvalid:
 	default:
 		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_status_filter")
 		return
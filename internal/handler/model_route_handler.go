@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/response"
+	"gpt-load/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ModelRouteCreateRequest defines the payload for creating a model route.
+type ModelRouteCreateRequest struct {
+	Model    string `json:"model"`
+	GroupID  uint   `json:"group_id"`
+	Priority int    `json:"priority"`
+	IsActive *bool  `json:"is_active"`
+}
+
+// ModelRouteUpdateRequest defines the payload for updating a model route.
+type ModelRouteUpdateRequest struct {
+	Model    *string `json:"model,omitempty"`
+	GroupID  *uint   `json:"group_id,omitempty"`
+	Priority *int    `json:"priority,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// validateModelRoute checks the route model pattern and target group, writing an error response on failure.
+func (s *Server) validateModelRoute(c *gin.Context, route *models.ModelRoute) bool {
+	if route.Model == "" {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.model_route_model_required")
+		return false
+	}
+	if err := models.ValidateModelRoutePattern(route.Model); err != nil {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_model_route_pattern", map[string]any{"error": err.Error()})
+		return false
+	}
+
+	var group models.Group
+	if err := s.DB.Select("id").First(&group, route.GroupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.model_route_group_not_found")
+		} else {
+			response.Error(c, app_errors.ParseDBError(err))
+		}
+		return false
+	}
+	return true
+}
+
+// findModelRouteByIDParam loads the model route referenced by the "id" route parameter.
+func (s *Server) findModelRouteByIDParam(c *gin.Context) (*models.ModelRoute, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrBadRequest, "validation.invalid_model_route_id")
+		return nil, false
+	}
+
+	var route models.ModelRoute
+	if err := s.DB.First(&route, id).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return nil, false
+	}
+	return &route, true
+}
+
+// invalidateModelRoutes reloads the routing table on all instances.
+func (s *Server) invalidateModelRoutes(c *gin.Context) {
+	if err := s.ModelRouter.Invalidate(); err != nil {
+		logrus.WithContext(c.Request.Context()).WithError(err).Error("failed to invalidate model route cache")
+	}
+}
+
+// ListModelRoutes handles listing model routes in resolution order.
+// When the "model" query parameter is set, only the active routes serving that model are returned.
+func (s *Server) ListModelRoutes(c *gin.Context) {
+	var routes []models.ModelRoute
+	query := s.DB
+	if groupID := c.Query("group_id"); groupID != "" {
+		id, err := strconv.Atoi(groupID)
+		if err != nil {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrBadRequest, "validation.invalid_group_id_format")
+			return
+		}
+		query = query.Where("group_id = ?", id)
+	}
+	if err := query.Find(&routes).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	services.SortModelRoutes(routes)
+
+	if model := strings.TrimSpace(c.Query("model")); model != "" {
+		matched := make([]models.ModelRoute, 0, len(routes))
+		for _, route := range routes {
+			if route.IsActive && route.Matches(model) {
+				matched = append(matched, route)
+			}
+		}
+		routes = matched
+	}
+	response.Success(c, routes)
+}
+
+// CreateModelRoute handles the creation of a new model route.
+func (s *Server) CreateModelRoute(c *gin.Context) {
+	var req ModelRouteCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	route := models.ModelRoute{
+		Model:    strings.TrimSpace(req.Model),
+		GroupID:  req.GroupID,
+		Priority: req.Priority,
+		IsActive: true,
+	}
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	if !s.validateModelRoute(c, &route) {
+		return
+	}
+
+	if err := s.DB.Create(&route).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidateModelRoutes(c)
+	response.Success(c, route)
+}
+
+// UpdateModelRoute handles updating an existing model route.
+func (s *Server) UpdateModelRoute(c *gin.Context) {
+	route, ok := s.findModelRouteByIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req ModelRouteUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	if req.Model != nil {
+		route.Model = strings.TrimSpace(*req.Model)
+	}
+	if req.GroupID != nil {
+		route.GroupID = *req.GroupID
+	}
+	if req.Priority != nil {
+		route.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		route.IsActive = *req.IsActive
+	}
+
+	if !s.validateModelRoute(c, route) {
+		return
+	}
+
+	if err := s.DB.Save(route).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidateModelRoutes(c)
+	response.Success(c, route)
+}
+
+// DeleteModelRoute handles deleting a model route.
+func (s *Server) DeleteModelRoute(c *gin.Context) {
+	route, ok := s.findModelRouteByIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.DB.Delete(&models.ModelRoute{}, route.ID).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidateModelRoutes(c)
+	response.SuccessI18n(c, "success.model_route_deleted", nil)
+}
@@ -0,0 +1,490 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/policy"
+	"gpt-load/internal/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
+)
+
+// PolicyCreateRequest defines the payload for creating a policy.
+type PolicyCreateRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Type        string          `json:"type"`
+	Config      json.RawMessage `json:"config"`
+	Priority    int             `json:"priority"`
+	IsActive    *bool           `json:"is_active"`
+}
+
+// PolicyUpdateRequest defines the payload for updating a policy.
+type PolicyUpdateRequest struct {
+	Name        *string         `json:"name,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	Type        *string         `json:"type,omitempty"`
+	Config      json.RawMessage `json:"config"`
+	Priority    *int            `json:"priority,omitempty"`
+	IsActive    *bool           `json:"is_active,omitempty"`
+}
+
+// GroupPolicyBindRequest defines the payload for binding a policy to a group.
+type GroupPolicyBindRequest struct {
+	PolicyID uint `json:"policy_id"`
+	Priority int  `json:"priority"`
+}
+
+// GroupPolicyUpdateRequest defines the payload for updating a group-policy binding.
+type GroupPolicyUpdateRequest struct {
+	Priority *int  `json:"priority,omitempty"`
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// PolicySimulationRequest defines the payload for a policy dry run.
+type PolicySimulationRequest struct {
+	Context          models.PolicyEvaluationContext `json:"context"`
+	IncludeInactive  bool                           `json:"include_inactive"`
+	ReplayFailedLogs int                            `json:"replay_failed_logs"`
+}
+
+// PolicyReplayResult holds the simulated policy outcome for a single failed request log.
+type PolicyReplayResult struct {
+	LogID         string                           `json:"log_id"`
+	Timestamp     time.Time                        `json:"timestamp"`
+	StatusCode    int                              `json:"status_code"`
+	Model         string                           `json:"model"`
+	UpstreamModel string                           `json:"upstream_model"`
+	ErrorMessage  string                           `json:"error_message"`
+	Results       []*models.PolicyEvaluationResult `json:"results"`
+}
+
+// PolicySimulationResponse defines the result of a policy dry run.
+type PolicySimulationResponse struct {
+	ModelAllowed *bool                            `json:"model_allowed,omitempty"`
+	Results      []*models.PolicyEvaluationResult `json:"results"`
+	Replays      []PolicyReplayResult             `json:"replays,omitempty"`
+}
+
+// maxPolicyReplayLogs caps the number of failed request logs replayed in one simulation.
+const maxPolicyReplayLogs = 100
+
+// validatePolicy checks the policy name, type and type-specific config, writing an error response on failure.
+func validatePolicy(c *gin.Context, p *models.Policy) bool {
+	if p.Name == "" {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.policy_name_required")
+		return false
+	}
+	if !policy.IsValidPolicyType(p.Type) {
+		supported := strings.Join(policy.PolicyTypes(), ", ")
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_policy_type", map[string]any{"types": supported})
+		return false
+	}
+	if err := policy.ValidatePolicyConfig(p); err != nil {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_policy_config", map[string]any{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// parsePolicyID parses the policy ID from the given route parameter.
+func parsePolicyID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil || id <= 0 {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrBadRequest, "validation.invalid_policy_id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// invalidatePolicies reloads the cached group policies on all instances.
+func (s *Server) invalidatePolicies(c *gin.Context) {
+	if err := s.PolicyEngine.Invalidate(); err != nil {
+		logrus.WithContext(c.Request.Context()).WithError(err).Error("failed to invalidate policy cache")
+	}
+}
+
+// ListPolicies handles listing all policies, optionally filtered by type.
+func (s *Server) ListPolicies(c *gin.Context) {
+	policyType := c.Query("type")
+	if policyType != "" && !policy.IsValidPolicyType(policyType) {
+		supported := strings.Join(policy.PolicyTypes(), ", ")
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_policy_type", map[string]any{"types": supported})
+		return
+	}
+
+	policies, err := s.PolicyService.ListPolicies(policyType)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, policies)
+}
+
+// GetPolicy handles fetching a single policy.
+func (s *Server) GetPolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c, "id")
+	if !ok {
+		return
+	}
+
+	p, err := s.PolicyService.GetPolicyByID(id)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, p)
+}
+
+// CreatePolicy handles the creation of a new policy.
+func (s *Server) CreatePolicy(c *gin.Context) {
+	var req PolicyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	p := models.Policy{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Type:        strings.TrimSpace(req.Type),
+		Config:      datatypes.JSON(req.Config),
+		Priority:    req.Priority,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		p.IsActive = *req.IsActive
+	}
+
+	if !validatePolicy(c, &p) {
+		return
+	}
+
+	if err := s.PolicyService.CreatePolicy(&p); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidatePolicies(c)
+	response.Success(c, p)
+}
+
+// UpdatePolicy handles updating an existing policy.
+func (s *Server) UpdatePolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c, "id")
+	if !ok {
+		return
+	}
+
+	p, err := s.PolicyService.GetPolicyByID(id)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
+	var req PolicyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	if req.Name != nil {
+		p.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		p.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Type != nil {
+		p.Type = strings.TrimSpace(*req.Type)
+	}
+	if req.Config != nil {
+		p.Config = datatypes.JSON(req.Config)
+	}
+	if req.Priority != nil {
+		p.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		p.IsActive = *req.IsActive
+	}
+
+	if !validatePolicy(c, p) {
+		return
+	}
+
+	if err := s.PolicyService.UpdatePolicy(p); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidatePolicies(c)
+	response.Success(c, p)
+}
+
+// DeletePolicy handles deleting a policy together with its group bindings.
+func (s *Server) DeletePolicy(c *gin.Context) {
+	id, ok := parsePolicyID(c, "id")
+	if !ok {
+		return
+	}
+
+	if _, err := s.PolicyService.GetPolicyByID(id); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
+	if err := s.PolicyService.DeletePolicy(id); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidatePolicies(c)
+	response.SuccessI18n(c, "success.policy_deleted", nil)
+}
+
+// CreateDefaultPolicies handles creating the built-in default policies if they do not exist.
+func (s *Server) CreateDefaultPolicies(c *gin.Context) {
+	if err := s.PolicyService.CreateDefaultPolicies(); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidatePolicies(c)
+
+	policies, err := s.PolicyService.ListPolicies("")
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, policies)
+}
+
+// ListGroupPolicies handles listing the policies bound to a group.
+func (s *Server) ListGroupPolicies(c *gin.Context) {
+	group, ok := s.findGroupByIDParam(c)
+	if !ok {
+		return
+	}
+
+	groupPolicies, err := s.PolicyService.ListGroupPolicies(group.ID)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, groupPolicies)
+}
+
+// AddGroupPolicy handles binding a policy to a group.
+func (s *Server) AddGroupPolicy(c *gin.Context) {
+	group, ok := s.findGroupByIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req GroupPolicyBindRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	if req.PolicyID == 0 {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_policy_id")
+		return
+	}
+
+	if _, err := s.PolicyService.GetPolicyByID(req.PolicyID); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
+	if err := s.PolicyService.AddPolicyToGroup(group.ID, req.PolicyID, req.Priority); err != nil {
+		if apiErr := app_errors.ParseDBError(err); apiErr == app_errors.ErrDuplicateResource {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrDuplicateResource, "validation.policy_already_bound")
+		} else {
+			response.Error(c, apiErr)
+		}
+		return
+	}
+	s.invalidatePolicies(c)
+
+	groupPolicies, err := s.PolicyService.ListGroupPolicies(group.ID)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, groupPolicies)
+}
+
+// UpdateGroupPolicy handles updating the priority or active flag of a group-policy binding.
+func (s *Server) UpdateGroupPolicy(c *gin.Context) {
+	group, ok := s.findGroupByIDParam(c)
+	if !ok {
+		return
+	}
+
+	policyID, ok := parsePolicyID(c, "policyId")
+	if !ok {
+		return
+	}
+
+	var req GroupPolicyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	var binding models.GroupPolicy
+	if err := s.DB.Where("group_id = ? AND policy_id = ?", group.ID, policyID).First(&binding).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
+	priority := binding.Priority
+	if req.Priority != nil {
+		priority = *req.Priority
+	}
+	isActive := binding.IsActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	if err := s.PolicyService.UpdateGroupPolicy(group.ID, policyID, priority, isActive); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidatePolicies(c)
+
+	groupPolicies, err := s.PolicyService.ListGroupPolicies(group.ID)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, groupPolicies)
+}
+
+// RemoveGroupPolicy handles unbinding a policy from a group.
+func (s *Server) RemoveGroupPolicy(c *gin.Context) {
+	group, ok := s.findGroupByIDParam(c)
+	if !ok {
+		return
+	}
+
+	policyID, ok := parsePolicyID(c, "policyId")
+	if !ok {
+		return
+	}
+
+	if err := s.PolicyService.RemovePolicyFromGroup(group.ID, policyID); err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidatePolicies(c)
+	response.SuccessI18n(c, "success.policy_unbound", nil)
+}
+
+// findGroupByIDParam loads the group referenced by the ":id" route parameter.
+func (s *Server) findGroupByIDParam(c *gin.Context) (*models.Group, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrBadRequest, "validation.invalid_group_id")
+		return nil, false
+	}
+
+	var group models.Group
+	if err := s.DB.First(&group, id).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return nil, false
+	}
+	return &group, true
+}
+
+// SimulateGroupPolicies evaluates the group's policies against a hypothetical context without side effects,
+// optionally replaying the group's most recent failed request logs.
+func (s *Server) SimulateGroupPolicies(c *gin.Context) {
+	group, ok := s.findGroupByIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req PolicySimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	if req.ReplayFailedLogs < 0 || req.ReplayFailedLogs > maxPolicyReplayLogs {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_replay_count", map[string]any{"max": maxPolicyReplayLogs})
+		return
+	}
+
+	policyCtx := req.Context
+	policyCtx.GroupID = group.ID
+
+	results, err := s.PolicyEngine.SimulatePolicies(group.ID, &policyCtx, req.IncludeInactive)
+	if err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+
+	resp := PolicySimulationResponse{Results: results}
+
+	if policyCtx.Model != "" {
+		allowed, err := s.PolicyEngine.EvaluateModelFilterPolicies(group.ID, policyCtx.Model)
+		if err != nil {
+			response.Error(c, app_errors.ParseDBError(err))
+			return
+		}
+		resp.ModelAllowed = &allowed
+	}
+
+	if req.ReplayFailedLogs > 0 {
+		var logs []models.RequestLog
+		if err := s.DB.Where("group_id = ? AND is_success = ?", group.ID, false).
+			Order("timestamp desc").
+			Limit(req.ReplayFailedLogs).
+			Find(&logs).Error; err != nil {
+			response.Error(c, app_errors.ParseDBError(err))
+			return
+		}
+
+		for _, log := range logs {
+			// Policies saw the model after alias rewriting; logs written before it was recorded only have the client model.
+			upstreamModel := log.UpstreamModel
+			if upstreamModel == "" {
+				upstreamModel = log.Model
+			}
+			logCtx := &models.PolicyEvaluationContext{
+				GroupID:      group.ID,
+				Model:        upstreamModel,
+				StatusCode:   log.StatusCode,
+				ErrorMessage: log.ErrorMessage,
+			}
+
+			var key models.APIKey
+			if log.KeyHash != "" && s.DB.Select("id", "failure_count").Where("group_id = ? AND key_hash = ?", group.ID, log.KeyHash).Limit(1).Find(&key).Error == nil {
+				logCtx.KeyID = key.ID
+				logCtx.FailureCount = key.FailureCount
+			}
+
+			logResults, err := s.PolicyEngine.SimulatePolicies(group.ID, logCtx, req.IncludeInactive)
+			if err != nil {
+				response.Error(c, app_errors.ParseDBError(err))
+				return
+			}
+
+			resp.Replays = append(resp.Replays, PolicyReplayResult{
+				LogID:         log.ID,
+				Timestamp:     log.Timestamp,
+				StatusCode:    log.StatusCode,
+				Model:         log.Model,
+				UpstreamModel: upstreamModel,
+				ErrorMessage:  log.ErrorMessage,
+				Results:       logResults,
+			})
+		}
+	}
+
+	response.Success(c, resp)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// secretNamePattern restricts secret names to what ${SECRET:name} can reference.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,100}$`)
+
+// SecretCreateRequest defines the payload for creating a secret.
+type SecretCreateRequest struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+// SecretUpdateRequest defines the payload for updating a secret. The value is kept when omitted.
+type SecretUpdateRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Value       *string `json:"value,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// findSecretByIDParam loads the secret referenced by the "id" route parameter.
+func (s *Server) findSecretByIDParam(c *gin.Context) (*models.Secret, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrBadRequest, "validation.invalid_secret_id")
+		return nil, false
+	}
+
+	var secret models.Secret
+	if err := s.DB.First(&secret, id).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return nil, false
+	}
+	return &secret, true
+}
+
+// setSecretValue validates and encrypts a secret value, writing an error response on failure.
+func (s *Server) setSecretValue(c *gin.Context, secret *models.Secret, value string) bool {
+	if value == "" {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.secret_value_required")
+		return false
+	}
+	encrypted, err := s.EncryptionSvc.Encrypt(value)
+	if err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInternalServer, err.Error()))
+		return false
+	}
+	secret.Value = encrypted
+	return true
+}
+
+// invalidateSecrets reloads the secrets on all instances.
+func (s *Server) invalidateSecrets(c *gin.Context) {
+	if err := s.SecretManager.Invalidate(); err != nil {
+		logrus.WithContext(c.Request.Context()).WithError(err).Error("failed to invalidate secret cache")
+	}
+}
+
+// ListSecrets handles listing secrets. Secret values are never returned.
+func (s *Server) ListSecrets(c *gin.Context) {
+	var secrets []models.Secret
+	if err := s.DB.Order("name asc").Find(&secrets).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	response.Success(c, secrets)
+}
+
+// CreateSecret handles the creation of a new secret.
+func (s *Server) CreateSecret(c *gin.Context) {
+	var req SecretCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	secret := models.Secret{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if !secretNamePattern.MatchString(secret.Name) {
+		response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_secret_name")
+		return
+	}
+	if !s.setSecretValue(c, &secret, req.Value) {
+		return
+	}
+
+	if err := s.DB.Create(&secret).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidateSecrets(c)
+	response.Success(c, secret)
+}
+
+// UpdateSecret handles updating an existing secret.
+func (s *Server) UpdateSecret(c *gin.Context) {
+	secret, ok := s.findSecretByIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req SecretUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrInvalidJSON, err.Error()))
+		return
+	}
+
+	if req.Name != nil {
+		secret.Name = strings.TrimSpace(*req.Name)
+		if !secretNamePattern.MatchString(secret.Name) {
+			response.ErrorI18nFromAPIError(c, app_errors.ErrValidation, "validation.invalid_secret_name")
+			return
+		}
+	}
+	if req.Value != nil && !s.setSecretValue(c, secret, *req.Value) {
+		return
+	}
+	if req.Description != nil {
+		secret.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if err := s.DB.Save(secret).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidateSecrets(c)
+	response.Success(c, secret)
+}
+
+// DeleteSecret handles deleting a secret.
+func (s *Server) DeleteSecret(c *gin.Context) {
+	secret, ok := s.findSecretByIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := s.DB.Delete(&models.Secret{}, secret.ID).Error; err != nil {
+		response.Error(c, app_errors.ParseDBError(err))
+		return
+	}
+	s.invalidateSecrets(c)
+	response.SuccessI18n(c, "success.secret_deleted", nil)
+}
@@ -16,6 +16,7 @@ type KeyProviderInterface interface {
 	RemoveAllKeys(groupID uint) (int64, error)
 	RemoveKeys(groupID uint, keyValues []string) (int64, error)
 	RemoveKeysFromStore(groupID uint, keyIDs []uint) error
+	UpdateKeyWeight(keyID uint, weight int) error
 }
 
 // KeyValidationResult 定义了单个密钥验证的结果。
@@ -31,11 +32,16 @@ type KeyStatusUpdater interface {
 
 // KeyValidatorInterface 定义密钥验证器接口
 type KeyValidatorInterface interface {
-	ValidateGroup(group *models.Group) (map[uint]interface{}, error)
 	ValidateSingleKey(key *models.APIKey, group *models.Group) (bool, error)
 	TestMultipleKeys(group *models.Group, keyValues []string) ([]interface{}, error)
 }
 
+// KeylessGroupChecker 定义无密钥分组的上游检查接口
+type KeylessGroupChecker interface {
+	// CheckKeylessGroup 检查无密钥分组的上游，分组不是无密钥分组时返回 false
+	CheckKeylessGroup(group *models.Group) bool
+}
+
 // PolicyEngineInterface 定义策略引擎接口
 type PolicyEngineInterface interface {
 	EvaluateRetryPolicies(groupID uint, context *models.PolicyEvaluationContext) (*models.PolicyEvaluationResult, error)
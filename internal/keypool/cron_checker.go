@@ -20,6 +20,7 @@ type CronChecker struct {
 	SettingsManager *config.SystemSettingsManager
 	Validator       interfaces.KeyValidatorInterface
 	EncryptionSvc   encryption.Service
+	KeyProvider     *KeyProvider
 	stopChan        chan struct{}
 	wg              sync.WaitGroup
 }
@@ -30,12 +31,14 @@ func NewCronChecker(
 	settingsManager *config.SystemSettingsManager,
 	validator interfaces.KeyValidatorInterface,
 	encryptionSvc encryption.Service,
+	keyProvider *KeyProvider,
 ) *CronChecker {
 	return &CronChecker{
 		DB:              db,
 		SettingsManager: settingsManager,
 		Validator:       validator,
 		EncryptionSvc:   encryptionSvc,
+		KeyProvider:     keyProvider,
 		stopChan:        make(chan struct{}),
 	}
 }
@@ -74,17 +77,37 @@ func (s *CronChecker) runLoop() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
+	restoreTicker := time.NewTicker(time.Minute)
+	defer restoreTicker.Stop()
+
 	for {
 		select {
 		case <-ticker.C:
 			logrus.Debug("CronChecker: Running as Master, submitting validation jobs.")
 			s.submitValidationJobs()
+		case <-restoreTicker.C:
+			s.restoreDisabledKeys()
 		case <-s.stopChan:
 			return
 		}
 	}
 }
 
+// restoreDisabledKeys returns keys whose disable period has expired to the active pool.
+func (s *CronChecker) restoreDisabledKeys() {
+	if s.KeyProvider == nil {
+		return
+	}
+	restored, err := s.KeyProvider.RestoreDisabledKeys()
+	if err != nil {
+		logrus.Errorf("CronChecker: Failed to restore disabled keys: %v", err)
+		return
+	}
+	if restored > 0 {
+		logrus.Infof("CronChecker: Restored %d disabled keys to the active pool.", restored)
+	}
+}
+
 // submitValidationJobs finds groups whose keys need validation and validates them concurrently.
 func (s *CronChecker) submitValidationJobs() {
 	var groups []models.Group
@@ -118,6 +141,15 @@ func (s *CronChecker) submitValidationJobs() {
 func (s *CronChecker) validateGroupKeys(group *models.Group) {
 	groupProcessStart := time.Now()
 
+	// Keyless groups have no keys to validate, their upstreams are probed instead.
+	if checker, ok := s.Validator.(interfaces.KeylessGroupChecker); ok && checker.CheckKeylessGroup(group) {
+		if err := s.DB.Model(group).Update("last_validated_at", time.Now()).Error; err != nil {
+			logrus.Errorf("CronChecker: Failed to update last_validated_at for group %s: %v", group.Name, err)
+		}
+		logrus.Infof("CronChecker: Checked upstreams of keyless group '%s'.", group.Name)
+		return
+	}
+
 	var invalidKeys []models.APIKey
 	err := s.DB.Where("group_id = ? AND status = ?", group.ID, models.KeyStatusInvalid).Find(&invalidKeys).Error
 	if err != nil {
@@ -36,13 +36,23 @@ func (m *MockKeyValidator) TestMultipleKeys(group *models.Group, keyValues []str
 	return args.Get(0).([]interface{}), args.Error(1)
 }
 
+// MockKeylessValidator 模拟同时实现 KeylessGroupChecker 的验证器
+type MockKeylessValidator struct {
+	MockKeyValidator
+}
+
+func (m *MockKeylessValidator) CheckKeylessGroup(group *models.Group) bool {
+	args := m.Called(group)
+	return args.Bool(0)
+}
+
 func TestNewCronChecker(t *testing.T) {
 	db := tests.SetupTestDB(t)
 	settingsManager := &config.SystemSettingsManager{}
 	validator := &MockKeyValidator{}
 	encryptionSvc, _ := encryption.NewService("test-password")
 
-	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc)
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
 
 	assert.NotNil(t, checker)
 	assert.Equal(t, db, checker.DB)
@@ -58,7 +68,7 @@ func TestCronChecker_StartStop(t *testing.T) {
 	validator := &MockKeyValidator{}
 	encryptionSvc, _ := encryption.NewService("test-password")
 
-	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc)
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
 
 	// Start the checker
 	checker.Start()
@@ -84,7 +94,7 @@ func TestCronChecker_submitValidationJobs(t *testing.T) {
 	validator := &MockKeyValidator{}
 	encryptionSvc, _ := encryption.NewService("test-password")
 
-	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc)
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
 
 	t.Run("no groups", func(t *testing.T) {
 		// No groups in database, should not panic
@@ -158,7 +168,7 @@ func TestCronChecker_validateGroupKeys(t *testing.T) {
 	validator := &MockKeyValidator{}
 	encryptionSvc, _ := encryption.NewService("test-password")
 
-	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc)
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
 
 	t.Run("no invalid keys", func(t *testing.T) {
 		group := &models.Group{
@@ -267,13 +277,53 @@ func TestCronChecker_validateGroupKeys(t *testing.T) {
 	})
 }
 
+func TestCronChecker_validateGroupKeys_Keyless(t *testing.T) {
+	db := tests.SetupTestDB(t)
+	settingsManager := config.NewSystemSettingsManager()
+	validator := &MockKeylessValidator{}
+	encryptionSvc, _ := encryption.NewService("test-password")
+
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
+
+	group := &models.Group{
+		ID:          1,
+		Name:        "local-group",
+		ChannelType: "local",
+		Upstreams:   datatypes.JSON(`["http://localhost:11434"]`),
+		EffectiveConfig: types.SystemSettings{
+			KeyValidationConcurrency: 1,
+		},
+	}
+	db.Create(&group)
+
+	// Invalid keys of a keyless group are not validated
+	encryptedKey, _ := encryptionSvc.Encrypt("unused-key")
+	invalidKey := models.APIKey{
+		KeyValue: encryptedKey,
+		Status:   models.KeyStatusInvalid,
+		GroupID:  group.ID,
+	}
+	db.Create(&invalidKey)
+
+	validator.On("CheckKeylessGroup", group).Return(true).Once()
+
+	checker.validateGroupKeys(group)
+
+	validator.AssertExpectations(t)
+	validator.AssertNotCalled(t, "ValidateSingleKey", mock.Anything, mock.Anything)
+
+	var updatedGroup models.Group
+	db.First(&updatedGroup, group.ID)
+	assert.NotNil(t, updatedGroup.LastValidatedAt)
+}
+
 func TestCronChecker_StopWithTimeout(t *testing.T) {
 	db := tests.SetupTestDB(t)
 	settingsManager := &config.SystemSettingsManager{}
 	validator := &MockKeyValidator{}
 	encryptionSvc, _ := encryption.NewService("test-password")
 
-	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc)
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
 
 	// Start the checker
 	checker.Start()
@@ -298,7 +348,7 @@ func TestCronChecker_Integration(t *testing.T) {
 	validator := &MockKeyValidator{}
 	encryptionSvc, _ := encryption.NewService("test-password")
 
-	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc)
+	checker := NewCronChecker(db, settingsManager, validator, encryptionSvc, nil)
 
 	// Create a group that needs validation
 	group := models.Group{
@@ -23,6 +23,7 @@ type KeyProvider struct {
 	store           store.Store
 	settingsManager *config.SystemSettingsManager
 	encryptionSvc   encryption.Service
+	stateMachine    *models.KeyStateMachine
 }
 
 // NewProvider 创建一个新的 KeyProvider 实例。
@@ -32,36 +33,55 @@ func NewProvider(db *gorm.DB, store store.Store, settingsManager *config.SystemS
 		store:           store,
 		settingsManager: settingsManager,
 		encryptionSvc:   encryptionSvc,
+		stateMachine:    models.NewKeyStateMachine(),
 	}
 }
 
 // SelectKey 为指定的分组原子性地选择并轮换一个可用的 APIKey。
 func (p *KeyProvider) SelectKey(groupID uint) (*models.APIKey, error) {
+	return p.SelectKeyWithStrategy(groupID, SelectionRoundRobin)
+}
+
+// SelectKeyWithStrategy 按指定的选择策略从活跃列表中选择一个可用的 APIKey，不限制并发。
+func (p *KeyProvider) SelectKeyWithStrategy(groupID uint, strategy string) (*models.APIKey, error) {
+	return p.selectKey(groupID, strategy, 0)
+}
+
+// SelectKeyForGroup 按分组的有效配置（选择策略、单 Key 并发上限）选择一个可用的 APIKey。
+// 配置了并发上限时会占用该 Key 的一个并发槽位，调用方需在请求结束后调用 ReleaseKeySlot 释放。
+func (p *KeyProvider) SelectKeyForGroup(group *models.Group) (*models.APIKey, error) {
+	cfg := group.EffectiveConfig
+	return p.selectKey(group.ID, cfg.KeySelectionStrategy, cfg.MaxConcurrentPerKey)
+}
+
+// ReleaseKeySlot 释放 SelectKeyForGroup 占用的并发槽位。
+func (p *KeyProvider) ReleaseKeySlot(apiKey *models.APIKey) {
+	if err := p.store.ReleaseSlot(keySlotKey(strconv.FormatUint(uint64(apiKey.ID), 10))); err != nil {
+		logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "error": err}).Error("Failed to release key in-flight slot")
+	}
+}
+
+func (p *KeyProvider) selectKey(groupID uint, strategy string, maxConcurrent int) (*models.APIKey, error) {
 	activeKeysListKey := fmt.Sprintf("group:%d:active_keys", groupID)
 
-	// 1. Atomically rotate the key ID from the list
-	keyIDStr, err := p.store.Rotate(activeKeysListKey)
+	// 1. Pick a key from the list according to the strategy, skipping keys that are cooling down or saturated
+	keyIDStr, keyDetails, err := p.pickKey(activeKeysListKey, strategy, maxConcurrent)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			return nil, app_errors.ErrNoActiveKeys
 		}
-		return nil, fmt.Errorf("failed to rotate key from store: %w", err)
+		return nil, err
 	}
 
+	// 2. Parse the key ID picked from the list
 	keyID, err := strconv.ParseUint(keyIDStr, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse key ID '%s': %w", keyIDStr, err)
 	}
 
-	// 2. Get key details from HASH
-	keyHashKey := fmt.Sprintf("key:%d", keyID)
-	keyDetails, err := p.store.HGetAll(keyHashKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get key details for key ID %d: %w", keyID, err)
-	}
-
 	// 3. Manually unmarshal the map into an APIKey struct
 	failureCount, _ := strconv.ParseInt(keyDetails["failure_count"], 10, 64)
+	weight, _ := strconv.Atoi(keyDetails["weight"])
 	createdAt, _ := strconv.ParseInt(keyDetails["created_at"], 10, 64)
 
 	// Decrypt the key value for use by channels
@@ -81,6 +101,7 @@ func (p *KeyProvider) SelectKey(groupID uint) (*models.APIKey, error) {
 		KeyValue:     decryptedKeyValue,
 		Status:       keyDetails["status"],
 		FailureCount: failureCount,
+		Weight:       weight,
 		GroupID:      groupID,
 		CreatedAt:    time.Unix(createdAt, 0),
 	}
@@ -88,32 +109,214 @@ func (p *KeyProvider) SelectKey(groupID uint) (*models.APIKey, error) {
 	return apiKey, nil
 }
 
-// UpdateStatus 异步地提交一个 Key 状态更新任务。
+// SetKeyCooldown 让 Key 在指定时长内不被选中，用于上游限流；冷却状态保存在 Store 中，多节点共享，且不计入失败次数。
+func (p *KeyProvider) SetKeyCooldown(apiKey *models.APIKey, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	cooldownUntil := time.Now().Add(duration).UnixMilli()
+	return p.store.HSet(fmt.Sprintf("key:%d", apiKey.ID), map[string]any{"cooldown_until": cooldownUntil})
+}
+
+// UpdateStatus 异步地提交一个 Key 状态更新任务。失败的状态码从错误信息中解析，见 ReportFailure。
 func (p *KeyProvider) UpdateStatus(apiKey *models.APIKey, group *models.Group, isSuccess bool, errorMessage string) {
+	if !isSuccess {
+		p.ReportFailure(apiKey, group, 0, errorMessage)
+		return
+	}
+	go func() {
+		keyHashKey := fmt.Sprintf("key:%d", apiKey.ID)
+		activeKeysListKey := fmt.Sprintf("group:%d:active_keys", group.ID)
+		if err := p.handleSuccess(apiKey.ID, keyHashKey, activeKeysListKey); err != nil {
+			logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "error": err}).Debug("Failed to update cache after success")
+		}
+	}()
+}
+
+// ReportFailure 异步地按失败类型更新 Key 状态：认证失败直接标记为无效，上游临时故障只退避，
+// 其余失败计入连续失败次数。statusCode 为 0 时从错误信息中解析。
+func (p *KeyProvider) ReportFailure(apiKey *models.APIKey, group *models.Group, statusCode int, errorMessage string) {
+	go func() {
+		if app_errors.IsUnCounted(errorMessage) {
+			logrus.WithFields(logrus.Fields{
+				"keyID": apiKey.ID,
+				"error": errorMessage,
+			}).Debug("Uncounted error, skipping failure handling")
+			return
+		}
+
+		keyHashKey := fmt.Sprintf("key:%d", apiKey.ID)
+		activeKeysListKey := fmt.Sprintf("group:%d:active_keys", group.ID)
+		kind := app_errors.ClassifyKeyFailure(statusCode, errorMessage)
+		if err := p.handleFailure(apiKey, group, kind, keyHashKey, activeKeysListKey); err != nil {
+			logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "error": err}).Debug("Failed to update cache after failure")
+		}
+	}()
+}
+
+// ApplyPolicyAction 异步地根据策略动作（invalidate/disable/degrade）更新 Key 状态。
+func (p *KeyProvider) ApplyPolicyAction(apiKey *models.APIKey, group *models.Group, action string, duration time.Duration, reason string) {
+	go func() {
+		keyHashKey := fmt.Sprintf("key:%d", apiKey.ID)
+		activeKeysListKey := fmt.Sprintf("group:%d:active_keys", group.ID)
+
+		var err error
+		switch action {
+		case models.RetryActionInvalidate:
+			err = p.setKeyStatus(apiKey.ID, keyHashKey, activeKeysListKey, models.KeyStatusInvalid, nil, reason)
+		case models.RetryActionDisable:
+			disabledUntil := time.Now().Add(duration)
+			err = p.setKeyStatus(apiKey.ID, keyHashKey, activeKeysListKey, models.KeyStatusDisabled, &disabledUntil, reason)
+		case models.RetryActionDegrade:
+			err = p.setKeyStatus(apiKey.ID, keyHashKey, activeKeysListKey, models.KeyStatusDegraded, nil, reason)
+		default:
+			err = fmt.Errorf("unsupported policy action: %s", action)
+		}
+
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "action": action, "error": err}).Error("Failed to apply policy action to key")
+			return
+		}
+		logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "action": action, "reason": reason}).Info("Applied policy action to key")
+	}()
+}
+
+// ParkExhaustedKey 异步地将额度耗尽的 Key 停用到下一次额度重置时间，不计入失败次数，
+// 到期后由 RestoreDisabledKeys 以活跃状态放回池中。
+func (p *KeyProvider) ParkExhaustedKey(apiKey *models.APIKey, group *models.Group, resetAt time.Time, reason string) {
 	go func() {
-		if isSuccess {
-			keyHashKey := fmt.Sprintf("key:%d", apiKey.ID)
-			activeKeysListKey := fmt.Sprintf("group:%d:active_keys", group.ID)
-			if err := p.handleSuccess(apiKey.ID, keyHashKey, activeKeysListKey); err != nil {
-				logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "error": err}).Debug("Failed to update cache after success")
+		activeKeysListKey := fmt.Sprintf("group:%d:active_keys", group.ID)
+		err := p.executeTransactionWithRetry(func(tx *gorm.DB) error {
+			var key models.APIKey
+			if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&key, apiKey.ID).Error; err != nil {
+				return fmt.Errorf("failed to lock key %d for update: %w", apiKey.ID, err)
 			}
-		} else {
-			if app_errors.IsUnCounted(errorMessage) {
-				logrus.WithFields(logrus.Fields{
-					"keyID": apiKey.ID,
-					"error": errorMessage,
-				}).Debug("Uncounted error, skipping failure handling")
-			} else {
-				keyHashKey := fmt.Sprintf("key:%d", apiKey.ID)
-				activeKeysListKey := fmt.Sprintf("group:%d:active_keys", group.ID)
-				if err := p.handleFailure(apiKey, group, keyHashKey, activeKeysListKey); err != nil {
-					logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "error": err}).Debug("Failed to update cache after failure")
-				}
+
+			if key.Status == models.KeyStatusInvalid {
+				return nil
+			}
+
+			updates := map[string]any{
+				"status":             models.KeyStatusDisabled,
+				"disabled_until":     &resetAt,
+				"quota_reset_at":     &resetAt,
+				"last_error_message": reason,
+			}
+			if err := tx.Model(&key).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update key status in DB: %w", err)
 			}
+
+			if err := p.store.HSet(fmt.Sprintf("key:%d", apiKey.ID), map[string]any{"status": models.KeyStatusDisabled}); err != nil {
+				return fmt.Errorf("failed to update key status in store: %w", err)
+			}
+			if err := p.store.LRem(activeKeysListKey, 0, apiKey.ID); err != nil {
+				return fmt.Errorf("failed to LRem key from active list: %w", err)
+			}
+			return nil
+		})
+
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "error": err}).Error("Failed to park quota exhausted key")
+			return
 		}
+		logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "group": group.Name, "resetAt": resetAt}).Info("Key quota exhausted, parked until next quota reset")
 	}()
 }
 
+// RestoreDisabledKeys 将 DisabledUntil 已到期的禁用 Key 放回活跃池。
+// 因额度耗尽停用的 Key 直接恢复为活跃状态；其余 Key 以降级状态恢复，
+// 连续失败次数和退避级别保留，再次失败会以更长的退避时间重新禁用。
+// Store 在事务提交后才更新，事务回滚时不会有 Key 提前进入活跃池。
+func (p *KeyProvider) RestoreDisabledKeys() (int64, error) {
+	var expiredKeys []models.APIKey
+	var restoredCount int64
+	now := time.Now()
+
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("status = ? AND disabled_until IS NOT NULL AND disabled_until <= ?", models.KeyStatusDisabled, now).Find(&expiredKeys).Error; err != nil {
+			return err
+		}
+
+		if len(expiredKeys) == 0 {
+			return nil
+		}
+
+		var quotaKeyIDs, backoffKeyIDs []uint
+		for i := range expiredKeys {
+			if expiredKeys[i].QuotaResetAt != nil {
+				expiredKeys[i].Status = models.KeyStatusActive
+				quotaKeyIDs = append(quotaKeyIDs, expiredKeys[i].ID)
+			} else {
+				expiredKeys[i].Status = models.KeyStatusDegraded
+				backoffKeyIDs = append(backoffKeyIDs, expiredKeys[i].ID)
+			}
+		}
+
+		if len(quotaKeyIDs) > 0 {
+			updates := map[string]any{
+				"status":         models.KeyStatusActive,
+				"disabled_until": nil,
+				"quota_reset_at": nil,
+			}
+			result := tx.Model(&models.APIKey{}).Where("id IN ?", quotaKeyIDs).Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			restoredCount += result.RowsAffected
+		}
+
+		if len(backoffKeyIDs) > 0 {
+			updates := map[string]any{
+				"status":         models.KeyStatusDegraded,
+				"disabled_until": nil,
+			}
+			result := tx.Model(&models.APIKey{}).Where("id IN ?", backoffKeyIDs).Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			restoredCount += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var failedCount int
+	for i := range expiredKeys {
+		if err := p.addKeyToStore(&expiredKeys[i]); err != nil {
+			logrus.WithFields(logrus.Fields{"keyID": expiredKeys[i].ID, "error": err}).Error("Failed to restore disabled key in store")
+			failedCount++
+		}
+	}
+	if failedCount > 0 {
+		return restoredCount, fmt.Errorf("failed to restore %d of %d keys in store", failedCount, len(expiredKeys))
+	}
+
+	return restoredCount, nil
+}
+
+// UpdateKeyWeight 更新 Key 的权重，并同步到 Store 供加权选择策略使用。
+func (p *KeyProvider) UpdateKeyWeight(keyID uint, weight int) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		var key models.APIKey
+		if err := tx.First(&key, keyID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&key).Update("weight", weight).Error; err != nil {
+			return err
+		}
+
+		if isSelectableStatus(key.Status) {
+			if err := p.store.HSet(fmt.Sprintf("key:%d", keyID), map[string]any{"weight": weight}); err != nil {
+				return fmt.Errorf("failed to update key weight in store: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
 // executeTransactionWithRetry wraps a database transaction with a retry mechanism.
 func (p *KeyProvider) executeTransactionWithRetry(operation func(tx *gorm.DB) error) error {
 	const maxRetries = 3
@@ -160,21 +363,32 @@ func (p *KeyProvider) handleSuccess(keyID uint, keyHashKey, activeKeysListKey st
 			return fmt.Errorf("failed to lock key %d for update: %w", keyID, err)
 		}
 
-		updates := map[string]any{"failure_count": 0}
-		if !isActive {
-			updates["status"] = models.KeyStatusActive
+		// 校验成功说明无效 Key 已恢复可用，直接回到活跃状态；其余状态交由状态机决定
+		newStatus := models.KeyStatusActive
+		if key.Status != models.KeyStatusInvalid {
+			newStatus = p.stateMachine.TransitionState(key.Status, true, key.ConsecutiveFailures)
 		}
 
+		now := time.Now()
+		updates := map[string]any{
+			"status":               newStatus,
+			"failure_count":        0,
+			"consecutive_failures": 0,
+			"backoff_level":        0,
+			"disabled_until":       nil,
+			"quota_reset_at":       nil,
+			"last_success_at":      &now,
+		}
 		if err := tx.Model(&key).Updates(updates).Error; err != nil {
 			return fmt.Errorf("failed to update key in DB: %w", err)
 		}
 
-		if err := p.store.HSet(keyHashKey, updates); err != nil {
+		if err := p.store.HSet(keyHashKey, map[string]any{"failure_count": 0, "status": newStatus}); err != nil {
 			return fmt.Errorf("failed to update key details in store: %w", err)
 		}
 
-		if !isActive {
-			logrus.WithField("keyID", keyID).Debug("Key has recovered and is being restored to active pool.")
+		if !isSelectableStatus(key.Status) && isSelectableStatus(newStatus) {
+			logrus.WithFields(logrus.Fields{"keyID": keyID, "status": newStatus}).Debug("Key has recovered and is being restored to active pool.")
 			if err := p.store.LRem(activeKeysListKey, 0, keyID); err != nil {
 				return fmt.Errorf("failed to LRem key before LPush on recovery: %w", err)
 			}
@@ -187,7 +401,10 @@ func (p *KeyProvider) handleSuccess(keyID uint, keyHashKey, activeKeysListKey st
 	})
 }
 
-func (p *KeyProvider) handleFailure(apiKey *models.APIKey, group *models.Group, keyHashKey, activeKeysListKey string) error {
+// handleFailure 通过状态机推进 Key 状态：active 失败后降级但仍可用，连续失败后按指数退避禁用。
+// 认证失败直接标记为无效；计数失败的连续失败次数达到 BlacklistThreshold 时标记为无效；
+// 上游临时故障只退避，不会使 Key 失效。BlacklistThreshold 为 0 时不拉黑。
+func (p *KeyProvider) handleFailure(apiKey *models.APIKey, group *models.Group, kind string, keyHashKey, activeKeysListKey string) error {
 	keyDetails, err := p.store.HGetAll(keyHashKey)
 	if err != nil {
 		return fmt.Errorf("failed to get key details from store: %w", err)
@@ -197,8 +414,6 @@ func (p *KeyProvider) handleFailure(apiKey *models.APIKey, group *models.Group,
 		return nil
 	}
 
-	failureCount, _ := strconv.ParseInt(keyDetails["failure_count"], 10, 64)
-
 	// 获取该分组的有效配置
 	blacklistThreshold := group.EffectiveConfig.BlacklistThreshold
 
@@ -208,12 +423,33 @@ func (p *KeyProvider) handleFailure(apiKey *models.APIKey, group *models.Group,
 			return fmt.Errorf("failed to lock key %d for update: %w", apiKey.ID, err)
 		}
 
-		newFailureCount := failureCount + 1
+		if key.Status == models.KeyStatusInvalid {
+			return nil
+		}
 
-		updates := map[string]any{"failure_count": newFailureCount}
-		shouldBlacklist := blacklistThreshold > 0 && newFailureCount >= int64(blacklistThreshold)
-		if shouldBlacklist {
-			updates["status"] = models.KeyStatusInvalid
+		now := time.Now()
+		consecutiveFailures := key.ConsecutiveFailures + 1
+		newStatus := p.stateMachine.TransitionState(key.Status, false, consecutiveFailures)
+
+		updates := map[string]any{
+			"failure_count":        key.FailureCount + 1,
+			"consecutive_failures": consecutiveFailures,
+			"last_failure_at":      &now,
+			"status":               newStatus,
+		}
+
+		switch {
+		case blacklistThreshold > 0 && (kind == app_errors.KeyFailureAuth ||
+			kind == app_errors.KeyFailureCounted && consecutiveFailures >= int64(blacklistThreshold)):
+			newStatus = models.KeyStatusInvalid
+			updates["status"] = newStatus
+			updates["disabled_until"] = nil
+		case newStatus == models.KeyStatusDisabled && key.Status != models.KeyStatusDisabled:
+			// 新进入禁用状态时提升退避级别
+			backoffLevel := key.BackoffLevel + 1
+			disabledUntil := now.Add(p.stateMachine.CalculateBackoffDuration(backoffLevel))
+			updates["backoff_level"] = backoffLevel
+			updates["disabled_until"] = &disabledUntil
 		}
 
 		if err := tx.Model(&key).Updates(updates).Error; err != nil {
@@ -224,13 +460,61 @@ func (p *KeyProvider) handleFailure(apiKey *models.APIKey, group *models.Group,
 			return fmt.Errorf("failed to increment failure count in store: %w", err)
 		}
 
-		if shouldBlacklist {
-			logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "threshold": blacklistThreshold}).Warn("Key has reached blacklist threshold, disabling.")
+		if newStatus == key.Status {
+			return nil
+		}
+
+		if err := p.store.HSet(keyHashKey, map[string]any{"status": newStatus}); err != nil {
+			return fmt.Errorf("failed to update key status to %s in store: %w", newStatus, err)
+		}
+
+		logFields := logrus.Fields{"keyID": apiKey.ID, "oldStatus": key.Status, "newStatus": newStatus, "consecutiveFailures": consecutiveFailures}
+		if !isSelectableStatus(newStatus) {
+			if newStatus == models.KeyStatusInvalid {
+				logrus.WithFields(logFields).WithField("failureKind", kind).Warn("Key failed authentication or reached the blacklist threshold, marking as invalid.")
+			} else {
+				logrus.WithFields(logFields).Warn("Key has been temporarily disabled.")
+			}
 			if err := p.store.LRem(activeKeysListKey, 0, apiKey.ID); err != nil {
 				return fmt.Errorf("failed to LRem key from active list: %w", err)
 			}
-			if err := p.store.HSet(keyHashKey, map[string]any{"status": models.KeyStatusInvalid}); err != nil {
-				return fmt.Errorf("failed to update key status to invalid in store: %w", err)
+		} else {
+			logrus.WithFields(logFields).Debug("Key status changed after failure.")
+		}
+
+		return nil
+	})
+}
+
+// setKeyStatus 将 Key 切换到指定状态，并同步 Store 中的详情和活跃列表。
+func (p *KeyProvider) setKeyStatus(keyID uint, keyHashKey, activeKeysListKey, status string, disabledUntil *time.Time, reason string) error {
+	return p.executeTransactionWithRetry(func(tx *gorm.DB) error {
+		var key models.APIKey
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").First(&key, keyID).Error; err != nil {
+			return fmt.Errorf("failed to lock key %d for update: %w", keyID, err)
+		}
+
+		if key.Status == models.KeyStatusInvalid {
+			return nil
+		}
+
+		updates := map[string]any{
+			"status":             status,
+			"disabled_until":     disabledUntil,
+			"quota_reset_at":     nil,
+			"last_error_message": reason,
+		}
+		if err := tx.Model(&key).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update key status in DB: %w", err)
+		}
+
+		if err := p.store.HSet(keyHashKey, map[string]any{"status": status}); err != nil {
+			return fmt.Errorf("failed to update key status in store: %w", err)
+		}
+
+		if !isSelectableStatus(status) {
+			if err := p.store.LRem(activeKeysListKey, 0, keyID); err != nil {
+				return fmt.Errorf("failed to LRem key from active list: %w", err)
 			}
 		}
 
@@ -267,7 +551,7 @@ func (p *KeyProvider) LoadKeysFromDB() error {
 				}
 			}
 
-			if key.Status == models.KeyStatusActive {
+			if isSelectableStatus(key.Status) {
 				allActiveKeyIDs[key.GroupID] = append(allActiveKeyIDs[key.GroupID], key.ID)
 			}
 		}
@@ -388,8 +672,12 @@ func (p *KeyProvider) RestoreKeys(groupID uint) (int64, error) {
 		}
 
 		updates := map[string]any{
-			"status":        models.KeyStatusActive,
-			"failure_count": 0,
+			"status":               models.KeyStatusActive,
+			"failure_count":        0,
+			"consecutive_failures": 0,
+			"backoff_level":        0,
+			"disabled_until":       nil,
+			"quota_reset_at":       nil,
 		}
 		result := tx.Model(&models.APIKey{}).Where("group_id = ? AND status = ?", groupID, models.KeyStatusInvalid).Updates(updates)
 		if result.Error != nil {
@@ -444,8 +732,12 @@ func (p *KeyProvider) RestoreMultipleKeys(groupID uint, keyValues []string) (int
 		keyIDsToRestore := pluckIDs(keysToRestore)
 
 		updates := map[string]any{
-			"status":        models.KeyStatusActive,
-			"failure_count": 0,
+			"status":               models.KeyStatusActive,
+			"failure_count":        0,
+			"consecutive_failures": 0,
+			"backoff_level":        0,
+			"disabled_until":       nil,
+			"quota_reset_at":       nil,
 		}
 		result := tx.Model(&models.APIKey{}).Where("id IN ?", keyIDsToRestore).Updates(updates)
 		if result.Error != nil {
@@ -566,8 +858,8 @@ func (p *KeyProvider) addKeyToStore(key *models.APIKey) error {
 		return fmt.Errorf("failed to HSet key details for key %d: %w", key.ID, err)
 	}
 
-	// 2. If active, add to the active LIST
-	if key.Status == models.KeyStatusActive {
+	// 2. If selectable, add to the active LIST
+	if isSelectableStatus(key.Status) {
 		activeKeysListKey := fmt.Sprintf("group:%d:active_keys", key.GroupID)
 		if err := p.store.LRem(activeKeysListKey, 0, key.ID); err != nil {
 			return fmt.Errorf("failed to LRem key %d before LPush for group %d: %w", key.ID, key.GroupID, err)
@@ -600,11 +892,17 @@ func (p *KeyProvider) apiKeyToMap(key *models.APIKey) map[string]any {
 		"key_string":    key.KeyValue,
 		"status":        key.Status,
 		"failure_count": key.FailureCount,
+		"weight":        key.Weight,
 		"group_id":      key.GroupID,
 		"created_at":    key.CreatedAt.Unix(),
 	}
 }
 
+// isSelectableStatus reports whether keys in the given status belong in the active list.
+func isSelectableStatus(status string) bool {
+	return status == models.KeyStatusActive || status == models.KeyStatusDegraded
+}
+
 // pluckIDs extracts IDs from a slice of APIKey.
 func pluckIDs(keys []models.APIKey) []uint {
 	ids := make([]uint, len(keys))
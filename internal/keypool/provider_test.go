@@ -2,11 +2,13 @@ package keypool
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"gpt-load/internal/config"
 	"gpt-load/internal/encryption"
@@ -61,6 +63,15 @@ func (m *MockStore) Rotate(key string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockStore) LRange(key string, start, stop int64) ([]string, error) {
+	args := m.Called(key, start, stop)
+	result := args.Get(0)
+	if result == nil {
+		return nil, args.Error(1)
+	}
+	return result.([]string), args.Error(1)
+}
+
 func (m *MockStore) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -119,6 +130,26 @@ func (m *MockStore) SPopN(key string, count int64) ([]string, error) {
 	return result.([]string), args.Error(1)
 }
 
+func (m *MockStore) TakeToken(key string, capacity int64, interval time.Duration) (bool, time.Duration, error) {
+	args := m.Called(key, capacity, interval)
+	return args.Bool(0), args.Get(1).(time.Duration), args.Error(2)
+}
+
+func (m *MockStore) HCompareAndSet(key, field, expected, value string) (bool, error) {
+	args := m.Called(key, field, expected, value)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStore) AcquireSlot(key string, limit int64, ttl time.Duration) (bool, error) {
+	args := m.Called(key, limit, ttl)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStore) ReleaseSlot(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
 func (m *MockStore) Publish(channel string, message []byte) error {
 	args := m.Called(channel, message)
 	return args.Error(0)
@@ -288,6 +319,82 @@ func TestKeyProvider_UpdateStatus(t *testing.T) {
 	})
 }
 
+func TestKeyProvider_handleFailure_StateMachine(t *testing.T) {
+	setup := func(t *testing.T, threshold int) func(kind string) models.APIKey {
+		db := tests.SetupTestDB(t)
+		settingsManager := &config.SystemSettingsManager{}
+		encryptionSvc, _ := encryption.NewService("test-password")
+
+		provider := NewProvider(db, store.NewMemoryStore(), settingsManager, encryptionSvc)
+		group := &models.Group{ID: 1}
+		group.EffectiveConfig.BlacklistThreshold = threshold
+
+		testKey := models.APIKey{KeyValue: "test-key", Status: models.KeyStatusActive, GroupID: 1}
+		require.NoError(t, db.Create(&testKey).Error)
+
+		keyHashKey := fmt.Sprintf("key:%d", testKey.ID)
+		activeKeysListKey := "group:1:active_keys"
+		return func(kind string) models.APIKey {
+			require.NoError(t, provider.handleFailure(&testKey, group, kind, keyHashKey, activeKeysListKey))
+			var key models.APIKey
+			require.NoError(t, db.First(&key, testKey.ID).Error)
+			// 模拟禁用到期后的恢复
+			if key.Status == models.KeyStatusDisabled {
+				require.NoError(t, db.Model(&models.APIKey{}).Where("id = ?", key.ID).Updates(map[string]any{"status": models.KeyStatusDegraded, "disabled_until": nil}).Error)
+			}
+			return key
+		}
+	}
+
+	t.Run("auth failure invalidates immediately", func(t *testing.T) {
+		fail := setup(t, 3)
+		key := fail(app_errors.KeyFailureAuth)
+		assert.Equal(t, models.KeyStatusInvalid, key.Status)
+		assert.Nil(t, key.DisabledUntil)
+	})
+
+	t.Run("counted failures invalidate at the blacklist threshold", func(t *testing.T) {
+		fail := setup(t, 3)
+
+		// 首次失败仅降级
+		key := fail(app_errors.KeyFailureCounted)
+		assert.Equal(t, models.KeyStatusDegraded, key.Status)
+		assert.Nil(t, key.DisabledUntil)
+
+		key = fail(app_errors.KeyFailureCounted)
+		assert.Equal(t, models.KeyStatusDegraded, key.Status)
+
+		key = fail(app_errors.KeyFailureCounted)
+		assert.Equal(t, models.KeyStatusInvalid, key.Status)
+		assert.Equal(t, int64(3), key.FailureCount)
+	})
+
+	t.Run("transient failures only back off", func(t *testing.T) {
+		fail := setup(t, 3)
+
+		fail(app_errors.KeyFailureTransient)
+		fail(app_errors.KeyFailureTransient)
+		key := fail(app_errors.KeyFailureTransient)
+		assert.Equal(t, models.KeyStatusDisabled, key.Status)
+		assert.Equal(t, 1, key.BackoffLevel)
+		require.NotNil(t, key.DisabledUntil)
+		assert.True(t, key.DisabledUntil.After(time.Now()))
+
+		// 到期恢复为降级状态，再次失败进入下一级退避，始终不会标记为无效
+		for level := 2; level <= 6; level++ {
+			key = fail(app_errors.KeyFailureTransient)
+			assert.Equal(t, models.KeyStatusDisabled, key.Status)
+			assert.Equal(t, level, key.BackoffLevel)
+		}
+	})
+
+	t.Run("zero threshold never invalidates", func(t *testing.T) {
+		fail := setup(t, 0)
+		key := fail(app_errors.KeyFailureAuth)
+		assert.NotEqual(t, models.KeyStatusInvalid, key.Status)
+	})
+}
+
 func TestKeyProvider_AddKeys(t *testing.T) {
 	db := tests.SetupTestDB(t)
 	mockStore := &MockStore{}
@@ -455,6 +562,109 @@ func TestKeyProvider_RestoreKeys(t *testing.T) {
 	})
 }
 
+func TestKeyProvider_RestoreDisabledKeys(t *testing.T) {
+	db := tests.SetupTestDB(t)
+	mockStore := &MockStore{}
+	settingsManager := &config.SystemSettingsManager{}
+	encryptionSvc, _ := encryption.NewService("test-password")
+
+	provider := NewProvider(db, mockStore, settingsManager, encryptionSvc)
+
+	t.Run("restores only expired disabled keys", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute)
+		pending := time.Now().Add(time.Hour)
+		testKeys := []models.APIKey{
+			{KeyValue: "expired-key", Status: models.KeyStatusDisabled, DisabledUntil: &expired, GroupID: 1},
+			{KeyValue: "pending-key", Status: models.KeyStatusDisabled, DisabledUntil: &pending, GroupID: 1},
+		}
+		db.Create(&testKeys)
+
+		mockStore.On("HSet", "key:"+fmt.Sprint(testKeys[0].ID), mock.AnythingOfType("map[string]interface {}")).Return(nil).Once()
+		mockStore.On("LRem", "group:1:active_keys", int64(0), testKeys[0].ID).Return(nil).Once()
+		mockStore.On("LPush", "group:1:active_keys", mock.Anything).Return(nil).Once()
+
+		restoredCount, err := provider.RestoreDisabledKeys()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), restoredCount)
+
+		var restored, stillDisabled models.APIKey
+		db.First(&restored, testKeys[0].ID)
+		db.First(&stillDisabled, testKeys[1].ID)
+		assert.Equal(t, models.KeyStatusDegraded, restored.Status)
+		assert.Nil(t, restored.DisabledUntil)
+		assert.Equal(t, models.KeyStatusDisabled, stillDisabled.Status)
+
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("store is updated after commit", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute)
+		testKeys := []models.APIKey{
+			{KeyValue: "store-fail-key", Status: models.KeyStatusDisabled, DisabledUntil: &expired, GroupID: 2},
+			{KeyValue: "store-ok-key", Status: models.KeyStatusDisabled, DisabledUntil: &expired, GroupID: 2},
+		}
+		db.Create(&testKeys)
+
+		mockStore.On("HSet", "key:"+fmt.Sprint(testKeys[0].ID), mock.AnythingOfType("map[string]interface {}")).Return(errors.New("store down")).Once()
+		mockStore.On("HSet", "key:"+fmt.Sprint(testKeys[1].ID), mock.AnythingOfType("map[string]interface {}")).Return(nil).Once()
+		mockStore.On("LRem", "group:2:active_keys", int64(0), testKeys[1].ID).Return(nil).Once()
+		mockStore.On("LPush", "group:2:active_keys", mock.Anything).Return(nil).Once()
+
+		restoredCount, err := provider.RestoreDisabledKeys()
+
+		assert.Error(t, err)
+		assert.Equal(t, int64(2), restoredCount)
+
+		// 数据库已提交，其余 Key 仍写入 Store
+		var restored models.APIKey
+		db.First(&restored, testKeys[0].ID)
+		assert.Equal(t, models.KeyStatusDegraded, restored.Status)
+
+		mockStore.AssertExpectations(t)
+	})
+}
+
+func TestKeyProvider_ParkExhaustedKey(t *testing.T) {
+	db := tests.SetupTestDB(t)
+	memStore := store.NewMemoryStore()
+	encryptionSvc, _ := encryption.NewService("")
+	provider := NewProvider(db, memStore, &config.SystemSettingsManager{}, encryptionSvc)
+	group := &models.Group{ID: 1, Name: "test-group"}
+
+	require.NoError(t, provider.AddKeys(1, []models.APIKey{{KeyValue: "exhausted-key", GroupID: 1, Status: models.KeyStatusActive}}))
+	var key models.APIKey
+	require.NoError(t, db.First(&key).Error)
+
+	resetAt := time.Now().Add(50 * time.Millisecond)
+	provider.ParkExhaustedKey(&key, group, resetAt, "quota exhausted")
+
+	require.Eventually(t, func() bool {
+		var parked models.APIKey
+		db.First(&parked, key.ID)
+		return parked.Status == models.KeyStatusDisabled && parked.QuotaResetAt != nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err := provider.SelectKey(1)
+	assert.ErrorIs(t, err, app_errors.ErrNoActiveKeys)
+
+	time.Sleep(60 * time.Millisecond)
+	restored, err := provider.RestoreDisabledKeys()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), restored)
+
+	var dbKey models.APIKey
+	require.NoError(t, db.First(&dbKey, key.ID).Error)
+	assert.Equal(t, models.KeyStatusActive, dbKey.Status)
+	assert.Nil(t, dbKey.QuotaResetAt)
+	assert.Nil(t, dbKey.DisabledUntil)
+	assert.Equal(t, int64(0), dbKey.FailureCount)
+
+	selected, err := provider.SelectKey(1)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, selected.ID)
+}
+
 func TestKeyProvider_RemoveInvalidKeys(t *testing.T) {
 	db := tests.SetupTestDB(t)
 	mockStore := &MockStore{}
@@ -646,6 +856,7 @@ func TestKeyProvider_apiKeyToMap(t *testing.T) {
 		KeyValue:     "test-key-value",
 		Status:       models.KeyStatusActive,
 		FailureCount: 5,
+		Weight:       3,
 		GroupID:      1,
 		CreatedAt:    createdAt,
 	}
@@ -657,6 +868,7 @@ func TestKeyProvider_apiKeyToMap(t *testing.T) {
 		"key_string":    "test-key-value",
 		"status":        models.KeyStatusActive,
 		"failure_count": int64(5),
+		"weight":        3,
 		"group_id":      uint(1),
 		"created_at":    createdAt.Unix(),
 	}
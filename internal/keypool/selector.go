@@ -0,0 +1,330 @@
+package keypool
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/store"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Key selection strategies supported by SelectKeyWithStrategy.
+//
+// weighted, lru and least_failures are sampled approximations: they compare at most maxSelectionCandidates
+// randomly sampled keys, so in larger pools weighted shares only approximate the key weights, and lru and
+// least_failures pick the best key of the sample rather than of the whole pool. lru claims the chosen key by
+// atomically swapping its last_used_at, so concurrent requests never take the same least recently used key.
+// least_failures ranks by failure_count, the failures since the key's last success.
+const (
+	SelectionRoundRobin    = "round_robin"
+	SelectionWeighted      = "weighted"
+	SelectionLRU           = "lru"
+	SelectionLeastFailures = "least_failures"
+	SelectionRandom        = "random"
+)
+
+const (
+	// maxSelectionCandidates bounds how many available keys are compared per selection for the
+	// detail-based strategies, so large pools do not turn every request into a full scan.
+	maxSelectionCandidates = 32
+	// maxSelectionProbes bounds how many keys are inspected per selection while skipping
+	// keys that are cooling down or saturated.
+	maxSelectionProbes = 64
+	// keySlotTTL bounds how long an in-flight slot survives without activity, so slots held by a crashed
+	// node are eventually freed. It is refreshed whenever a slot of the key is acquired.
+	keySlotTTL = 30 * time.Minute
+)
+
+// pickKey chooses a key from the group's active list according to the strategy and returns its ID and details.
+// Round-robin keeps using the atomic Rotate; the other strategies read the active list with LRange.
+// Both rely only on Store primitives, so they behave the same on Redis and in memory.
+// Keys that are cooling down are skipped. With maxConcurrent > 0 an in-flight slot is acquired for the
+// returned key and saturated keys are skipped as well; the caller must release the slot with ReleaseKeySlot.
+func (p *KeyProvider) pickKey(activeKeysListKey, strategy string, maxConcurrent int) (string, map[string]string, error) {
+	now := time.Now()
+
+	if strategy == "" || strategy == SelectionRoundRobin {
+		return p.pickRoundRobin(activeKeysListKey, now, maxConcurrent)
+	}
+
+	switch strategy {
+	case SelectionRandom, SelectionWeighted, SelectionLRU, SelectionLeastFailures:
+	default:
+		logrus.Warnf("Unknown key selection strategy '%s', falling back to round_robin", strategy)
+		return p.pickRoundRobin(activeKeysListKey, now, maxConcurrent)
+	}
+
+	keyIDs, err := p.store.LRange(activeKeysListKey, 0, -1)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to select key from store: %w", err)
+	}
+	if len(keyIDs) == 0 {
+		return "", nil, store.ErrNotFound
+	}
+
+	if strategy == SelectionRandom {
+		return p.pickRandom(keyIDs, now, maxConcurrent)
+	}
+
+	var skipped skippedKeys
+	var candidates []string
+	var details []map[string]string
+	for i, keyID := range shuffleKeyIDs(keyIDs) {
+		if i >= maxSelectionProbes || len(candidates) >= maxSelectionCandidates {
+			break
+		}
+		d, err := p.store.HGetAll("key:" + keyID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get key details for key ID %s: %w", keyID, err)
+		}
+		if skipped.coolingDown(d, now) {
+			continue
+		}
+		candidates = append(candidates, keyID)
+		details = append(details, d)
+	}
+
+	var lruClaims int // 因并发选中而失败的 lru 认领次数
+	for len(candidates) > 0 {
+		var best int
+		switch strategy {
+		case SelectionWeighted:
+			best = pickWeighted(details)
+		case SelectionLRU:
+			best = pickMinField(details, "last_used_at")
+		case SelectionLeastFailures:
+			best = pickMinField(details, "failure_count")
+		}
+
+		acquired, err := p.acquireKeySlot(candidates[best], maxConcurrent)
+		if err != nil {
+			return "", nil, err
+		}
+		if acquired && strategy == SelectionLRU {
+			claimed, err := p.claimLRUKey(candidates[best], details[best], now)
+			if err != nil {
+				p.releaseKeySlot(candidates[best], maxConcurrent)
+				return "", nil, err
+			}
+			// 已被并发请求选中：刷新其使用时间后重新比较，池中 Key 少于并发数时仍会轮到它；
+			// 竞争过于激烈时不再重试，直接使用该 Key
+			if !claimed && lruClaims < maxSelectionProbes {
+				lruClaims++
+				p.releaseKeySlot(candidates[best], maxConcurrent)
+				d, err := p.store.HGetAll("key:" + candidates[best])
+				if err != nil {
+					return "", nil, fmt.Errorf("failed to get key details for key ID %s: %w", candidates[best], err)
+				}
+				details[best] = d
+				continue
+			}
+		}
+		if acquired {
+			return candidates[best], details[best], nil
+		}
+
+		skipped.saturated = true
+		candidates = append(candidates[:best], candidates[best+1:]...)
+		details = append(details[:best], details[best+1:]...)
+	}
+
+	return "", nil, skipped.err(now)
+}
+
+// pickRandom returns the first usable key in random order. Unlike the comparing strategies it stops at the
+// first available key, so a request normally costs a single HGetAll.
+func (p *KeyProvider) pickRandom(keyIDs []string, now time.Time, maxConcurrent int) (string, map[string]string, error) {
+	var skipped skippedKeys
+	for i, keyID := range shuffleKeyIDs(keyIDs) {
+		if i >= maxSelectionProbes {
+			break
+		}
+		d, err := p.store.HGetAll("key:" + keyID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get key details for key ID %s: %w", keyID, err)
+		}
+		if skipped.coolingDown(d, now) {
+			continue
+		}
+
+		acquired, err := p.acquireKeySlot(keyID, maxConcurrent)
+		if err != nil {
+			return "", nil, err
+		}
+		if acquired {
+			return keyID, d, nil
+		}
+		skipped.saturated = true
+	}
+	return "", nil, skipped.err(now)
+}
+
+// pickRoundRobin rotates the active list until it finds a key that is neither cooling down nor saturated.
+func (p *KeyProvider) pickRoundRobin(activeKeysListKey string, now time.Time, maxConcurrent int) (string, map[string]string, error) {
+	seen := make(map[string]struct{})
+	var skipped skippedKeys
+	for range maxSelectionProbes {
+		keyID, err := p.store.Rotate(activeKeysListKey)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to select key from store: %w", err)
+		}
+		if _, ok := seen[keyID]; ok {
+			break
+		}
+		seen[keyID] = struct{}{}
+		if _, err := strconv.ParseUint(keyID, 10, 64); err != nil {
+			// Malformed IDs are reported by the caller when it parses the ID.
+			return keyID, nil, nil
+		}
+
+		d, err := p.store.HGetAll("key:" + keyID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get key details for key ID %s: %w", keyID, err)
+		}
+		if skipped.coolingDown(d, now) {
+			continue
+		}
+
+		acquired, err := p.acquireKeySlot(keyID, maxConcurrent)
+		if err != nil {
+			return "", nil, err
+		}
+		if acquired {
+			return keyID, d, nil
+		}
+		skipped.saturated = true
+	}
+	return "", nil, skipped.err(now)
+}
+
+// acquireKeySlot takes an in-flight slot of the key when a concurrency limit is configured.
+func (p *KeyProvider) acquireKeySlot(keyID string, maxConcurrent int) (bool, error) {
+	if maxConcurrent <= 0 {
+		return true, nil
+	}
+	acquired, err := p.store.AcquireSlot(keySlotKey(keyID), int64(maxConcurrent), keySlotTTL)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire in-flight slot for key ID %s: %w", keyID, err)
+	}
+	return acquired, nil
+}
+
+// releaseKeySlot gives back an in-flight slot taken by acquireKeySlot.
+func (p *KeyProvider) releaseKeySlot(keyID string, maxConcurrent int) {
+	if maxConcurrent <= 0 {
+		return
+	}
+	if err := p.store.ReleaseSlot(keySlotKey(keyID)); err != nil {
+		logrus.WithFields(logrus.Fields{"keyID": keyID, "error": err}).Debug("Failed to release in-flight slot")
+	}
+}
+
+// claimLRUKey records the key as used now, unless another selection has used it since its details were read.
+func (p *KeyProvider) claimLRUKey(keyID string, details map[string]string, now time.Time) (bool, error) {
+	claimed, err := p.store.HCompareAndSet("key:"+keyID, "last_used_at", details["last_used_at"], strconv.FormatInt(now.UnixNano(), 10))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim key ID %s: %w", keyID, err)
+	}
+	return claimed, nil
+}
+
+// keySlotKey returns the store key of a key's in-flight counter.
+func keySlotKey(keyID string) string {
+	return "key:" + keyID + ":in_flight"
+}
+
+// KeysCoolingDownError is returned when every inspected key is cooling down. It unwraps to
+// app_errors.ErrKeysCoolingDown and carries the time until the first of those keys is usable again.
+type KeysCoolingDownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *KeysCoolingDownError) Error() string {
+	return app_errors.ErrKeysCoolingDown.Message
+}
+
+func (e *KeysCoolingDownError) Unwrap() error {
+	return app_errors.ErrKeysCoolingDown
+}
+
+// skippedKeys records why inspected keys could not be used, to report the right error when none is.
+type skippedKeys struct {
+	saturated     bool
+	cooldownUntil int64 // Earliest cooldown end of the skipped keys in Unix milliseconds, 0 if none.
+}
+
+// coolingDown reports whether the key is cooling down, remembering the earliest cooldown end.
+func (s *skippedKeys) coolingDown(details map[string]string, now time.Time) bool {
+	cooldownUntil, err := strconv.ParseInt(details["cooldown_until"], 10, 64)
+	if err != nil || cooldownUntil <= now.UnixMilli() {
+		return false
+	}
+	if s.cooldownUntil == 0 || cooldownUntil < s.cooldownUntil {
+		s.cooldownUntil = cooldownUntil
+	}
+	return true
+}
+
+// err returns the error for a selection that found no usable key.
+func (s *skippedKeys) err(now time.Time) error {
+	if s.saturated {
+		return app_errors.ErrKeysSaturated
+	}
+	var retryAfter time.Duration
+	if s.cooldownUntil > 0 {
+		retryAfter = time.UnixMilli(s.cooldownUntil).Sub(now)
+	}
+	return &KeysCoolingDownError{RetryAfter: retryAfter}
+}
+
+// shuffleKeyIDs returns the key IDs in random order without modifying the input.
+func shuffleKeyIDs(keyIDs []string) []string {
+	shuffled := make([]string, len(keyIDs))
+	copy(shuffled, keyIDs)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+// pickWeighted returns the index of a sampled candidate chosen with probability proportional to its weight (default 1).
+func pickWeighted(details []map[string]string) int {
+	weights := make([]int64, len(details))
+	var total int64
+	for i, d := range details {
+		weight, err := strconv.ParseInt(d["weight"], 10, 64)
+		if err != nil || weight <= 0 {
+			weight = 1
+		}
+		weights[i] = weight
+		total += weight
+	}
+
+	r := rand.Int63n(total)
+	for i, weight := range weights {
+		if r < weight {
+			return i
+		}
+		r -= weight
+	}
+	return len(details) - 1
+}
+
+// pickMinField returns the index of the candidate with the smallest numeric value of field; missing values count as 0.
+// Candidates are already shuffled, so ties are broken randomly.
+func pickMinField(details []map[string]string, field string) int {
+	best := 0
+	var bestValue int64
+	for i, d := range details {
+		value, _ := strconv.ParseInt(d[field], 10, 64)
+		if i == 0 || value < bestValue {
+			best = i
+			bestValue = value
+		}
+	}
+	return best
+}
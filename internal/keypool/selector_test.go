@@ -0,0 +1,272 @@
+package keypool
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gpt-load/internal/config"
+	"gpt-load/internal/encryption"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/store"
+	"gpt-load/internal/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSelectionProvider(t *testing.T, keys []models.APIKey) (*KeyProvider, store.Store) {
+	db := tests.SetupTestDB(t)
+	memStore := store.NewMemoryStore()
+	encryptionSvc, _ := encryption.NewService("")
+
+	provider := NewProvider(db, memStore, &config.SystemSettingsManager{}, encryptionSvc)
+	require.NoError(t, provider.AddKeys(1, keys))
+	return provider, memStore
+}
+
+// hGetAllCountingStore counts HGetAll calls, each of which is a round trip on Redis.
+type hGetAllCountingStore struct {
+	store.Store
+	hGetAlls int
+}
+
+func (s *hGetAllCountingStore) HGetAll(key string) (map[string]string, error) {
+	s.hGetAlls++
+	return s.Store.HGetAll(key)
+}
+
+func TestKeyProvider_SelectKeyWithStrategy(t *testing.T) {
+	t.Run("round robin cycles through all keys", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+			{KeyValue: "k2", GroupID: 1, Status: models.KeyStatusActive},
+		})
+
+		seen := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			key, err := provider.SelectKeyWithStrategy(1, SelectionRoundRobin)
+			require.NoError(t, err)
+			seen[key.KeyValue] = true
+		}
+		assert.Len(t, seen, 2)
+	})
+
+	t.Run("least failures prefers the healthiest key", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "flaky", GroupID: 1, Status: models.KeyStatusDegraded, FailureCount: 3},
+			{KeyValue: "healthy", GroupID: 1, Status: models.KeyStatusActive},
+		})
+
+		for i := 0; i < 5; i++ {
+			key, err := provider.SelectKeyWithStrategy(1, SelectionLeastFailures)
+			require.NoError(t, err)
+			assert.Equal(t, "healthy", key.KeyValue)
+		}
+	})
+
+	t.Run("lru alternates between keys", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+			{KeyValue: "k2", GroupID: 1, Status: models.KeyStatusActive},
+		})
+
+		first, err := provider.SelectKeyWithStrategy(1, SelectionLRU)
+		require.NoError(t, err)
+		second, err := provider.SelectKeyWithStrategy(1, SelectionLRU)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.KeyValue, second.KeyValue)
+	})
+
+	t.Run("concurrent lru selections take distinct keys", func(t *testing.T) {
+		const keyCount = 8
+		keys := make([]models.APIKey, keyCount)
+		for i := range keys {
+			keys[i] = models.APIKey{KeyValue: fmt.Sprintf("k%d", i), GroupID: 1, Status: models.KeyStatusActive}
+		}
+		provider, _ := setupSelectionProvider(t, keys)
+
+		var wg sync.WaitGroup
+		selected := make(chan string, keyCount)
+		for range keyCount {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				key, err := provider.SelectKeyWithStrategy(1, SelectionLRU)
+				if assert.NoError(t, err) {
+					selected <- key.KeyValue
+				}
+			}()
+		}
+		wg.Wait()
+		close(selected)
+
+		seen := map[string]bool{}
+		for keyValue := range selected {
+			assert.False(t, seen[keyValue], "key %s selected twice", keyValue)
+			seen[keyValue] = true
+		}
+		assert.Len(t, seen, keyCount)
+	})
+
+	t.Run("weighted follows key weights", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "heavy", GroupID: 1, Status: models.KeyStatusActive, Weight: 99},
+			{KeyValue: "light", GroupID: 1, Status: models.KeyStatusActive, Weight: 1},
+		})
+
+		counts := map[string]int{}
+		for i := 0; i < 500; i++ {
+			key, err := provider.SelectKeyWithStrategy(1, SelectionWeighted)
+			require.NoError(t, err)
+			counts[key.KeyValue]++
+		}
+		assert.Greater(t, counts["heavy"], counts["light"]*5)
+	})
+
+	t.Run("random only returns active keys", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+			{KeyValue: "dead", GroupID: 1, Status: models.KeyStatusInvalid},
+		})
+
+		for i := 0; i < 10; i++ {
+			key, err := provider.SelectKeyWithStrategy(1, SelectionRandom)
+			require.NoError(t, err)
+			assert.Equal(t, "k1", key.KeyValue)
+		}
+	})
+
+	t.Run("random probes a single key", func(t *testing.T) {
+		countingStore := &hGetAllCountingStore{Store: store.NewMemoryStore()}
+		encryptionSvc, _ := encryption.NewService("")
+		provider := NewProvider(tests.SetupTestDB(t), countingStore, &config.SystemSettingsManager{}, encryptionSvc)
+
+		var keys []models.APIKey
+		for i := 0; i < 10; i++ {
+			keys = append(keys, models.APIKey{KeyValue: fmt.Sprintf("k%d", i), GroupID: 1, Status: models.KeyStatusActive})
+		}
+		require.NoError(t, provider.AddKeys(1, keys))
+
+		countingStore.hGetAlls = 0
+		_, err := provider.SelectKeyWithStrategy(1, SelectionRandom)
+		require.NoError(t, err)
+		assert.Equal(t, 1, countingStore.hGetAlls)
+	})
+
+	t.Run("empty pool", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, nil)
+
+		for _, strategy := range []string{SelectionRoundRobin, SelectionWeighted, SelectionLRU, SelectionLeastFailures, SelectionRandom} {
+			_, err := provider.SelectKeyWithStrategy(1, strategy)
+			assert.Error(t, err, fmt.Sprintf("strategy %s", strategy))
+		}
+	})
+}
+
+func TestKeyProvider_SetKeyCooldown(t *testing.T) {
+	strategies := []string{SelectionRoundRobin, SelectionWeighted, SelectionLRU, SelectionLeastFailures, SelectionRandom}
+
+	t.Run("cooling keys are skipped", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "limited", GroupID: 1, Status: models.KeyStatusActive},
+			{KeyValue: "free", GroupID: 1, Status: models.KeyStatusActive},
+		})
+
+		limited, err := provider.SelectKeyWithStrategy(1, SelectionRoundRobin)
+		require.NoError(t, err)
+		if limited.KeyValue != "limited" {
+			limited, err = provider.SelectKeyWithStrategy(1, SelectionRoundRobin)
+			require.NoError(t, err)
+		}
+		require.NoError(t, provider.SetKeyCooldown(limited, time.Minute))
+
+		for _, strategy := range strategies {
+			for i := 0; i < 4; i++ {
+				key, err := provider.SelectKeyWithStrategy(1, strategy)
+				require.NoError(t, err, strategy)
+				assert.Equal(t, "free", key.KeyValue, strategy)
+			}
+		}
+	})
+
+	t.Run("all keys cooling down", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+		})
+
+		key, err := provider.SelectKeyWithStrategy(1, SelectionRoundRobin)
+		require.NoError(t, err)
+		require.NoError(t, provider.SetKeyCooldown(key, time.Minute))
+
+		for _, strategy := range strategies {
+			_, err := provider.SelectKeyWithStrategy(1, strategy)
+			assert.ErrorIs(t, err, app_errors.ErrKeysCoolingDown, strategy)
+
+			var coolingErr *KeysCoolingDownError
+			require.ErrorAs(t, err, &coolingErr, strategy)
+			assert.Greater(t, coolingErr.RetryAfter, 50*time.Second, strategy)
+			assert.LessOrEqual(t, coolingErr.RetryAfter, time.Minute, strategy)
+		}
+
+		var dbKey models.APIKey
+		require.NoError(t, provider.db.First(&dbKey, key.ID).Error)
+		assert.Equal(t, models.KeyStatusActive, dbKey.Status)
+		assert.Equal(t, int64(0), dbKey.FailureCount)
+	})
+
+	t.Run("expired cooldown", func(t *testing.T) {
+		provider, memStore := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+		})
+
+		key, err := provider.SelectKeyWithStrategy(1, SelectionRoundRobin)
+		require.NoError(t, err)
+		require.NoError(t, memStore.HSet(fmt.Sprintf("key:%d", key.ID), map[string]any{"cooldown_until": time.Now().Add(-time.Second).UnixMilli()}))
+
+		_, err = provider.SelectKeyWithStrategy(1, SelectionRoundRobin)
+		assert.NoError(t, err)
+	})
+}
+
+func TestKeyProvider_SelectKeyForGroup_ConcurrencyLimit(t *testing.T) {
+	for _, strategy := range []string{SelectionRoundRobin, SelectionWeighted, SelectionLRU, SelectionLeastFailures, SelectionRandom} {
+		t.Run(strategy, func(t *testing.T) {
+			provider, _ := setupSelectionProvider(t, []models.APIKey{
+				{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+				{KeyValue: "k2", GroupID: 1, Status: models.KeyStatusActive},
+			})
+			group := &models.Group{ID: 1}
+			group.EffectiveConfig.KeySelectionStrategy = strategy
+			group.EffectiveConfig.MaxConcurrentPerKey = 1
+
+			first, err := provider.SelectKeyForGroup(group)
+			require.NoError(t, err)
+			second, err := provider.SelectKeyForGroup(group)
+			require.NoError(t, err)
+			assert.NotEqual(t, first.KeyValue, second.KeyValue)
+
+			_, err = provider.SelectKeyForGroup(group)
+			assert.ErrorIs(t, err, app_errors.ErrKeysSaturated)
+
+			provider.ReleaseKeySlot(first)
+			third, err := provider.SelectKeyForGroup(group)
+			require.NoError(t, err)
+			assert.Equal(t, first.KeyValue, third.KeyValue)
+		})
+	}
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		provider, _ := setupSelectionProvider(t, []models.APIKey{
+			{KeyValue: "k1", GroupID: 1, Status: models.KeyStatusActive},
+		})
+		group := &models.Group{ID: 1}
+
+		for i := 0; i < 5; i++ {
+			_, err := provider.SelectKeyForGroup(group)
+			require.NoError(t, err)
+		}
+	})
+}
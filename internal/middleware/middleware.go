@@ -157,11 +157,8 @@ func ProxyAuth(gm *services.GroupManager) gin.HandlerFunc {
 			return
 		}
 
-		// Check both key collections to prevent timing attacks
-		_, existsInEffective := group.EffectiveConfig.ProxyKeysMap[key]
-		_, existsInGroup := group.ProxyKeysMap[key]
-
-		if existsInEffective || existsInGroup {
+		if group.HasProxyKey(key) {
+			c.Set("proxyKey", key)
 			c.Next()
 			return
 		}
@@ -171,6 +168,22 @@ func ProxyAuth(gm *services.GroupManager) gin.HandlerFunc {
 	}
 }
 
+// UnifiedProxyAuth requires a proxy key on the unified endpoint.
+// The key is checked against the groups resolved from the model routing table by the proxy handler.
+func UnifiedProxyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := extractAuthKey(c)
+		if key == "" {
+			response.Error(c, app_errors.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Set("requestedProxyKey", key)
+		c.Next()
+	}
+}
+
 // Recovery creates a recovery middleware with custom error handling
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
@@ -0,0 +1,40 @@
+package models
+
+import "path"
+
+// 请求体规则动作
+const (
+	BodyRuleActionSet        = "set"         // 设置字段值，覆盖已有值
+	BodyRuleActionSetDefault = "set_default" // 字段不存在时设置默认值
+	BodyRuleActionDelete     = "delete"      // 删除字段
+	BodyRuleActionClamp      = "clamp"       // 将数值限制在 [min, max] 区间内
+)
+
+// BodyRule defines a single request body transformation. Rules are applied in order after the parameter overrides.
+type BodyRule struct {
+	Action string   `json:"action"`
+	Path   string   `json:"path"` // 点分隔的 JSON 路径，数字段表示数组下标，如 "generationConfig.maxOutputTokens"
+	Value  any      `json:"value,omitempty"`
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Models []string `json:"models,omitempty"` // 仅对匹配的模型生效，支持通配模式，为空时不限制
+	Paths  []string `json:"paths,omitempty"`  // 仅对匹配的请求路径生效（不含 /proxy/<group> 前缀），支持通配模式，为空时不限制
+}
+
+// Applies reports whether the rule is scoped to the given model and request path.
+func (r *BodyRule) Applies(model, requestPath string) bool {
+	return matchesAnyPattern(r.Models, model) && matchesAnyPattern(r.Paths, requestPath)
+}
+
+// matchesAnyPattern reports whether the value matches one of the glob patterns. An empty pattern list matches everything.
+func matchesAnyPattern(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, value); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
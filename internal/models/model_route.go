@@ -0,0 +1,41 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// ModelRoute 将模型映射到分组，统一入口 /v1/* 根据请求中的模型按路由表选择分组
+type ModelRoute struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Model     string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_model_routes_model_group" json:"model"` // 精确模型名或通配模式，如 "gpt-4o*"
+	GroupID   uint      `gorm:"not null;uniqueIndex:idx_model_routes_model_group;index" json:"group_id"`
+	Priority  int       `gorm:"not null;default:0" json:"priority"` // 数值越小越优先
+	IsActive  bool      `gorm:"not null" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsPattern reports whether the route model contains glob wildcards.
+func (r *ModelRoute) IsPattern() bool {
+	return strings.ContainsAny(r.Model, "*?[")
+}
+
+// Matches reports whether the given model is served by this route.
+func (r *ModelRoute) Matches(model string) bool {
+	if model == "" {
+		return false
+	}
+	if !r.IsPattern() {
+		return r.Model == model
+	}
+	matched, err := path.Match(r.Model, model)
+	return err == nil && matched
+}
+
+// ValidateModelRoutePattern checks that the route model is a valid exact name or glob pattern.
+func ValidateModelRoutePattern(model string) error {
+	_, err := path.Match(model, "")
+	return err
+}
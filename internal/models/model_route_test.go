@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelRouteMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		model   string
+		want    bool
+	}{
+		{"exact match", "gpt-4o", "gpt-4o", true},
+		{"exact mismatch", "gpt-4o", "gpt-4o-mini", false},
+		{"wildcard suffix", "gpt-4o*", "gpt-4o-mini", true},
+		{"wildcard mismatch", "claude-*", "gpt-4o", false},
+		{"single character", "gemini-?.5-pro", "gemini-2.5-pro", true},
+		{"empty model", "*", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &ModelRoute{Model: tt.pattern}
+			assert.Equal(t, tt.want, route.Matches(tt.model))
+		})
+	}
+}
+
+func TestValidateModelRoutePattern(t *testing.T) {
+	assert.NoError(t, ValidateModelRoutePattern("gpt-4o"))
+	assert.NoError(t, ValidateModelRoutePattern("gpt-*"))
+	assert.Error(t, ValidateModelRoutePattern("gpt-[4"))
+}
+
+func TestGroupHasProxyKey(t *testing.T) {
+	group := &Group{ProxyKeysMap: map[string]struct{}{"sk-group": {}}}
+	group.EffectiveConfig.ProxyKeysMap = map[string]struct{}{"sk-global": {}}
+
+	assert.True(t, group.HasProxyKey("sk-group"))
+	assert.True(t, group.HasProxyKey("sk-global"))
+	assert.False(t, group.HasProxyKey("sk-other"))
+}
@@ -24,6 +24,13 @@ const (
 	RetryActionInvalidate = "invalidate" // 标记无效
 )
 
+// PolicyErrorType 策略评估上下文中的错误类型
+const (
+	PolicyErrorTypeNetwork  = "network"  // 网络错误
+	PolicyErrorTypeTimeout  = "timeout"  // 请求超时
+	PolicyErrorTypeUpstream = "upstream" // 上游返回错误状态码
+)
+
 // Policy 策略模型
 type Policy struct {
 	ID          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -91,36 +98,45 @@ type ModelFilterPolicyConfig struct {
 	Patterns []string `json:"patterns"`
 }
 
+// RateLimitScope 限流计数的作用范围
+const (
+	RateLimitScopeGroup    = "group"     // 分组内所有请求共享额度
+	RateLimitScopeProxyKey = "proxy_key" // 每个代理密钥单独计数
+)
+
 // RateLimitPolicyConfig 限流策略配置
 type RateLimitPolicyConfig struct {
 	Limit    int64  `json:"limit"`
-	Interval string `json:"interval"` // e.g., "1s", "1m", "1h"
+	Interval string `json:"interval"`        // e.g., "1s", "1m", "1h"
+	Scope    string `json:"scope,omitempty"` // group (default) or proxy_key
 }
 
 // PolicyEvaluationContext 策略评估上下文
 type PolicyEvaluationContext struct {
-	GroupID       uint
-	KeyID         uint
-	Model         string
-	StatusCode    int
-	ErrorMessage  string
-	ErrorType     string
-	FailureCount  int64
-	RequestCount  int64
+	GroupID      uint   `json:"group_id"`
+	KeyID        uint   `json:"key_id"`
+	Model        string `json:"model"`
+	StatusCode   int    `json:"status_code"`
+	ErrorMessage string `json:"error_message"`
+	ErrorType    string `json:"error_type"`
+	FailureCount int64  `json:"failure_count"`
+	RequestCount int64  `json:"request_count"`
 }
 
 // PolicyEvaluationResult 策略评估结果
 type PolicyEvaluationResult struct {
-	PolicyID   uint
-	PolicyName string
-	RuleName   string
-	Action     string
-	MaxRetries int
-	BackoffMs  int
-	Duration   string
-	Priority   int
-	Reason     string
-	Matched    bool
+	PolicyID   uint             `json:"policy_id"`
+	PolicyName string           `json:"policy_name"`
+	PolicyType string           `json:"policy_type"`
+	RuleName   string           `json:"rule_name"`
+	Action     string           `json:"action"`
+	MaxRetries int              `json:"max_retries"`
+	BackoffMs  int              `json:"backoff_ms"`
+	Duration   string           `json:"duration"`
+	Priority   int              `json:"priority"`
+	Reason     string           `json:"reason"`
+	Conditions []RetryCondition `json:"conditions,omitempty"`
+	Matched    bool             `json:"matched"`
 }
 
 // GetRetryConfig 从 Policy.Config 中解析重试策略配置
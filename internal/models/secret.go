@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Secret 对应 secrets 表，保存加密的值，头部规则通过 ${SECRET:name} 引用
+type Secret struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Value       string    `gorm:"type:text;not null" json:"-"` // 加密存储，不通过接口返回
+	Description string    `gorm:"type:varchar(512)" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -10,6 +10,7 @@ type SystemSettingInfo struct {
 	Description  string   `json:"description"`
 	Category     string   `json:"category"`
 	MinValue     *int     `json:"min_value,omitempty"`
+	Options      []string `json:"options,omitempty"`
 	Required     bool     `json:"required"`
 }
 
@@ -26,11 +26,18 @@ type GroupConfig struct {
 	MaxIdleConnsPerHost          *int    `json:"max_idle_conns_per_host,omitempty"`
 	ResponseHeaderTimeout        *int    `json:"response_header_timeout,omitempty"`
 	ProxyURL                     *string `json:"proxy_url,omitempty"`
+	UpstreamSelectionStrategy    *string `json:"upstream_selection_strategy,omitempty"`
+	UpstreamFailureThreshold     *int    `json:"upstream_failure_threshold,omitempty"`
+	UpstreamCooloffSeconds       *int    `json:"upstream_cooloff_seconds,omitempty"`
 	MaxRetries                   *int    `json:"max_retries,omitempty"`
 	BlacklistThreshold           *int    `json:"blacklist_threshold,omitempty"`
 	KeyValidationIntervalMinutes *int    `json:"key_validation_interval_minutes,omitempty"`
 	KeyValidationConcurrency     *int    `json:"key_validation_concurrency,omitempty"`
 	KeyValidationTimeoutSeconds  *int    `json:"key_validation_timeout_seconds,omitempty"`
+	KeySelectionStrategy         *string `json:"key_selection_strategy,omitempty"`
+	MaxConcurrentPerKey          *int    `json:"max_concurrent_per_key,omitempty"`
+	QuotaResetTime               *string `json:"quota_reset_time,omitempty"`
+	QuotaResetTimezone           *string `json:"quota_reset_timezone,omitempty"`
 	EnableRequestBodyLogging     *bool   `json:"enable_request_body_logging,omitempty"`
 }
 
@@ -43,29 +50,45 @@ type HeaderRule struct {
 
 // Group 对应 groups 表
 type Group struct {
-	ID                 uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
-	EffectiveConfig    types.SystemSettings `gorm:"-" json:"effective_config,omitempty"`
-	Name               string               `gorm:"type:varchar(255);not null;unique" json:"name"`
-	Endpoint           string               `gorm:"-" json:"endpoint"`
-	DisplayName        string               `gorm:"type:varchar(255)" json:"display_name"`
-	ProxyKeys          string               `gorm:"type:text" json:"proxy_keys"`
-	Description        string               `gorm:"type:varchar(512)" json:"description"`
-	Upstreams          datatypes.JSON       `gorm:"type:json;not null" json:"upstreams"`
-	ValidationEndpoint string               `gorm:"type:varchar(255)" json:"validation_endpoint"`
-	ChannelType        string               `gorm:"type:varchar(50);not null" json:"channel_type"`
-	Sort               int                  `gorm:"default:0" json:"sort"`
-	TestModel          string               `gorm:"type:varchar(255);not null" json:"test_model"`
-	ParamOverrides     datatypes.JSONMap    `gorm:"type:json" json:"param_overrides"`
-	Config             datatypes.JSONMap    `gorm:"type:json" json:"config"`
-	HeaderRules        datatypes.JSON       `gorm:"type:json" json:"header_rules"`
-	APIKeys            []APIKey             `gorm:"foreignKey:GroupID" json:"api_keys"`
-	LastValidatedAt    *time.Time           `json:"last_validated_at"`
-	CreatedAt          time.Time            `json:"created_at"`
-	UpdatedAt          time.Time            `json:"updated_at"`
+	ID                  uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	EffectiveConfig     types.SystemSettings `gorm:"-" json:"effective_config,omitempty"`
+	Name                string               `gorm:"type:varchar(255);not null;unique" json:"name"`
+	Endpoint            string               `gorm:"-" json:"endpoint"`
+	DisplayName         string               `gorm:"type:varchar(255)" json:"display_name"`
+	ProxyKeys           string               `gorm:"type:text" json:"proxy_keys"`
+	Description         string               `gorm:"type:varchar(512)" json:"description"`
+	Upstreams           datatypes.JSON       `gorm:"type:json;not null" json:"upstreams"`
+	ValidationEndpoint  string               `gorm:"type:varchar(255)" json:"validation_endpoint"`
+	ChannelType         string               `gorm:"type:varchar(50);not null" json:"channel_type"`
+	Sort                int                  `gorm:"default:0" json:"sort"`
+	TestModel           string               `gorm:"type:varchar(255);not null" json:"test_model"`
+	ParamOverrides      datatypes.JSONMap    `gorm:"type:json" json:"param_overrides"`
+	ModelAliases        datatypes.JSONMap    `gorm:"type:json" json:"model_aliases"` // 模型别名表：客户端模型名 -> 上游真实模型名
+	Config              datatypes.JSONMap    `gorm:"type:json" json:"config"`
+	ChannelConfig       datatypes.JSONMap    `gorm:"type:json" json:"channel_config"` // 渠道类型专属配置，由渠道自行解析
+	HeaderRules         datatypes.JSON       `gorm:"type:json" json:"header_rules"`
+	ResponseHeaderRules datatypes.JSON       `gorm:"type:json" json:"response_header_rules"` // 应用于返回给客户端的响应头
+	BodyRules           datatypes.JSON       `gorm:"type:json" json:"body_rules"`            // 有序的请求体改写规则
+	FallbackGroups      datatypes.JSON       `gorm:"type:json" json:"fallback_groups"`       // 有序的回退分组 ID 列表
+	APIKeys             []APIKey             `gorm:"foreignKey:GroupID" json:"api_keys"`
+	LastValidatedAt     *time.Time           `json:"last_validated_at"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
 
 	// For cache
-	ProxyKeysMap   map[string]struct{} `gorm:"-" json:"-"`
-	HeaderRuleList []HeaderRule        `gorm:"-" json:"-"`
+	ProxyKeysMap           map[string]struct{} `gorm:"-" json:"-"`
+	HeaderRuleList         []HeaderRule        `gorm:"-" json:"-"`
+	ResponseHeaderRuleList []HeaderRule        `gorm:"-" json:"-"`
+	BodyRuleList           []BodyRule          `gorm:"-" json:"-"`
+	FallbackGroupIDs       []uint              `gorm:"-" json:"-"`
+}
+
+// HasProxyKey reports whether the key is accepted by the group, checking both the effective and the group's own proxy keys.
+func (g *Group) HasProxyKey(key string) bool {
+	// Check both key collections to prevent timing attacks
+	_, existsInEffective := g.EffectiveConfig.ProxyKeysMap[key]
+	_, existsInGroup := g.ProxyKeysMap[key]
+	return existsInEffective || existsInGroup
 }
 
 // APIKey 对应 api_keys 表
@@ -77,6 +100,7 @@ type APIKey struct {
 	Status       string     `gorm:"type:varchar(50);not null;default:'pending'" json:"status"` // Default to 'pending'
 	RequestCount int64      `gorm:"not null;default:0" json:"request_count"`
 	FailureCount int64      `gorm:"not null;default:0" json:"failure_count"`
+	Weight       int        `gorm:"not null;default:1" json:"weight"` // 加权选择策略下的权重
 	LastUsedAt   *time.Time `json:"last_used_at"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
@@ -86,6 +110,7 @@ type APIKey struct {
 	LastSuccessAt       *time.Time `json:"last_success_at"`
 	LastValidatedAt     *time.Time `json:"last_validated_at"`
 	DisabledUntil       *time.Time `json:"disabled_until"`
+	QuotaResetAt        *time.Time `json:"quota_reset_at"` // 因额度耗尽停用时，计划放回池中的时间
 	ConsecutiveFailures int64      `gorm:"not null;default:0" json:"consecutive_failures"`
 	LastErrorMessage    string     `gorm:"type:text" json:"last_error_message"`
 	BackoffLevel        int        `gorm:"not null;default:0" json:"backoff_level"`
@@ -98,25 +123,28 @@ const (
 )
 
 // RequestLog 对应 request_logs 表
+// GroupID/GroupName 为实际处理本次请求的分组，RequestedGroupName 为客户端请求的入口分组，发生跨分组回退时二者不同
 type RequestLog struct {
-	ID           string    `gorm:"type:varchar(36);primaryKey" json:"id"`
-	Timestamp    time.Time `gorm:"not null;index" json:"timestamp"`
-	GroupID      uint      `gorm:"not null;index" json:"group_id"`
-	GroupName    string    `gorm:"type:varchar(255);index" json:"group_name"`
-	KeyValue     string    `gorm:"type:text" json:"key_value"`
-	KeyHash      string    `gorm:"type:varchar(128);index" json:"key_hash"`
-	Model        string    `gorm:"type:varchar(255);index" json:"model"`
-	IsSuccess    bool      `gorm:"not null" json:"is_success"`
-	SourceIP     string    `gorm:"type:varchar(64)" json:"source_ip"`
-	StatusCode   int       `gorm:"not null" json:"status_code"`
-	RequestPath  string    `gorm:"type:varchar(500)" json:"request_path"`
-	Duration     int64     `gorm:"not null" json:"duration_ms"`
-	ErrorMessage string    `gorm:"type:text" json:"error_message"`
-	UserAgent    string    `gorm:"type:varchar(512)" json:"user_agent"`
-	RequestType  string    `gorm:"type:varchar(20);not null;default:'final';index" json:"request_type"`
-	UpstreamAddr string    `gorm:"type:varchar(500)" json:"upstream_addr"`
-	IsStream     bool      `gorm:"not null" json:"is_stream"`
-	RequestBody  string    `gorm:"type:text" json:"request_body"`
+	ID                 string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Timestamp          time.Time `gorm:"not null;index" json:"timestamp"`
+	GroupID            uint      `gorm:"not null;index" json:"group_id"`
+	GroupName          string    `gorm:"type:varchar(255);index" json:"group_name"`
+	RequestedGroupName string    `gorm:"type:varchar(255)" json:"requested_group_name"`
+	KeyValue           string    `gorm:"type:text" json:"key_value"`
+	KeyHash            string    `gorm:"type:varchar(128);index" json:"key_hash"`
+	Model              string    `gorm:"type:varchar(255);index" json:"model"`          // 客户端请求的模型名
+	UpstreamModel      string    `gorm:"type:varchar(255);index" json:"upstream_model"` // 经模型别名改写后实际发往上游的模型名
+	IsSuccess          bool      `gorm:"not null" json:"is_success"`
+	SourceIP           string    `gorm:"type:varchar(64)" json:"source_ip"`
+	StatusCode         int       `gorm:"not null" json:"status_code"`
+	RequestPath        string    `gorm:"type:varchar(500)" json:"request_path"`
+	Duration           int64     `gorm:"not null" json:"duration_ms"`
+	ErrorMessage       string    `gorm:"type:text" json:"error_message"`
+	UserAgent          string    `gorm:"type:varchar(512)" json:"user_agent"`
+	RequestType        string    `gorm:"type:varchar(20);not null;default:'final';index" json:"request_type"`
+	UpstreamAddr       string    `gorm:"type:varchar(500)" json:"upstream_addr"`
+	IsStream           bool      `gorm:"not null" json:"is_stream"`
+	RequestBody        string    `gorm:"type:text" json:"request_body"`
 }
 
 // StatCard 用于仪表盘的单个统计卡片数据
@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"sort"
@@ -8,21 +9,107 @@ import (
 	"strings"
 
 	"gpt-load/internal/models"
+	"gpt-load/internal/store"
+	"gpt-load/internal/syncer"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+const PolicyUpdateChannel = "policies:updated"
+
 // PolicyEngine 策略引擎
 type PolicyEngine struct {
-	db *gorm.DB
+	db     *gorm.DB
+	store  store.Store
+	syncer *syncer.CacheSyncer[map[uint][]models.GroupPolicy]
 }
 
 // NewPolicyEngine 创建新的策略引擎
-func NewPolicyEngine(db *gorm.DB) *PolicyEngine {
+func NewPolicyEngine(db *gorm.DB, store store.Store) *PolicyEngine {
 	return &PolicyEngine{
-		db: db,
+		db:    db,
+		store: store,
+	}
+}
+
+// Initialize 加载各分组已启用的策略并通过 CacheSyncer 在实例间同步，之后代理路径不再查询数据库
+func (pe *PolicyEngine) Initialize() error {
+	loader := func() (map[uint][]models.GroupPolicy, error) {
+		var groupPolicies []models.GroupPolicy
+		if err := pe.activeGroupPoliciesQuery().Find(&groupPolicies).Error; err != nil {
+			return nil, fmt.Errorf("failed to load group policies from db: %w", err)
+		}
+
+		byGroup := make(map[uint][]models.GroupPolicy)
+		for _, groupPolicy := range groupPolicies {
+			byGroup[groupPolicy.GroupID] = append(byGroup[groupPolicy.GroupID], groupPolicy)
+		}
+		for _, policies := range byGroup {
+			sortGroupPolicies(policies)
+		}
+		return byGroup, nil
+	}
+
+	syncer, err := syncer.NewCacheSyncer(
+		loader,
+		pe.store,
+		PolicyUpdateChannel,
+		logrus.WithField("syncer", "policies"),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create policy syncer: %w", err)
+	}
+	pe.syncer = syncer
+	return nil
+}
+
+// Invalidate 通知所有实例重新加载策略缓存
+func (pe *PolicyEngine) Invalidate() error {
+	if pe.syncer == nil {
+		return fmt.Errorf("PolicyEngine is not initialized")
+	}
+	return pe.syncer.Invalidate()
+}
+
+// Stop 停止策略缓存的后台同步
+func (pe *PolicyEngine) Stop(ctx context.Context) {
+	if pe.syncer != nil {
+		pe.syncer.Stop()
+	}
+}
+
+// activeGroupPoliciesQuery 查询绑定和策略均已启用的分组策略
+func (pe *PolicyEngine) activeGroupPoliciesQuery() *gorm.DB {
+	return pe.db.Preload("Policy").
+		Joins("JOIN policies ON policies.id = group_policies.policy_id").
+		Where("group_policies.is_active = ? AND policies.is_active = ?", true, true)
+}
+
+// activeGroupPolicies 返回分组已启用的策略，按优先级排序。调用方不得修改返回的切片，
+// 它在初始化后直接来自缓存；未初始化时（如测试中）回退到数据库查询
+func (pe *PolicyEngine) activeGroupPolicies(groupID uint) ([]models.GroupPolicy, error) {
+	if pe.syncer != nil {
+		return pe.syncer.Get()[groupID], nil
+	}
+
+	var groupPolicies []models.GroupPolicy
+	if err := pe.activeGroupPoliciesQuery().Where("group_policies.group_id = ?", groupID).Find(&groupPolicies).Error; err != nil {
+		return nil, fmt.Errorf("failed to query group policies: %w", err)
 	}
+	sortGroupPolicies(groupPolicies)
+	return groupPolicies, nil
+}
+
+// sortGroupPolicies 按优先级排序，优先级相同时按策略 ID
+func sortGroupPolicies(policies []models.GroupPolicy) {
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Priority != policies[j].Priority {
+			return policies[i].Priority < policies[j].Priority
+		}
+		return policies[i].PolicyID < policies[j].PolicyID
+	})
 }
 
 // EvaluatePolicies 评估分组的所有策略
@@ -165,29 +252,70 @@ func (pe *PolicyEngine) EvaluateModelFilterPolicies(groupID uint, model string)
 	return true, nil
 }
 
-// GetGroupPolicies 获取分组的所有策略
-func (pe *PolicyEngine) GetGroupPolicies(groupID uint) ([]models.GroupPolicy, error) {
-	var groupPolicies []models.GroupPolicy
+// SimulatePolicies 预演分组策略，仅返回匹配的结果；includeInactive 为 true 时同时评估未启用的策略和绑定
+func (pe *PolicyEngine) SimulatePolicies(groupID uint, context *models.PolicyEvaluationContext, includeInactive bool) ([]*models.PolicyEvaluationResult, error) {
+	var evaluated []*models.PolicyEvaluationResult
 
-	if err := pe.db.Preload("Policy").Where("group_id = ? AND is_active = ?", groupID, true).Find(&groupPolicies).Error; err != nil {
-		return nil, fmt.Errorf("failed to query group policies: %w", err)
+	if includeInactive {
+		var policies []models.GroupPolicy
+		if err := pe.db.Preload("Policy").Where("group_id = ?", groupID).Find(&policies).Error; err != nil {
+			return nil, fmt.Errorf("failed to query group policies: %w", err)
+		}
+
+		sort.Slice(policies, func(i, j int) bool {
+			return policies[i].Priority < policies[j].Priority
+		})
+
+		for _, groupPolicy := range policies {
+			result, err := pe.evaluatePolicy(&groupPolicy.Policy, context)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{"policy_id": groupPolicy.PolicyID, "group_id": groupID, "error": err}).Warn("Failed to evaluate policy during simulation")
+				continue
+			}
+			if result != nil {
+				result.Priority = groupPolicy.Priority
+				evaluated = append(evaluated, result)
+			}
+		}
+	} else {
+		var err error
+		if evaluated, err = pe.EvaluatePolicies(groupID, context); err != nil {
+			return nil, err
+		}
 	}
 
-	return groupPolicies, nil
+	results := make([]*models.PolicyEvaluationResult, 0, len(evaluated))
+	for _, result := range evaluated {
+		if result.Matched {
+			results = append(results, result)
+		}
+	}
+	return results, nil
 }
 
-// GetGroupPoliciesByType 获取分组指定类型的策略
-func (pe *PolicyEngine) GetGroupPoliciesByType(groupID uint, policyType string) ([]models.GroupPolicy, error) {
-	var groupPolicies []models.GroupPolicy
+// GetGroupPolicies 获取分组的所有已启用策略，返回副本可由调用方修改
+func (pe *PolicyEngine) GetGroupPolicies(groupID uint) ([]models.GroupPolicy, error) {
+	policies, err := pe.activeGroupPolicies(groupID)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := pe.db.Preload("Policy").
-		Joins("JOIN policies ON policies.id = group_policies.policy_id").
-		Where("group_policies.group_id = ? AND group_policies.is_active = ? AND policies.type = ? AND policies.is_active = ?",
-			groupID, true, policyType, true).
-		Find(&groupPolicies).Error; err != nil {
-		return nil, fmt.Errorf("failed to query group policies by type: %w", err)
+	return append([]models.GroupPolicy(nil), policies...), nil
+}
+
+// GetGroupPoliciesByType 获取分组指定类型的已启用策略，返回副本可由调用方修改
+func (pe *PolicyEngine) GetGroupPoliciesByType(groupID uint, policyType string) ([]models.GroupPolicy, error) {
+	policies, err := pe.activeGroupPolicies(groupID)
+	if err != nil {
+		return nil, err
 	}
 
+	var groupPolicies []models.GroupPolicy
+	for _, groupPolicy := range policies {
+		if groupPolicy.Policy.Type == policyType {
+			groupPolicies = append(groupPolicies, groupPolicy)
+		}
+	}
 	return groupPolicies, nil
 }
 
@@ -226,12 +354,14 @@ func (pe *PolicyEngine) evaluateRetryPolicy(policy *models.Policy, context *mode
 			return &models.PolicyEvaluationResult{
 				PolicyID:   policy.ID,
 				PolicyName: policy.Name,
+				PolicyType: policy.Type,
 				RuleName:   rule.Name,
 				Action:     rule.Action,
 				MaxRetries: rule.MaxRetries,
 				BackoffMs:  rule.BackoffMs,
 				Priority:   rule.Priority,
 				Reason:     fmt.Sprintf("Matched rule: %s", rule.Name),
+				Conditions: rule.Conditions,
 				Matched:    true,
 			}, nil
 		}
@@ -240,6 +370,7 @@ func (pe *PolicyEngine) evaluateRetryPolicy(policy *models.Policy, context *mode
 	return &models.PolicyEvaluationResult{
 		PolicyID:   policy.ID,
 		PolicyName: policy.Name,
+		PolicyType: policy.Type,
 		Matched:    false,
 	}, nil
 }
@@ -262,11 +393,13 @@ func (pe *PolicyEngine) evaluateDegradationPolicy(policy *models.Policy, context
 			return &models.PolicyEvaluationResult{
 				PolicyID:   policy.ID,
 				PolicyName: policy.Name,
+				PolicyType: policy.Type,
 				RuleName:   rule.Name,
 				Action:     rule.Action,
 				Duration:   rule.Duration,
 				Priority:   rule.Priority,
 				Reason:     fmt.Sprintf("Matched degradation rule: %s", rule.Name),
+				Conditions: rule.Conditions,
 				Matched:    true,
 			}, nil
 		}
@@ -275,6 +408,7 @@ func (pe *PolicyEngine) evaluateDegradationPolicy(policy *models.Policy, context
 	return &models.PolicyEvaluationResult{
 		PolicyID:   policy.ID,
 		PolicyName: policy.Name,
+		PolicyType: policy.Type,
 		Matched:    false,
 	}, nil
 }
@@ -324,6 +458,7 @@ func (pe *PolicyEngine) evaluateRateLimitPolicy(policy *models.Policy, context *
 	return &models.PolicyEvaluationResult{
 		PolicyID:   policy.ID,
 		PolicyName: policy.Name,
+		PolicyType: policy.Type,
 		Matched:    false,
 	}, nil
 }
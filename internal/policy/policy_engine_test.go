@@ -1,10 +1,12 @@
 package policy
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
 	"gpt-load/internal/models"
+	"gpt-load/internal/store"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,7 +22,7 @@ func setupTestPolicyEngine(t *testing.T) (*PolicyEngine, *gorm.DB) {
 	err = db.AutoMigrate(&models.Policy{}, &models.GroupPolicy{}, &models.Group{})
 	require.NoError(t, err)
 
-	engine := NewPolicyEngine(db)
+	engine := NewPolicyEngine(db, store.NewMemoryStore())
 	return engine, db
 }
 
@@ -429,3 +431,72 @@ func TestPolicyEngine_GetGroupPoliciesByType(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, rateLimitPolicies, 0)
 }
+
+func TestPolicyEngine_CachedGroupPolicies(t *testing.T) {
+	engine, db := setupTestPolicyEngine(t)
+
+	group := createTestGroup(t, db)
+	retryPolicy := createTestRetryPolicy(t, db)
+	filterPolicy := createTestModelFilterPolicy(t, db)
+	require.NoError(t, db.Create(&models.GroupPolicy{GroupID: group.ID, PolicyID: retryPolicy.ID, Priority: 2, IsActive: true}).Error)
+	require.NoError(t, db.Create(&models.GroupPolicy{GroupID: group.ID, PolicyID: filterPolicy.ID, Priority: 1, IsActive: true}).Error)
+
+	require.NoError(t, engine.Initialize())
+	defer engine.Stop(context.Background())
+
+	policies, err := engine.GetGroupPolicies(group.ID)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, filterPolicy.ID, policies[0].PolicyID)
+
+	// 缓存生效后数据库变更在重新加载前不可见
+	require.NoError(t, db.Model(&models.GroupPolicy{}).Where("policy_id = ?", retryPolicy.ID).Update("is_active", false).Error)
+	retryPolicies, err := engine.GetGroupPoliciesByType(group.ID, models.PolicyTypeRetry)
+	require.NoError(t, err)
+	assert.Len(t, retryPolicies, 1)
+
+	// 返回副本，调用方修改不影响缓存
+	policies[0], policies[1] = policies[1], policies[0]
+	cached, err := engine.GetGroupPolicies(group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, filterPolicy.ID, cached[0].PolicyID)
+}
+
+func TestPolicyEngine_SimulatePolicies(t *testing.T) {
+	engine, db := setupTestPolicyEngine(t)
+
+	group := createTestGroup(t, db)
+	retryPolicy := createTestRetryPolicy(t, db)
+
+	binding := &models.GroupPolicy{GroupID: group.ID, PolicyID: retryPolicy.ID, Priority: 1, IsActive: true}
+	require.NoError(t, db.Create(binding).Error)
+
+	context := &models.PolicyEvaluationContext{GroupID: group.ID, StatusCode: 429}
+
+	t.Run("returns matched results with rule details", func(t *testing.T) {
+		results, err := engine.SimulatePolicies(group.ID, context, false)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "rate_limit_retry", results[0].RuleName)
+		assert.Equal(t, models.PolicyTypeRetry, results[0].PolicyType)
+		assert.Len(t, results[0].Conditions, 1)
+	})
+
+	t.Run("no match returns empty list", func(t *testing.T) {
+		results, err := engine.SimulatePolicies(group.ID, &models.PolicyEvaluationContext{StatusCode: 200}, false)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("inactive bindings only evaluated on request", func(t *testing.T) {
+		require.NoError(t, db.Model(&models.GroupPolicy{}).Where("group_id = ?", group.ID).Update("is_active", false).Error)
+
+		results, err := engine.SimulatePolicies(group.ID, context, false)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		results, err = engine.SimulatePolicies(group.ID, context, true)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+}
@@ -50,6 +50,17 @@ func (ps *PolicyService) GetPolicyByID(policyID uint) (*models.Policy, error) {
 	return &policy, err
 }
 
+// ListPolicies 获取所有策略，policyType 为空时不过滤类型
+func (ps *PolicyService) ListPolicies(policyType string) ([]models.Policy, error) {
+	var policies []models.Policy
+	query := ps.db.Order("priority asc, id asc")
+	if policyType != "" {
+		query = query.Where("type = ?", policyType)
+	}
+	err := query.Find(&policies).Error
+	return policies, err
+}
+
 // GetPoliciesByType 根据类型获取策略
 func (ps *PolicyService) GetPoliciesByType(policyType string) ([]models.Policy, error) {
 	var policies []models.Policy
@@ -68,6 +79,27 @@ func (ps *PolicyService) AddPolicyToGroup(groupID, policyID uint, priority int)
 	return ps.db.Create(assoc).Error
 }
 
+// UpdateGroupPolicy 更新分组策略关联的优先级和启用状态
+func (ps *PolicyService) UpdateGroupPolicy(groupID, policyID uint, priority int, isActive bool) error {
+	result := ps.db.Model(&models.GroupPolicy{}).
+		Where("group_id = ? AND policy_id = ?", groupID, policyID).
+		Updates(map[string]any{"priority": priority, "is_active": isActive})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListGroupPolicies 获取分组绑定的所有策略（包括未启用的）
+func (ps *PolicyService) ListGroupPolicies(groupID uint) ([]models.GroupPolicy, error) {
+	var groupPolicies []models.GroupPolicy
+	err := ps.db.Preload("Policy").Where("group_id = ?", groupID).Order("priority asc, policy_id asc").Find(&groupPolicies).Error
+	return groupPolicies, err
+}
+
 // RemovePolicyFromGroup 从分组移除策略
 func (ps *PolicyService) RemovePolicyFromGroup(groupID, policyID uint) error {
 	return ps.db.Where("group_id = ? AND policy_id = ?", groupID, policyID).Delete(&models.GroupPolicy{}).Error
@@ -0,0 +1,210 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gpt-load/internal/models"
+)
+
+// 支持的条件类型
+var validConditionTypes = map[string]bool{
+	"status_code":   true,
+	"error_message": true,
+	"error_type":    true,
+	"model":         true,
+	"failure_count": true,
+	"request_count": true,
+}
+
+// 支持的条件操作符
+var validOperators = map[string]bool{
+	"equals":   true,
+	"contains": true,
+	"regex":    true,
+	"in":       true,
+	"not_in":   true,
+	"gt":       true,
+	"lt":       true,
+	"gte":      true,
+	"lte":      true,
+}
+
+// 各策略类型允许的动作
+var (
+	validRetryActions = map[string]bool{
+		models.RetryActionRetry:      true,
+		models.RetryActionDegrade:    true,
+		models.RetryActionDisable:    true,
+		models.RetryActionInvalidate: true,
+	}
+	validDegradationActions = map[string]bool{
+		models.RetryActionDegrade:    true,
+		models.RetryActionDisable:    true,
+		models.RetryActionInvalidate: true,
+	}
+)
+
+// PolicyTypes 返回所有支持的策略类型
+func PolicyTypes() []string {
+	return []string{
+		models.PolicyTypeRetry,
+		models.PolicyTypeDegradation,
+		models.PolicyTypeModelFilter,
+		models.PolicyTypeRateLimit,
+	}
+}
+
+// IsValidPolicyType 检查策略类型是否受支持
+func IsValidPolicyType(policyType string) bool {
+	for _, t := range PolicyTypes() {
+		if t == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePolicyConfig 按策略类型校验 Policy.Config
+func ValidatePolicyConfig(policy *models.Policy) error {
+	if len(policy.Config) == 0 {
+		return fmt.Errorf("config is required")
+	}
+
+	switch policy.Type {
+	case models.PolicyTypeRetry:
+		config, err := policy.GetRetryConfig()
+		if err != nil {
+			return fmt.Errorf("invalid retry config: %w", err)
+		}
+		return validateRetryConfig(config)
+	case models.PolicyTypeDegradation:
+		config, err := policy.GetDegradationConfig()
+		if err != nil {
+			return fmt.Errorf("invalid degradation config: %w", err)
+		}
+		return validateDegradationConfig(config)
+	case models.PolicyTypeModelFilter:
+		config, err := policy.GetModelFilterConfig()
+		if err != nil {
+			return fmt.Errorf("invalid model filter config: %w", err)
+		}
+		return validateModelFilterConfig(config)
+	case models.PolicyTypeRateLimit:
+		config, err := policy.GetRateLimitConfig()
+		if err != nil {
+			return fmt.Errorf("invalid rate limit config: %w", err)
+		}
+		return validateRateLimitConfig(config)
+	default:
+		return fmt.Errorf("unknown policy type: %s", policy.Type)
+	}
+}
+
+func validateRetryConfig(config *models.RetryPolicyConfig) error {
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	for i, rule := range config.Rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("rule #%d: name is required", i+1)
+		}
+		if !validRetryActions[rule.Action] {
+			return fmt.Errorf("rule '%s': invalid action '%s'", rule.Name, rule.Action)
+		}
+		if rule.MaxRetries < 0 {
+			return fmt.Errorf("rule '%s': max_retries must be non-negative", rule.Name)
+		}
+		if rule.BackoffMs < 0 {
+			return fmt.Errorf("rule '%s': backoff_ms must be non-negative", rule.Name)
+		}
+		if err := validateConditions(rule.Conditions); err != nil {
+			return fmt.Errorf("rule '%s': %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateDegradationConfig(config *models.DegradationPolicyConfig) error {
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	for i, rule := range config.Rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("rule #%d: name is required", i+1)
+		}
+		if !validDegradationActions[rule.Action] {
+			return fmt.Errorf("rule '%s': invalid action '%s'", rule.Name, rule.Action)
+		}
+		if rule.Duration != "" {
+			if d, err := time.ParseDuration(rule.Duration); err != nil || d <= 0 {
+				return fmt.Errorf("rule '%s': invalid duration '%s'", rule.Name, rule.Duration)
+			}
+		}
+		if err := validateConditions(rule.Conditions); err != nil {
+			return fmt.Errorf("rule '%s': %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateModelFilterConfig(config *models.ModelFilterPolicyConfig) error {
+	if config.Type != "include" && config.Type != "exclude" {
+		return fmt.Errorf("type must be 'include' or 'exclude'")
+	}
+	if len(config.Patterns) == 0 {
+		return fmt.Errorf("at least one pattern is required")
+	}
+	for _, pattern := range config.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateRateLimitConfig(config *models.RateLimitPolicyConfig) error {
+	if config.Limit <= 0 {
+		return fmt.Errorf("limit must be greater than 0")
+	}
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid interval '%s'", config.Interval)
+	}
+	switch config.Scope {
+	case "", models.RateLimitScopeGroup, models.RateLimitScopeProxyKey:
+	default:
+		return fmt.Errorf("scope must be '%s' or '%s'", models.RateLimitScopeGroup, models.RateLimitScopeProxyKey)
+	}
+	return nil
+}
+
+func validateConditions(conditions []models.RetryCondition) error {
+	for _, condition := range conditions {
+		if !validConditionTypes[condition.Type] {
+			return fmt.Errorf("invalid condition type '%s'", condition.Type)
+		}
+		if !validOperators[condition.Operator] {
+			return fmt.Errorf("invalid operator '%s'", condition.Operator)
+		}
+
+		switch condition.Operator {
+		case "in", "not_in":
+			if len(condition.Values) == 0 {
+				return fmt.Errorf("operator '%s' requires values", condition.Operator)
+			}
+		case "regex":
+			if _, err := regexp.Compile(condition.Value); err != nil {
+				return fmt.Errorf("invalid regex '%s': %w", condition.Value, err)
+			}
+		case "gt", "lt", "gte", "lte":
+			if _, err := strconv.ParseFloat(condition.Value, 64); err != nil {
+				return fmt.Errorf("operator '%s' requires a numeric value", condition.Operator)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"testing"
+
+	"gpt-load/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePolicyConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		policyType string
+		config     string
+		wantErr    bool
+	}{
+		{
+			name:       "valid retry policy",
+			policyType: models.PolicyTypeRetry,
+			config:     `{"rules":[{"name":"r1","conditions":[{"type":"status_code","operator":"equals","value":"429"}],"action":"retry","max_retries":2,"backoff_ms":100}]}`,
+		},
+		{
+			name:       "retry policy without rules",
+			policyType: models.PolicyTypeRetry,
+			config:     `{"rules":[]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "retry policy with unknown action",
+			policyType: models.PolicyTypeRetry,
+			config:     `{"rules":[{"name":"r1","action":"explode"}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "retry policy with unknown condition type",
+			policyType: models.PolicyTypeRetry,
+			config:     `{"rules":[{"name":"r1","conditions":[{"type":"latency","operator":"gt","value":"1"}],"action":"retry"}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "in operator without values",
+			policyType: models.PolicyTypeRetry,
+			config:     `{"rules":[{"name":"r1","conditions":[{"type":"status_code","operator":"in"}],"action":"retry"}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "numeric operator with non-numeric value",
+			policyType: models.PolicyTypeRetry,
+			config:     `{"rules":[{"name":"r1","conditions":[{"type":"failure_count","operator":"gt","value":"many"}],"action":"retry"}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "valid degradation policy",
+			policyType: models.PolicyTypeDegradation,
+			config:     `{"rules":[{"name":"d1","conditions":[{"type":"status_code","operator":"in","values":["500","502"]}],"action":"disable","duration":"5m"}]}`,
+		},
+		{
+			name:       "degradation policy with invalid duration",
+			policyType: models.PolicyTypeDegradation,
+			config:     `{"rules":[{"name":"d1","action":"disable","duration":"soon"}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "degradation policy with retry action",
+			policyType: models.PolicyTypeDegradation,
+			config:     `{"rules":[{"name":"d1","action":"retry"}]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "valid model filter policy",
+			policyType: models.PolicyTypeModelFilter,
+			config:     `{"type":"exclude","patterns":["^o1-.*"]}`,
+		},
+		{
+			name:       "model filter policy with invalid regex",
+			policyType: models.PolicyTypeModelFilter,
+			config:     `{"type":"include","patterns":["gpt-(4"]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "model filter policy with unknown type",
+			policyType: models.PolicyTypeModelFilter,
+			config:     `{"type":"allow","patterns":["gpt-4"]}`,
+			wantErr:    true,
+		},
+		{
+			name:       "valid rate limit policy",
+			policyType: models.PolicyTypeRateLimit,
+			config:     `{"limit":60,"interval":"1m"}`,
+		},
+		{
+			name:       "rate limit policy with zero limit",
+			policyType: models.PolicyTypeRateLimit,
+			config:     `{"limit":0,"interval":"1m"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "valid per proxy key rate limit policy",
+			policyType: models.PolicyTypeRateLimit,
+			config:     `{"limit":10,"interval":"1s","scope":"proxy_key"}`,
+		},
+		{
+			name:       "rate limit policy with unknown scope",
+			policyType: models.PolicyTypeRateLimit,
+			config:     `{"limit":10,"interval":"1s","scope":"user"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "malformed json",
+			policyType: models.PolicyTypeRateLimit,
+			config:     `{"limit":`,
+			wantErr:    true,
+		},
+		{
+			name:       "unknown policy type",
+			policyType: "unknown",
+			config:     `{}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &models.Policy{Type: tt.policyType, Config: []byte(tt.config)}
+			err := ValidatePolicyConfig(policy)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIsValidPolicyType(t *testing.T) {
+	assert.True(t, IsValidPolicyType(models.PolicyTypeRetry))
+	assert.True(t, IsValidPolicyType(models.PolicyTypeRateLimit))
+	assert.False(t, IsValidPolicyType("unknown"))
+}
@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gpt-load/internal/models"
+	"gpt-load/internal/store"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitDecision 限流检查结果
+type RateLimitDecision struct {
+	Allowed    bool
+	PolicyName string
+	Limit      int64
+	Interval   time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimiter 基于 store.Store 令牌桶执行 rate_limit 策略，Redis 下多节点共享额度
+type RateLimiter struct {
+	engine *PolicyEngine
+	store  store.Store
+}
+
+// NewRateLimiter 创建限流器
+func NewRateLimiter(engine *PolicyEngine, store store.Store) *RateLimiter {
+	return &RateLimiter{
+		engine: engine,
+		store:  store,
+	}
+}
+
+// Allow 按优先级依次检查分组绑定的限流策略，任一策略超限即拒绝。策略取自策略引擎的缓存，不查询数据库
+func (rl *RateLimiter) Allow(groupID uint, proxyKey string) (*RateLimitDecision, error) {
+	policies, err := rl.engine.activeGroupPolicies(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate limit policies: %w", err)
+	}
+
+	for _, groupPolicy := range policies {
+		if groupPolicy.Policy.Type != models.PolicyTypeRateLimit {
+			continue
+		}
+
+		config, err := groupPolicy.Policy.GetRateLimitConfig()
+		if err != nil {
+			logrus.WithError(err).Warnf("Invalid rate limit policy '%s'", groupPolicy.Policy.Name)
+			continue
+		}
+		interval, err := time.ParseDuration(config.Interval)
+		if err != nil || interval <= 0 || config.Limit <= 0 {
+			logrus.Warnf("Invalid rate limit policy '%s': limit=%d, interval=%s", groupPolicy.Policy.Name, config.Limit, config.Interval)
+			continue
+		}
+
+		bucketKey := rateLimitBucketKey(groupPolicy.PolicyID, groupID, config.Scope, proxyKey)
+		allowed, retryAfter, err := rl.store.TakeToken(bucketKey, config.Limit, interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take rate limit token: %w", err)
+		}
+
+		if !allowed {
+			return &RateLimitDecision{
+				Allowed:    false,
+				PolicyName: groupPolicy.Policy.Name,
+				Limit:      config.Limit,
+				Interval:   interval,
+				RetryAfter: retryAfter,
+			}, nil
+		}
+	}
+
+	return &RateLimitDecision{Allowed: true}, nil
+}
+
+// rateLimitBucketKey 生成令牌桶在存储中的键，代理密钥只以哈希形式出现
+func rateLimitBucketKey(policyID, groupID uint, scope, proxyKey string) string {
+	key := fmt.Sprintf("ratelimit:%d:group:%d", policyID, groupID)
+	if scope == models.RateLimitScopeProxyKey && proxyKey != "" {
+		sum := sha256.Sum256([]byte(proxyKey))
+		key += ":pk:" + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gpt-load/internal/models"
+	"gpt-load/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	engine, db := setupTestPolicyEngine(t)
+
+	configBytes, err := json.Marshal(models.RateLimitPolicyConfig{Limit: 2, Interval: "1m", Scope: models.RateLimitScopeProxyKey})
+	require.NoError(t, err)
+
+	policy := &models.Policy{Name: "per-key-limit", Type: models.PolicyTypeRateLimit, Config: configBytes, IsActive: true}
+	require.NoError(t, db.Create(policy).Error)
+	require.NoError(t, db.Create(&models.GroupPolicy{GroupID: 1, PolicyID: policy.ID, IsActive: true}).Error)
+
+	limiter := NewRateLimiter(engine, store.NewMemoryStore())
+
+	for i := 0; i < 2; i++ {
+		decision, err := limiter.Allow(1, "sk-a")
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+
+	decision, err := limiter.Allow(1, "sk-a")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "per-key-limit", decision.PolicyName)
+	assert.Equal(t, time.Minute, decision.Interval)
+	assert.Greater(t, decision.RetryAfter, time.Duration(0))
+
+	// 其他代理密钥拥有独立额度
+	decision, err = limiter.Allow(1, "sk-b")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	// 未绑定限流策略的分组不受影响
+	decision, err = limiter.Allow(2, "sk-a")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestRateLimiter_UsesCachedPolicies(t *testing.T) {
+	engine, db := setupTestPolicyEngine(t)
+
+	configBytes, err := json.Marshal(models.RateLimitPolicyConfig{Limit: 1, Interval: "1m"})
+	require.NoError(t, err)
+
+	policy := &models.Policy{Name: "group-limit", Type: models.PolicyTypeRateLimit, Config: configBytes, IsActive: true}
+	require.NoError(t, db.Create(policy).Error)
+	require.NoError(t, db.Create(&models.GroupPolicy{GroupID: 1, PolicyID: policy.ID, IsActive: true}).Error)
+
+	require.NoError(t, engine.Initialize())
+	defer engine.Stop(context.Background())
+
+	// 初始化后的检查不再访问数据库
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	limiter := NewRateLimiter(engine, store.NewMemoryStore())
+
+	decision, err := limiter.Allow(1, "")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	decision, err = limiter.Allow(1, "")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "group-limit", decision.PolicyName)
+}
@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"gpt-load/internal/channel"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/keypool"
+	"gpt-load/internal/models"
+	"gpt-load/internal/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// fallbackChainKey is the gin context key holding the request's fallback chain.
+const fallbackChainKey = "fallbackChain"
+
+// fallbackChain tracks the groups a request can still be retried on once the current group gives up.
+type fallbackChain struct {
+	requestedGroup string
+	bodyBytes      []byte   // 原始请求体，回退分组需要重新应用各自的参数覆盖
+	requestURL     *url.URL // 原始请求 URL，回退分组需要重新应用各自的模型别名
+	pending        []*models.Group
+}
+
+// startFallbackChain records the fallback order of a request served first by groups[0].
+// Each group is followed by its declared fallback groups; fallback groups' own fallbacks are not followed.
+func (ps *ProxyServer) startFallbackChain(c *gin.Context, bodyBytes []byte, groups []*models.Group) {
+	chain := &fallbackChain{requestedGroup: groups[0].Name, bodyBytes: bodyBytes, requestURL: c.Request.URL}
+	seen := make(map[uint]struct{})
+	add := func(group *models.Group) {
+		if _, ok := seen[group.ID]; ok {
+			return
+		}
+		seen[group.ID] = struct{}{}
+		chain.pending = append(chain.pending, group)
+	}
+
+	for _, group := range groups {
+		add(group)
+		for _, id := range group.FallbackGroupIDs {
+			fallbackGroup, err := ps.groupManager.GetGroupByID(id)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{"group": group.Name, "fallback_group_id": id}).Debug("Skipping unknown fallback group")
+				continue
+			}
+			add(fallbackGroup)
+		}
+	}
+	chain.pending = chain.pending[1:]
+
+	c.Set(fallbackChainKey, chain)
+}
+
+// getFallbackChain returns the request's fallback chain, or nil if none was started.
+func getFallbackChain(c *gin.Context) *fallbackChain {
+	if value, ok := c.Get(fallbackChainKey); ok {
+		if chain, ok := value.(*fallbackChain); ok {
+			return chain
+		}
+	}
+	return nil
+}
+
+// hasFallback reports whether another group is left to retry the request on.
+func hasFallback(c *gin.Context) bool {
+	chain := getFallbackChain(c)
+	return chain != nil && len(chain.pending) > 0
+}
+
+// isFallbackGroup reports whether the group is serving the request as a fallback.
+func isFallbackGroup(c *gin.Context, group *models.Group) bool {
+	chain := getFallbackChain(c)
+	return chain != nil && chain.requestedGroup != group.Name
+}
+
+// fallBack retries the request on the next usable group of the chain. It returns false when no group is left.
+func (ps *ProxyServer) fallBack(c *gin.Context, from *models.Group, startTime time.Time) bool {
+	chain := getFallbackChain(c)
+	if chain == nil {
+		return false
+	}
+
+	for len(chain.pending) > 0 {
+		next := chain.pending[0]
+		chain.pending = chain.pending[1:]
+
+		channelHandler, err := ps.channelFactory.GetChannel(next)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"group": next.Name, "error": err}).Warn("Skipping fallback group, failed to get channel")
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{"from": from.Name, "to": next.Name}).Info("Falling back to next group")
+		c.Request.URL = chain.requestURL
+		ps.proxyToGroup(c, next, channelHandler, chain.bodyBytes, startTime)
+		return true
+	}
+	return false
+}
+
+// failOrFallBack logs the failed request on the group and hands it on to the next group of the fallback chain.
+// When fallback is not allowed or no group is left, the error is returned to the client.
+func (ps *ProxyServer) failOrFallBack(
+	c *gin.Context,
+	group *models.Group,
+	channelHandler channel.ChannelProxy,
+	apiErr *app_errors.APIError,
+	cause error,
+	canFallBack bool,
+	isStream bool,
+	bodyBytes []byte,
+	startTime time.Time,
+) {
+	canFallBack = canFallBack && hasFallback(c)
+	requestType := models.RequestTypeFinal
+	if canFallBack {
+		requestType = models.RequestTypeRetry
+	}
+	ps.logRequest(c, group, nil, startTime, apiErr.HTTPStatus, cause, isStream, "", channelHandler, bodyBytes, requestType)
+
+	if canFallBack && ps.fallBack(c, group, startTime) {
+		return
+	}
+
+	// 仅在 429 确实返回给客户端时告知重试时间，回退分组的成功响应不带 Retry-After
+	var limitedErr *rateLimitedError
+	var coolingErr *keypool.KeysCoolingDownError
+	switch {
+	case errors.As(cause, &limitedErr):
+		setRetryAfter(c, limitedErr.retryAfter)
+	case errors.As(cause, &coolingErr) && coolingErr.RetryAfter > 0:
+		setRetryAfter(c, coolingErr.RetryAfter)
+	}
+	ps.applyResponseHeaderRules(c, group)
+	response.Error(c, apiErr)
+}
@@ -0,0 +1,155 @@
+package proxy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultPolicyDisableDuration is used when a disable action does not specify a duration.
+const defaultPolicyDisableDuration = 30 * time.Minute
+
+// evaluateFailurePolicies evaluates the retry and degradation policies attached to the group for a failed attempt.
+func (ps *ProxyServer) evaluateFailurePolicies(group *models.Group, policyCtx *models.PolicyEvaluationContext) (*models.PolicyEvaluationResult, *models.PolicyEvaluationResult) {
+	retryResult, err := ps.policyEngine.EvaluateRetryPolicies(group.ID, policyCtx)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"group": group.Name, "error": err}).Error("Failed to evaluate retry policies")
+		retryResult = nil
+	}
+
+	degradationResult, err := ps.policyEngine.EvaluateDegradationPolicies(group.ID, policyCtx)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"group": group.Name, "error": err}).Error("Failed to evaluate degradation policies")
+		degradationResult = nil
+	}
+
+	return retryResult, degradationResult
+}
+
+// applyKeyPolicy decides what happens to the key after a failed attempt.
+// A matched degradation rule takes precedence over the key action of a retry rule;
+// without any key action the failure is reported to the key provider, which classifies it by status code,
+// unless chargeKey is false because the failure is attributed to the upstream rather than the key.
+func (ps *ProxyServer) applyKeyPolicy(
+	apiKey *models.APIKey,
+	group *models.Group,
+	statusCode int,
+	errorMessage string,
+	retryResult *models.PolicyEvaluationResult,
+	degradationResult *models.PolicyEvaluationResult,
+	chargeKey bool,
+) {
+	result := degradationResult
+	if result == nil && retryResult != nil && retryResult.Action != models.RetryActionRetry {
+		result = retryResult
+	}
+
+	if result == nil {
+		if !chargeKey {
+			logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "group": group.Name}).Debug("Connection-level error, not charged against the key")
+			return
+		}
+		ps.keyProvider.ReportFailure(apiKey, group, statusCode, errorMessage)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"keyID":  apiKey.ID,
+		"group":  group.Name,
+		"policy": result.PolicyName,
+		"rule":   result.RuleName,
+		"action": result.Action,
+	}).Debug("Policy matched for failed request")
+
+	switch result.Action {
+	case models.RetryActionInvalidate, models.RetryActionDegrade:
+		ps.keyProvider.ApplyPolicyAction(apiKey, group, result.Action, 0, errorMessage)
+	case models.RetryActionDisable:
+		ps.keyProvider.ApplyPolicyAction(apiKey, group, result.Action, parsePolicyDuration(result.Duration), errorMessage)
+	default:
+		ps.keyProvider.ReportFailure(apiKey, group, statusCode, errorMessage)
+	}
+}
+
+// parsePolicyDuration parses a policy duration string such as "5m", falling back to the default.
+func parsePolicyDuration(value string) time.Duration {
+	if value == "" {
+		return defaultPolicyDisableDuration
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		logrus.Warnf("Invalid policy duration '%s', using default %s", value, defaultPolicyDisableDuration)
+		return defaultPolicyDisableDuration
+	}
+	return duration
+}
+
+// rateLimitedError is the cause of a request refused by the group's rate limit policies.
+type rateLimitedError struct {
+	apiErr     *app_errors.APIError
+	retryAfter time.Duration
+}
+
+func (e *rateLimitedError) Error() string {
+	return e.apiErr.Error()
+}
+
+func (e *rateLimitedError) Unwrap() error {
+	return e.apiErr
+}
+
+// checkRateLimit enforces the group's rate limit policies and returns the refusal when the request is over the limit.
+// Store failures are logged and the request is let through. Retry-After is left to failOrFallBack,
+// since a fallback group may still serve the request.
+func (ps *ProxyServer) checkRateLimit(c *gin.Context, group *models.Group) *rateLimitedError {
+	decision, err := ps.rateLimiter.Allow(group.ID, c.GetString("proxyKey"))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"group": group.Name, "error": err}).Error("Failed to evaluate rate limit policies")
+		return nil
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	apiErr := app_errors.NewAPIError(app_errors.ErrRateLimited, fmt.Sprintf("Rate limit exceeded for group '%s' (%d requests per %s), retry after %d seconds", group.Name, decision.Limit, decision.Interval, retryAfterSeconds(decision.RetryAfter)))
+	return &rateLimitedError{apiErr: apiErr, retryAfter: decision.RetryAfter}
+}
+
+// retryAfterSeconds rounds a wait up to whole seconds, at least 1.
+func retryAfterSeconds(wait time.Duration) int {
+	return max(int(math.Ceil(wait.Seconds())), 1)
+}
+
+// setRetryAfter sets the Retry-After header of the client response.
+func setRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+}
+
+// applyKeyCooldown parks the key for the cooldown reported by the upstream rate-limit headers.
+// The key stays active and its failure count is untouched; selection simply skips it until the cooldown ends.
+func (ps *ProxyServer) applyKeyCooldown(apiKey *models.APIKey, group *models.Group, cooldown time.Duration) {
+	if err := ps.keyProvider.SetKeyCooldown(apiKey, cooldown); err != nil {
+		logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "group": group.Name, "error": err}).Error("Failed to set key cooldown")
+		return
+	}
+	logrus.WithFields(logrus.Fields{"keyID": apiKey.ID, "group": group.Name, "cooldown": cooldown}).Debug("Key cooling down after upstream rate limit")
+}
+
+// parkExhaustedKey takes a key whose quota is used up out of the pool until the group's next quota reset.
+func (ps *ProxyServer) parkExhaustedKey(apiKey *models.APIKey, group *models.Group, reason string) {
+	cfg := group.EffectiveConfig
+	resetAt, err := utils.NextQuotaReset(time.Now(), cfg.QuotaResetTime, cfg.QuotaResetTimezone)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"group": group.Name, "error": err}).Error("Invalid quota reset configuration, parking key for a day")
+		resetAt = time.Now().Add(24 * time.Hour)
+	}
+	ps.keyProvider.ParkExhaustedKey(apiKey, group, resetAt, reason)
+}
@@ -285,7 +285,7 @@ func (re *RequestExecutor) handleRetryableError(ctx *RequestContext, result *Req
 		errorMsg = result.Error.Error()
 	}
 
-	re.proxyServer.keyProvider.UpdateStatus(ctx.APIKey, ctx.Group, false, errorMsg)
+	re.proxyServer.keyProvider.ReportFailure(ctx.APIKey, ctx.Group, result.StatusCode, errorMsg)
 
 	// Log the retry attempt
 	var logError error
@@ -324,7 +324,7 @@ func (re *RequestExecutor) handleFinalError(ctx *RequestContext, result *Request
 		errorMsg = result.Error.Error()
 	}
 
-	re.proxyServer.keyProvider.UpdateStatus(ctx.APIKey, ctx.Group, false, errorMsg)
+	re.proxyServer.keyProvider.ReportFailure(ctx.APIKey, ctx.Group, result.StatusCode, errorMsg)
 
 	// Log the final error
 	var logError error
@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"gpt-load/internal/channel"
 	app_errors "gpt-load/internal/errors"
 	"gpt-load/internal/models"
+	"gpt-load/internal/utils"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -30,6 +36,153 @@ func (ps *ProxyServer) applyParamOverrides(bodyBytes []byte, group *models.Group
 	return json.Marshal(requestData)
 }
 
+// applyBodyRules applies the group's ordered body rules scoped to the request's model and path.
+func (ps *ProxyServer) applyBodyRules(c *gin.Context, group *models.Group, channelHandler channel.ChannelProxy, bodyBytes []byte) []byte {
+	if len(group.BodyRuleList) == 0 || len(bodyBytes) == 0 {
+		return bodyBytes
+	}
+
+	model := channelHandler.ExtractModel(c, bodyBytes)
+	requestPath := channel.TrimProxyPrefix(c.Request.URL.Path)
+	transformed, err := utils.ApplyBodyRules(bodyBytes, group.BodyRuleList, model, requestPath)
+	if err != nil {
+		logrus.Warnf("failed to apply body rules for group %s, passing through: %v", group.Name, err)
+		return bodyBytes
+	}
+	return transformed
+}
+
+// assignRequestID sets the ID of a proxy request, taken from the client's X-Request-Id header when present,
+// and returns it to the client in the same header.
+func assignRequestID(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-Id")
+	if requestID == "" || len(requestID) > 128 {
+		requestID = uuid.NewString()
+	}
+	c.Set(utils.RequestIDContextKey, requestID)
+	c.Header("X-Request-Id", requestID)
+}
+
+// attemptCountKey is the gin context key counting the upstream attempts of a request, including those of fallback groups.
+const attemptCountKey = "attemptCount"
+
+// Response headers added to every proxy response; response header rules can override or remove them.
+const (
+	servedGroupHeader  = "X-Served-Group"
+	attemptCountHeader = "X-Attempt-Count"
+)
+
+// applyResponseHeaderRules sets the served group and attempt count headers of the client response,
+// then applies the group's response header rules. It is called for upstream and proxy error responses alike.
+func (ps *ProxyServer) applyResponseHeaderRules(c *gin.Context, group *models.Group) {
+	attemptCount := c.GetInt(attemptCountKey)
+	header := c.Writer.Header()
+	header.Set(servedGroupHeader, group.Name)
+	header.Set(attemptCountHeader, strconv.Itoa(attemptCount))
+
+	if len(group.ResponseHeaderRuleList) == 0 {
+		return
+	}
+	// 不传入 Key 与密文解析器，避免 ${API_KEY}、${SECRET:name} 将密钥暴露给客户端
+	headerCtx := utils.NewHeaderVariableContextFromGin(c, group, nil)
+	headerCtx.Secrets = nil
+	headerCtx.AttemptCount = attemptCount
+	headerCtx.ServedGroup = group.Name
+	utils.ApplyResponseHeaderRules(header, group.ResponseHeaderRuleList, headerCtx)
+}
+
+// modelAliasKey is the gin context key holding the model alias the current group applied to the request.
+const modelAliasKey = "modelAlias"
+
+// modelAlias records a client model name rewritten to the upstream model of the group.
+type modelAlias struct {
+	alias string
+	model string
+}
+
+// getModelAlias returns the model alias applied by the current group, or nil if the model was not rewritten.
+func getModelAlias(c *gin.Context) *modelAlias {
+	if value, ok := c.Get(modelAliasKey); ok {
+		if alias, ok := value.(*modelAlias); ok {
+			return alias
+		}
+	}
+	return nil
+}
+
+// applyModelAlias rewrites a model alias of the group to its upstream model, in the body's model field
+// and in the models/{model} segment of Gemini-style request paths.
+func (ps *ProxyServer) applyModelAlias(c *gin.Context, group *models.Group, channelHandler channel.ChannelProxy, bodyBytes []byte) []byte {
+	// 回退分组各自应用别名，先清除上一个分组的记录
+	c.Set(modelAliasKey, (*modelAlias)(nil))
+	if len(group.ModelAliases) == 0 {
+		return bodyBytes
+	}
+
+	alias := channelHandler.ExtractModel(c, bodyBytes)
+	target, ok := group.ModelAliases[alias].(string)
+	if alias == "" || !ok || target == "" || target == alias {
+		return bodyBytes
+	}
+
+	if rewritten, ok := rewriteBodyModel(bodyBytes, alias, target); ok {
+		bodyBytes = rewritten
+	}
+	if path, ok := rewritePathModel(c.Request.URL.Path, alias, target); ok {
+		// 复制 URL，回退链中保留的原始 URL 不受影响
+		u := *c.Request.URL
+		u.Path = path
+		u.RawPath = ""
+		c.Request.URL = &u
+	}
+
+	c.Set(modelAliasKey, &modelAlias{alias: alias, model: target})
+	logrus.WithFields(logrus.Fields{"group": group.Name, "alias": alias, "model": target}).Debug("Rewrote model alias")
+	return bodyBytes
+}
+
+// rewriteBodyModel replaces the alias in the body's model field, keeping a "models/" prefix.
+func rewriteBodyModel(bodyBytes []byte, alias, target string) ([]byte, bool) {
+	if len(bodyBytes) == 0 {
+		return nil, false
+	}
+	var requestData map[string]any
+	if err := json.Unmarshal(bodyBytes, &requestData); err != nil {
+		return nil, false
+	}
+	model, ok := requestData["model"].(string)
+	if !ok || strings.TrimPrefix(model, "models/") != alias {
+		return nil, false
+	}
+	requestData["model"] = strings.TrimSuffix(model, alias) + target
+
+	rewritten, err := json.Marshal(requestData)
+	if err != nil {
+		return nil, false
+	}
+	return rewritten, true
+}
+
+// rewritePathModel replaces the alias in the models/{model}[:method] segment of a request path.
+func rewritePathModel(path, alias, target string) (string, bool) {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if part != "models" || i+1 >= len(parts) {
+			continue
+		}
+		model, method, hasMethod := strings.Cut(parts[i+1], ":")
+		if model != alias {
+			return "", false
+		}
+		parts[i+1] = target
+		if hasMethod {
+			parts[i+1] += ":" + method
+		}
+		return strings.Join(parts, "/"), true
+	}
+	return "", false
+}
+
 // logUpstreamError provides a centralized way to log errors from upstream interactions.
 func logUpstreamError(context string, err error) {
 	if err == nil {
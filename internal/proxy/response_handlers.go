@@ -1,9 +1,14 @@
 package proxy
 
 import (
+	"errors"
 	"io"
 	"net/http"
 
+	"gpt-load/internal/channel"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/response"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -46,3 +51,75 @@ func (ps *ProxyServer) handleNormalResponse(c *gin.Context, resp *http.Response)
 		logUpstreamError("copying response body", err)
 	}
 }
+
+// handleTranslatedStreamingResponse converts the upstream event stream into the client protocol event by event.
+func (ps *ProxyServer) handleTranslatedStreamingResponse(c *gin.Context, resp *http.Response, translator channel.ProtocolTranslator) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	flusher, _ := c.Writer.(http.Flusher)
+	stream := translator.NewStreamTranslator(c)
+	writeEvents := func(events []channel.SSEEvent) error {
+		for _, event := range events {
+			if err := channel.WriteSSE(c.Writer, event); err != nil {
+				return err
+			}
+		}
+		if flusher != nil && len(events) > 0 {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	err := channel.ReadStream(stream, resp.Body, func(event channel.SSEEvent) error {
+		events, err := stream.TranslateEvent(event)
+		if err != nil {
+			logrus.WithError(err).Warn("Skipping untranslatable stream event")
+			return nil
+		}
+		return writeEvents(events)
+	})
+	if err != nil {
+		logUpstreamError("translating stream", err)
+		return
+	}
+	if err := writeEvents(stream.Finish()); err != nil {
+		logUpstreamError("writing stream to client", err)
+	}
+}
+
+// handleTranslatedErrorResponse converts an upstream error body into the client protocol's error format.
+func (ps *ProxyServer) handleTranslatedErrorResponse(c *gin.Context, resp *http.Response, translator channel.ProtocolTranslator) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logUpstreamError("reading error response body", err)
+		c.Status(http.StatusBadGateway)
+		return
+	}
+	c.Data(resp.StatusCode, "application/json", translator.TranslateError(resp.StatusCode, body))
+}
+
+// handleTranslatedResponse converts the upstream response body into the client protocol.
+func (ps *ProxyServer) handleTranslatedResponse(c *gin.Context, resp *http.Response, translator channel.ProtocolTranslator) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logUpstreamError("reading response body", err)
+		c.Status(http.StatusBadGateway)
+		return
+	}
+
+	translated, err := translator.TranslateResponse(c, body)
+	var translationErr *channel.TranslationError
+	if errors.As(err, &translationErr) {
+		c.Data(translationErr.StatusCode, "application/json", translationErr.Body)
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to translate upstream response")
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrBadGateway, err.Error()))
+		return
+	}
+	c.Data(resp.StatusCode, "application/json", translated)
+}
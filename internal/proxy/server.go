@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"gpt-load/internal/channel"
@@ -17,6 +19,7 @@ import (
 	app_errors "gpt-load/internal/errors"
 	"gpt-load/internal/keypool"
 	"gpt-load/internal/models"
+	"gpt-load/internal/policy"
 	"gpt-load/internal/response"
 	"gpt-load/internal/services"
 	"gpt-load/internal/utils"
@@ -29,34 +32,44 @@ import (
 type ProxyServer struct {
 	keyProvider       *keypool.KeyProvider
 	groupManager      *services.GroupManager
+	modelRouter       *services.ModelRouter
 	settingsManager   *config.SystemSettingsManager
 	channelFactory    *channel.Factory
 	requestLogService *services.RequestLogService
 	encryptionSvc     encryption.Service
+	policyEngine      *policy.PolicyEngine
+	rateLimiter       *policy.RateLimiter
 }
 
 // NewProxyServer creates a new proxy server
 func NewProxyServer(
 	keyProvider *keypool.KeyProvider,
 	groupManager *services.GroupManager,
+	modelRouter *services.ModelRouter,
 	settingsManager *config.SystemSettingsManager,
 	channelFactory *channel.Factory,
 	requestLogService *services.RequestLogService,
 	encryptionSvc encryption.Service,
+	policyEngine *policy.PolicyEngine,
+	rateLimiter *policy.RateLimiter,
 ) (*ProxyServer, error) {
 	return &ProxyServer{
 		keyProvider:       keyProvider,
 		groupManager:      groupManager,
+		modelRouter:       modelRouter,
 		settingsManager:   settingsManager,
 		channelFactory:    channelFactory,
 		requestLogService: requestLogService,
 		encryptionSvc:     encryptionSvc,
+		policyEngine:      policyEngine,
+		rateLimiter:       rateLimiter,
 	}, nil
 }
 
 // HandleProxy is the main entry point for proxy requests, refactored based on the stable .bak logic.
 func (ps *ProxyServer) HandleProxy(c *gin.Context) {
 	startTime := time.Now()
+	assignRequestID(c)
 	groupName := c.Param("group_name")
 
 	group, err := ps.groupManager.GetGroupByName(groupName)
@@ -79,15 +92,67 @@ func (ps *ProxyServer) HandleProxy(c *gin.Context) {
 	}
 	c.Request.Body.Close()
 
+	ps.startFallbackChain(c, bodyBytes, []*models.Group{group})
+	ps.proxyToGroup(c, group, channelHandler, bodyBytes, startTime)
+}
+
+// proxyToGroup applies the group's overrides and policies to the request and forwards it upstream.
+// A fallback group that refuses the request by policy hands it on to the next group of the chain.
+func (ps *ProxyServer) proxyToGroup(c *gin.Context, group *models.Group, channelHandler channel.ChannelProxy, bodyBytes []byte, startTime time.Time) {
+	bodyBytes = ps.applyModelAlias(c, group, channelHandler, bodyBytes)
+
 	finalBodyBytes, err := ps.applyParamOverrides(bodyBytes, group)
 	if err != nil {
 		response.Error(c, app_errors.NewAPIError(app_errors.ErrInternalServer, fmt.Sprintf("Failed to apply parameter overrides: %v", err)))
 		return
 	}
+	finalBodyBytes = ps.applyBodyRules(c, group, channelHandler, finalBodyBytes)
+
+	// 按覆盖参数与请求体规则生效后的请求体判断流式；协议转换保持流式模式不变
+	isStream := channelHandler.IsStreamRequest(c, finalBodyBytes)
 
-	isStream := channelHandler.IsStreamRequest(c, bodyBytes)
+	// 在消耗密钥之前应用模型过滤策略
+	model := channelHandler.ExtractModel(c, finalBodyBytes)
+	allowed, err := ps.policyEngine.EvaluateModelFilterPolicies(group.ID, model)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"group": group.Name, "error": err}).Error("Failed to evaluate model filter policies")
+	}
+	if !allowed {
+		apiErr := app_errors.NewAPIError(app_errors.ErrModelNotAllowed, fmt.Sprintf("Model '%s' is not allowed for group '%s'", model, group.Name))
+		ps.failOrFallBack(c, group, channelHandler, apiErr, apiErr, isFallbackGroup(c, group), isStream, finalBodyBytes, startTime)
+		return
+	}
+
+	// 超出限流策略的请求直接返回 429，不再转发到上游
+	if limited := ps.checkRateLimit(c, group); limited != nil {
+		ps.failOrFallBack(c, group, channelHandler, limited.apiErr, limited, isFallbackGroup(c, group), isStream, finalBodyBytes, startTime)
+		return
+	}
+
+	// 协议转换渠道将客户端请求转换为上游协议，并可替换用于构建上游地址的请求 URL
+	requestURL := c.Request.URL
+	if translator := channel.GetTranslator(channelHandler, c); translator != nil {
+		translated, err := translator.TranslateRequest(c, finalBodyBytes)
+		if err != nil {
+			apiErr := app_errors.NewAPIError(app_errors.ErrBadRequest, fmt.Sprintf("Failed to translate request: %v", err))
+			ps.failOrFallBack(c, group, channelHandler, apiErr, apiErr, false, isStream, finalBodyBytes, startTime)
+			return
+		}
+		finalBodyBytes = translated.Body
+		if translated.URL != nil {
+			requestURL = translated.URL
+		}
+	}
+	if rewriter, ok := channelHandler.(channel.RequestURLRewriter); ok {
+		requestURL, err = rewriter.RewriteRequestURL(c, requestURL, finalBodyBytes)
+		if err != nil {
+			apiErr := app_errors.NewAPIError(app_errors.ErrBadRequest, err.Error())
+			ps.failOrFallBack(c, group, channelHandler, apiErr, apiErr, false, isStream, finalBodyBytes, startTime)
+			return
+		}
+	}
 
-	ps.executeRequestWithRetry(c, channelHandler, group, finalBodyBytes, isStream, startTime, 0)
+	ps.executeRequestWithRetry(c, channelHandler, group, finalBodyBytes, requestURL, isStream, startTime, 0, group.EffectiveConfig.MaxRetries)
 }
 
 // executeRequestWithRetry is the core recursive function for handling requests and retries.
@@ -96,21 +161,50 @@ func (ps *ProxyServer) executeRequestWithRetry(
 	channelHandler channel.ChannelProxy,
 	group *models.Group,
 	bodyBytes []byte,
+	requestURL *url.URL,
 	isStream bool,
 	startTime time.Time,
 	retryCount int,
+	maxRetries int,
 ) {
 	cfg := group.EffectiveConfig
-
-	apiKey, err := ps.keyProvider.SelectKey(group.ID)
+	translator := channel.GetTranslator(channelHandler, c)
+
+	// 无密钥渠道（自托管模型服务）跳过密钥选择，以不含密钥值的占位 Key 走完同一流程
+	_, keyless := channelHandler.(channel.KeylessChannel)
+	var apiKey *models.APIKey
+	var err error
+	if keyless {
+		apiKey = &models.APIKey{GroupID: group.ID}
+	} else {
+		apiKey, err = ps.keyProvider.SelectKeyForGroup(group)
+	}
 	if err != nil {
-		logrus.Errorf("Failed to select a key for group %s on attempt %d: %v", group.Name, retryCount+1, err)
-		response.Error(c, app_errors.NewAPIError(app_errors.ErrNoKeysAvailable, err.Error()))
-		ps.logRequest(c, group, nil, startTime, http.StatusServiceUnavailable, err, isStream, "", channelHandler, bodyBytes, models.RequestTypeFinal)
+		var apiErr *app_errors.APIError
+		if errors.As(err, &apiErr) && (apiErr == app_errors.ErrKeysCoolingDown || apiErr == app_errors.ErrKeysSaturated) {
+			logrus.Warnf("No usable key for group %s on attempt %d: %s", group.Name, retryCount+1, apiErr.Message)
+		} else {
+			logrus.Errorf("Failed to select a key for group %s on attempt %d: %v", group.Name, retryCount+1, err)
+			apiErr = app_errors.NewAPIError(app_errors.ErrNoKeysAvailable, err.Error())
+		}
+		ps.failOrFallBack(c, group, channelHandler, apiErr, err, true, isStream, bodyBytes, startTime)
 		return
 	}
 
-	upstreamURL, err := channelHandler.BuildUpstreamURL(c.Request.URL, group)
+	// 并发槽位在本次尝试结束时释放；重试前提前释放，避免失败的 Key 在后续尝试期间仍占用槽位
+	var releaseOnce sync.Once
+	releaseKeySlot := func() {
+		if cfg.MaxConcurrentPerKey > 0 && !keyless {
+			releaseOnce.Do(func() { ps.keyProvider.ReleaseKeySlot(apiKey) })
+		}
+	}
+	defer releaseKeySlot()
+	loggedKey := apiKey
+	if keyless {
+		loggedKey = nil
+	}
+
+	upstreamURL, err := channelHandler.BuildUpstreamURL(requestURL, group)
 	if err != nil {
 		response.Error(c, app_errors.NewAPIError(app_errors.ErrInternalServer, fmt.Sprintf("Failed to build upstream URL: %v", err)))
 		return
@@ -140,12 +234,17 @@ func (ps *ProxyServer) executeRequestWithRetry(
 	req.Header.Del("Authorization")
 	req.Header.Del("X-Api-Key")
 	req.Header.Del("X-Goog-Api-Key")
+	if translator != nil {
+		// 响应需要解析转换，交由 Transport 自动处理压缩
+		req.Header.Del("Accept-Encoding")
+	}
 
 	channelHandler.ModifyRequest(req, apiKey, group)
 
 	// Apply custom header rules
 	if len(group.HeaderRuleList) > 0 {
 		headerCtx := utils.NewHeaderVariableContextFromGin(c, group, apiKey)
+		headerCtx.Model = channelHandler.ExtractModel(c, bodyBytes)
 		utils.ApplyHeaderRules(req, group.HeaderRuleList, headerCtx)
 	}
 
@@ -157,28 +256,46 @@ func (ps *ProxyServer) executeRequestWithRetry(
 		client = channelHandler.GetHTTPClient()
 	}
 
+	// 上游在途请求在本次尝试结束时结束计数；重试前提前结束，与并发槽位一致
+	endUpstreamRequest := channelHandler.TrackUpstreamRequest(upstreamURL)
+	defer endUpstreamRequest()
+
+	c.Set(attemptCountKey, c.GetInt(attemptCountKey)+1)
+	upstreamStart := time.Now()
 	resp, err := client.Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
+	if resp != nil {
+		channelHandler.RecordUpstreamResult(upstreamURL, resp.StatusCode, nil, time.Since(upstreamStart))
+	} else if err != nil && !app_errors.IsIgnorableError(err) {
+		channelHandler.RecordUpstreamResult(upstreamURL, 0, err, 0)
+	}
 
 	// Unified error handling for retries. Exclude 404 from being a retryable error.
 	if err != nil || (resp != nil && resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound) {
 		if err != nil && app_errors.IsIgnorableError(err) {
 			logrus.Debugf("Client-side ignorable error for key %s, aborting retries: %v", utils.MaskAPIKey(apiKey.KeyValue), err)
-			ps.logRequest(c, group, apiKey, startTime, 499, err, isStream, upstreamURL, channelHandler, bodyBytes, models.RequestTypeFinal)
+			ps.logRequest(c, group, loggedKey, startTime, 499, err, isStream, upstreamURL, channelHandler, bodyBytes, models.RequestTypeFinal)
 			return
 		}
 
 		var statusCode int
 		var errorMessage string
 		var parsedError string
+		var errorType string
+		var cooldown time.Duration
+		var quotaExhausted bool
 
 		if err != nil {
 			statusCode = 500
 			errorMessage = err.Error()
 			parsedError = errorMessage
-			logrus.Debugf("Request failed (attempt %d/%d) for key %s: %v", retryCount+1, cfg.MaxRetries, utils.MaskAPIKey(apiKey.KeyValue), err)
+			errorType = models.PolicyErrorTypeNetwork
+			if errors.Is(err, context.DeadlineExceeded) {
+				errorType = models.PolicyErrorTypeTimeout
+			}
+			logrus.Debugf("Request failed (attempt %d/%d) for key %s: %v", retryCount+1, maxRetries, utils.MaskAPIKey(apiKey.KeyValue), err)
 		} else {
 			// HTTP-level error (status >= 400)
 			statusCode = resp.StatusCode
@@ -191,23 +308,70 @@ func (ps *ProxyServer) executeRequestWithRetry(
 			errorBody = handleGzipCompression(resp, errorBody)
 			errorMessage = string(errorBody)
 			parsedError = app_errors.ParseUpstreamError(errorBody)
-			logrus.Debugf("Request failed with status %d (attempt %d/%d) for key %s. Parsed Error: %s", statusCode, retryCount+1, cfg.MaxRetries, utils.MaskAPIKey(apiKey.KeyValue), parsedError)
+			errorType = models.PolicyErrorTypeUpstream
+			cooldown = utils.ParseRateLimitCooldown(resp.Header, statusCode, time.Now())
+			quotaExhausted = channelHandler.IsQuotaExhausted(statusCode, errorBody)
+			logrus.Debugf("Request failed with status %d (attempt %d/%d) for key %s. Parsed Error: %s", statusCode, retryCount+1, maxRetries, utils.MaskAPIKey(apiKey.KeyValue), parsedError)
 		}
 
-		// 使用解析后的错误信息更新密钥状态
-		ps.keyProvider.UpdateStatus(apiKey, group, false, parsedError)
+		policyCtx := &models.PolicyEvaluationContext{
+			GroupID:      group.ID,
+			KeyID:        apiKey.ID,
+			Model:        channelHandler.ExtractModel(c, bodyBytes),
+			StatusCode:   statusCode,
+			ErrorMessage: parsedError,
+			ErrorType:    errorType,
+			FailureCount: apiKey.FailureCount,
+			RequestCount: int64(retryCount + 1),
+		}
+		var retryResult, degradationResult *models.PolicyEvaluationResult
+		if keyless {
+			// 无密钥分组只按重试策略重试，失败由上游健康统计处理
+			retryResult, _ = ps.evaluateFailurePolicies(group, policyCtx)
+		} else if quotaExhausted {
+			// 额度耗尽：停用到下一次额度重置时间，不计入失败次数
+			ps.parkExhaustedKey(apiKey, group, parsedError)
+		} else if cooldown > 0 {
+			// 上游明确给出了限流恢复时间：仅冷却该 Key，不计入失败次数
+			ps.applyKeyCooldown(apiKey, group, cooldown)
+		} else {
+			retryResult, degradationResult = ps.evaluateFailurePolicies(group, policyCtx)
 
-		// 判断是否为最后一次尝试
-		isLastAttempt := retryCount >= cfg.MaxRetries
+			// 根据策略结果更新密钥状态，无匹配时使用默认的失败计数；连接层错误归因于上游，不计入密钥
+			ps.applyKeyPolicy(apiKey, group, statusCode, parsedError, retryResult, degradationResult, err == nil)
+		}
+
+		var backoff time.Duration
+		if retryResult != nil {
+			if retryResult.MaxRetries > 0 {
+				maxRetries = retryResult.MaxRetries
+			}
+			backoff = time.Duration(retryResult.BackoffMs) * time.Millisecond
+		}
+
+		// 判断是否为最后一次尝试；重试耗尽后如有回退分组，则转交下一个分组处理
+		isLastAttempt := retryCount >= maxRetries
+		canFallBack := isLastAttempt && hasFallback(c)
 		requestType := models.RequestTypeRetry
-		if isLastAttempt {
+		if isLastAttempt && !canFallBack {
 			requestType = models.RequestTypeFinal
 		}
 
-		ps.logRequest(c, group, apiKey, startTime, statusCode, errors.New(parsedError), isStream, upstreamURL, channelHandler, bodyBytes, requestType)
+		ps.logRequest(c, group, loggedKey, startTime, statusCode, errors.New(parsedError), isStream, upstreamURL, channelHandler, bodyBytes, requestType)
 
 		// 如果是最后一次尝试，直接返回错误，不再递归
 		if isLastAttempt {
+			if canFallBack {
+				releaseKeySlot()
+				endUpstreamRequest()
+				if ps.fallBack(c, group, startTime) {
+					return
+				}
+			}
+			if translator != nil && resp != nil {
+				errorMessage = string(translator.TranslateError(statusCode, []byte(errorMessage)))
+			}
+			ps.applyResponseHeaderRules(c, group)
 			var errorJSON map[string]any
 			if err := json.Unmarshal([]byte(errorMessage), &errorJSON); err == nil {
 				c.JSON(statusCode, errorJSON)
@@ -217,27 +381,60 @@ func (ps *ProxyServer) executeRequestWithRetry(
 			return
 		}
 
-		ps.executeRequestWithRetry(c, channelHandler, group, bodyBytes, isStream, startTime, retryCount+1)
+		releaseKeySlot()
+		endUpstreamRequest()
+		if backoff > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-c.Request.Context().Done():
+				logrus.Debugf("Client disconnected during retry backoff for group %s", group.Name)
+				return
+			}
+		}
+
+		ps.executeRequestWithRetry(c, channelHandler, group, bodyBytes, requestURL, isStream, startTime, retryCount+1, maxRetries)
 		return
 	}
 
-	// ps.keyProvider.UpdateStatus(apiKey, group, true) // 请求成功不再重置成功次数，减少IO消耗
+	// 成功反馈交给状态机，健康 Key 在 Store 层即被短路，不会产生数据库写入
+	if !keyless {
+		ps.keyProvider.UpdateStatus(apiKey, group, true, "")
+		// 上游在成功响应中报告额度已耗尽时，提前冷却该 Key，避免下一次请求必然 429
+		if cooldown := utils.ParseRateLimitCooldown(resp.Header, resp.StatusCode, time.Now()); cooldown > 0 {
+			ps.applyKeyCooldown(apiKey, group, cooldown)
+		}
+	}
 	logrus.Debugf("Request for group %s succeeded on attempt %d with key %s", group.Name, retryCount+1, utils.MaskAPIKey(apiKey.KeyValue))
 
 	for key, values := range resp.Header {
+		// 转换后的响应体与上游不同，长度与编码头不再适用
+		if translator != nil && (key == "Content-Length" || key == "Content-Encoding") {
+			continue
+		}
 		for _, value := range values {
 			c.Header(key, value)
 		}
 	}
-	c.Status(resp.StatusCode)
-
-	if isStream {
+	ps.applyResponseHeaderRules(c, group)
+
+	switch {
+	case translator != nil && resp.StatusCode >= http.StatusBadRequest:
+		// 不重试的错误响应（如 404 未知模型）同样需要转换为客户端协议的错误格式
+		ps.handleTranslatedErrorResponse(c, resp, translator)
+	case translator != nil && isStream:
+		c.Status(resp.StatusCode)
+		ps.handleTranslatedStreamingResponse(c, resp, translator)
+	case translator != nil:
+		ps.handleTranslatedResponse(c, resp, translator)
+	case isStream:
+		c.Status(resp.StatusCode)
 		ps.handleStreamingResponse(c, resp)
-	} else {
+	default:
+		c.Status(resp.StatusCode)
 		ps.handleNormalResponse(c, resp)
 	}
 
-	ps.logRequest(c, group, apiKey, startTime, resp.StatusCode, nil, isStream, upstreamURL, channelHandler, bodyBytes, models.RequestTypeFinal)
+	ps.logRequest(c, group, loggedKey, startTime, resp.StatusCode, nil, isStream, upstreamURL, channelHandler, bodyBytes, models.RequestTypeFinal)
 }
 
 // logRequest is a helper function to create and record a request log.
@@ -267,23 +464,39 @@ func (ps *ProxyServer) logRequest(
 
 	duration := time.Since(startTime).Milliseconds()
 
+	requestURL := c.Request.URL
+	if chain := getFallbackChain(c); chain != nil {
+		// 记录客户端原始请求路径，而非经模型别名改写后的路径
+		requestURL = chain.requestURL
+	}
+
 	logEntry := &models.RequestLog{
-		GroupID:      group.ID,
-		GroupName:    group.Name,
-		IsSuccess:    finalError == nil && statusCode < 400,
-		SourceIP:     c.ClientIP(),
-		StatusCode:   statusCode,
-		RequestPath:  utils.TruncateString(c.Request.URL.String(), 500),
-		Duration:     duration,
-		UserAgent:    userAgent,
-		RequestType:  requestType,
-		IsStream:     isStream,
-		UpstreamAddr: utils.TruncateString(upstreamAddr, 500),
-		RequestBody:  requestBodyToLog,
+		GroupID:            group.ID,
+		GroupName:          group.Name,
+		RequestedGroupName: group.Name,
+		IsSuccess:          finalError == nil && statusCode < 400,
+		SourceIP:           c.ClientIP(),
+		StatusCode:         statusCode,
+		RequestPath:        utils.TruncateString(requestURL.String(), 500),
+		Duration:           duration,
+		UserAgent:          userAgent,
+		RequestType:        requestType,
+		IsStream:           isStream,
+		UpstreamAddr:       utils.TruncateString(upstreamAddr, 500),
+		RequestBody:        requestBodyToLog,
+	}
+
+	if chain := getFallbackChain(c); chain != nil {
+		logEntry.RequestedGroupName = chain.requestedGroup
 	}
 
 	if channelHandler != nil && bodyBytes != nil {
 		logEntry.Model = channelHandler.ExtractModel(c, bodyBytes)
+		logEntry.UpstreamModel = logEntry.Model
+	}
+	if alias := getModelAlias(c); alias != nil {
+		logEntry.Model = alias.alias
+		logEntry.UpstreamModel = alias.model
 	}
 
 	if apiKey != nil {
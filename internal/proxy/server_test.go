@@ -0,0 +1,216 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gpt-load/internal/channel"
+	"gpt-load/internal/config"
+	"gpt-load/internal/encryption"
+	"gpt-load/internal/httpclient"
+	"gpt-load/internal/keypool"
+	"gpt-load/internal/models"
+	"gpt-load/internal/policy"
+	"gpt-load/internal/services"
+	"gpt-load/internal/store"
+	"gpt-load/internal/tests"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// testProxy is a proxy server backed by an in-memory database and store.
+type testProxy struct {
+	db     *gorm.DB
+	server *ProxyServer
+	router *gin.Engine
+}
+
+// testGroup describes a group created by newTestProxy.
+type testGroup struct {
+	name        string
+	upstream    string
+	keys        []string
+	fallbacks   []string
+	rateLimit   int64 // 大于 0 时绑定每分钟该次数的限流策略
+	channelType string
+}
+
+func newTestProxy(t *testing.T, groups ...testGroup) *testProxy {
+	gin.SetMode(gin.TestMode)
+	db := tests.SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.GroupPolicy{}))
+	memStore := store.NewMemoryStore()
+	settingsManager := &config.SystemSettingsManager{}
+	encryptionSvc, _ := encryption.NewService("")
+
+	ids := make(map[string]uint, len(groups))
+	for _, g := range groups {
+		channelType := g.channelType
+		if channelType == "" {
+			channelType = "openai"
+		}
+		upstreams, err := json.Marshal([]map[string]any{{"url": g.upstream, "weight": 1}})
+		require.NoError(t, err)
+		group := &models.Group{Name: g.name, ChannelType: channelType, TestModel: "gpt-4o", Upstreams: datatypes.JSON(upstreams)}
+		require.NoError(t, db.Create(group).Error)
+		ids[g.name] = group.ID
+	}
+
+	keyProvider := keypool.NewProvider(db, memStore, settingsManager, encryptionSvc)
+	for _, g := range groups {
+		if len(g.fallbacks) > 0 {
+			fallbackIDs := make([]uint, 0, len(g.fallbacks))
+			for _, name := range g.fallbacks {
+				fallbackIDs = append(fallbackIDs, ids[name])
+			}
+			fallbacks, err := json.Marshal(fallbackIDs)
+			require.NoError(t, err)
+			require.NoError(t, db.Model(&models.Group{}).Where("id = ?", ids[g.name]).Update("fallback_groups", datatypes.JSON(fallbacks)).Error)
+		}
+		if g.rateLimit > 0 {
+			configBytes, err := json.Marshal(models.RateLimitPolicyConfig{Limit: g.rateLimit, Interval: "1m"})
+			require.NoError(t, err)
+			rateLimit := &models.Policy{Name: g.name + "-limit", Type: models.PolicyTypeRateLimit, Config: configBytes, IsActive: true}
+			require.NoError(t, db.Create(rateLimit).Error)
+			require.NoError(t, db.Create(&models.GroupPolicy{GroupID: ids[g.name], PolicyID: rateLimit.ID, IsActive: true}).Error)
+		}
+		keys := make([]models.APIKey, 0, len(g.keys))
+		for _, key := range g.keys {
+			keys = append(keys, models.APIKey{KeyValue: key, KeyHash: key, GroupID: ids[g.name], Status: models.KeyStatusActive})
+		}
+		if len(keys) > 0 {
+			require.NoError(t, keyProvider.AddKeys(ids[g.name], keys))
+		}
+	}
+
+	groupManager := services.NewGroupManager(db, memStore, settingsManager)
+	require.NoError(t, groupManager.Initialize())
+	policyEngine := policy.NewPolicyEngine(db, memStore)
+	require.NoError(t, policyEngine.Initialize())
+
+	server, err := NewProxyServer(
+		keyProvider,
+		groupManager,
+		services.NewModelRouter(db, memStore),
+		settingsManager,
+		channel.NewFactory(settingsManager, httpclient.NewHTTPClientManager()),
+		nil,
+		encryptionSvc,
+		policyEngine,
+		policy.NewRateLimiter(policyEngine, memStore),
+	)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Any("/proxy/:group_name/*path", server.HandleProxy)
+	return &testProxy{db: db, server: server, router: router}
+}
+
+func (p *testProxy) do(method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	p.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestProxy_RateLimitedFallbackGroupLeavesNoRetryAfter(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"x","object":"chat.completion","choices":[]}`)
+	}))
+	defer upstream.Close()
+
+	// primary 没有可用 Key，回退到 limited；limited 超出限流后交给 backup 处理
+	p := newTestProxy(t,
+		testGroup{name: "primary", upstream: upstream.URL, fallbacks: []string{"limited", "backup"}},
+		testGroup{name: "limited", upstream: upstream.URL, keys: []string{"sk-limited"}, rateLimit: 1},
+		testGroup{name: "backup", upstream: upstream.URL, keys: []string{"sk-backup"}},
+	)
+	body := `{"model":"gpt-4o","messages":[]}`
+
+	w := p.do(http.MethodPost, "/proxy/primary/v1/chat/completions", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "limited", w.Header().Get("X-Served-Group"))
+
+	w = p.do(http.MethodPost, "/proxy/primary/v1/chat/completions", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "backup", w.Header().Get("X-Served-Group"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestProxy_RateLimitedRequestSetsRetryAfter(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"id":"x","object":"chat.completion","choices":[]}`)
+	}))
+	defer upstream.Close()
+
+	p := newTestProxy(t, testGroup{name: "limited", upstream: upstream.URL, keys: []string{"sk-limited"}, rateLimit: 1})
+	body := `{"model":"gpt-4o","messages":[]}`
+
+	w := p.do(http.MethodPost, "/proxy/limited/v1/chat/completions", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = p.do(http.MethodPost, "/proxy/limited/v1/chat/completions", body)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestProxy_TranslatedUpstreamNotFound(t *testing.T) {
+	cases := []struct {
+		name        string
+		channelType string
+		upstream    string // 上游 404 响应体
+		message     string
+	}{
+		{
+			name:        "anthropic",
+			channelType: "openai-to-anthropic",
+			upstream:    `{"type":"error","error":{"type":"not_found_error","message":"model: claude-unknown"}}`,
+			message:     "model: claude-unknown",
+		},
+		{
+			name:        "gemini",
+			channelType: "openai-to-gemini",
+			upstream:    `{"error":{"code":404,"message":"models/gemini-unknown is not found","status":"NOT_FOUND"}}`,
+			message:     "models/gemini-unknown is not found",
+		},
+	}
+	for _, tt := range cases {
+		for _, stream := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s stream=%t", tt.name, stream), func(t *testing.T) {
+				upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = io.WriteString(w, tt.upstream)
+				}))
+				defer upstream.Close()
+
+				p := newTestProxy(t, testGroup{name: "translated", upstream: upstream.URL, keys: []string{"sk-up"}, channelType: tt.channelType})
+				body := fmt.Sprintf(`{"model":"unknown","stream":%t,"messages":[{"role":"user","content":"hi"}]}`, stream)
+
+				w := p.do(http.MethodPost, "/proxy/translated/v1/chat/completions", body)
+				assert.Equal(t, http.StatusNotFound, w.Code)
+
+				var resp struct {
+					Choices []any `json:"choices"`
+					Error   struct {
+						Message string `json:"message"`
+					} `json:"error"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+				assert.Empty(t, resp.Choices)
+				assert.Equal(t, tt.message, resp.Error.Message)
+			})
+		}
+	}
+}
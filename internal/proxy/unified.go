@@ -0,0 +1,158 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gpt-load/internal/channel"
+	app_errors "gpt-load/internal/errors"
+	"gpt-load/internal/models"
+	"gpt-load/internal/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// routedGroup is a group resolved from the model routing table together with its channel.
+type routedGroup struct {
+	group   *models.Group
+	channel channel.ChannelProxy
+}
+
+// HandleUnifiedProxy serves the top-level /v1/* endpoint, picking the group from the model routing table.
+func (ps *ProxyServer) HandleUnifiedProxy(c *gin.Context) {
+	startTime := time.Now()
+	assignRequestID(c)
+	proxyKey := c.GetString("requestedProxyKey")
+
+	bodyBytes, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		logrus.Errorf("Failed to read request body: %v", err)
+		response.Error(c, app_errors.NewAPIError(app_errors.ErrBadRequest, "Failed to read request body"))
+		return
+	}
+	c.Request.Body.Close()
+
+	// 先校验代理密钥，避免未认证的调用方通过 404 与 401 的区别探测路由表
+	if !ps.acceptsRoutedProxyKey(proxyKey) {
+		response.Error(c, app_errors.ErrUnauthorized)
+		return
+	}
+
+	candidates, model, apiErr := ps.resolveRoutedGroups(c, proxyKey, bodyBytes)
+	if apiErr != nil {
+		if model == "" && c.Request.Method == http.MethodGet && strings.TrimSuffix(c.Param("path"), "/") == "/models" {
+			ps.listRoutedModels(c, proxyKey)
+			return
+		}
+		response.Error(c, apiErr)
+		return
+	}
+
+	// 其余匹配的分组依次作为回退
+	groups := make([]*models.Group, 0, len(candidates))
+	for _, candidate := range candidates {
+		groups = append(groups, candidate.group)
+	}
+	target := candidates[0]
+	c.Set("proxyKey", proxyKey)
+	logrus.WithFields(logrus.Fields{"model": model, "group": target.group.Name}).Debug("Routed unified request")
+
+	ps.startFallbackChain(c, bodyBytes, groups)
+	ps.proxyToGroup(c, target.group, target.channel, bodyBytes, startTime)
+}
+
+// acceptsRoutedProxyKey reports whether any group of the routing table accepts the proxy key.
+func (ps *ProxyServer) acceptsRoutedProxyKey(proxyKey string) bool {
+	for _, route := range ps.modelRouter.Routes() {
+		group, err := ps.groupManager.GetGroupByID(route.GroupID)
+		if err == nil && group.HasProxyKey(proxyKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRoutedGroups returns the groups routed for the request model that accept the proxy key, in route order.
+// The model is extracted with each group's own channel so that channel-specific model locations are honoured.
+func (ps *ProxyServer) resolveRoutedGroups(c *gin.Context, proxyKey string, bodyBytes []byte) ([]routedGroup, string, *app_errors.APIError) {
+	var (
+		candidates []routedGroup
+		model      string
+		matched    bool
+	)
+	extracted := make(map[string]string)
+
+	for _, route := range ps.modelRouter.Routes() {
+		group, err := ps.groupManager.GetGroupByID(route.GroupID)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"route_id": route.ID, "group_id": route.GroupID}).Debug("Skipping model route of unknown group")
+			continue
+		}
+
+		channelHandler, err := ps.channelFactory.GetChannel(group)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"group": group.Name, "error": err}).Warn("Skipping model route, failed to get channel")
+			continue
+		}
+
+		requestModel, ok := extracted[group.ChannelType]
+		if !ok {
+			requestModel = channelHandler.ExtractModel(c, bodyBytes)
+			extracted[group.ChannelType] = requestModel
+		}
+		if requestModel != "" && model == "" {
+			model = requestModel
+		}
+		if !route.Matches(requestModel) {
+			continue
+		}
+
+		matched = true
+		if !group.HasProxyKey(proxyKey) {
+			continue
+		}
+		candidates = append(candidates, routedGroup{group: group, channel: channelHandler})
+	}
+
+	switch {
+	case model == "":
+		return nil, "", app_errors.NewAPIError(app_errors.ErrBadRequest, "A model is required to route requests on the unified endpoint")
+	case !matched:
+		return nil, model, app_errors.NewAPIError(app_errors.ErrModelNotRouted, fmt.Sprintf("No group is configured to serve model '%s'", model))
+	case len(candidates) == 0:
+		return nil, model, app_errors.ErrUnauthorized
+	}
+	return candidates, model, nil
+}
+
+// listRoutedModels answers GET /v1/models with the exact route models whose groups accept the proxy key.
+func (ps *ProxyServer) listRoutedModels(c *gin.Context, proxyKey string) {
+	type modelEntry struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		OwnedBy string `json:"owned_by"`
+	}
+
+	seen := make(map[string]struct{})
+	data := make([]modelEntry, 0)
+	for _, route := range ps.modelRouter.Routes() {
+		if route.IsPattern() {
+			continue
+		}
+		if _, ok := seen[route.Model]; ok {
+			continue
+		}
+		group, err := ps.groupManager.GetGroupByID(route.GroupID)
+		if err != nil || !group.HasProxyKey(proxyKey) {
+			continue
+		}
+		seen[route.Model] = struct{}{}
+		data = append(data, modelEntry{ID: route.Model, Object: "model", OwnedBy: group.Name})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": data})
+}
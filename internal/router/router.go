@@ -44,6 +44,7 @@ func NewRouter(
 	registerSystemRoutes(router, serverHandler)
 	registerAPIRoutes(router, serverHandler, configManager, incrementalValidationHandler)
 	registerProxyRoutes(router, proxyServer, groupManager)
+	registerUnifiedProxyRoutes(router, proxyServer)
 
 	// 添加全局中间件和错误处理
 	router.Use(gzip.Gzip(gzip.DefaultCompression))
@@ -103,6 +104,40 @@ func registerProtectedAPIRoutes(api *gin.RouterGroup, serverHandler *handler.Ser
 		groups.DELETE("/:id", serverHandler.DeleteGroup)
 		groups.GET("/:id/stats", serverHandler.GetGroupStats)
 		groups.POST("/:id/copy", serverHandler.CopyGroup)
+		groups.GET("/:id/policies", serverHandler.ListGroupPolicies)
+		groups.POST("/:id/policies", serverHandler.AddGroupPolicy)
+		groups.POST("/:id/policies/simulate", serverHandler.SimulateGroupPolicies)
+		groups.PUT("/:id/policies/:policyId", serverHandler.UpdateGroupPolicy)
+		groups.DELETE("/:id/policies/:policyId", serverHandler.RemoveGroupPolicy)
+	}
+
+	// Policy Management Routes
+	policies := api.Group("/policies")
+	{
+		policies.GET("", serverHandler.ListPolicies)
+		policies.POST("", serverHandler.CreatePolicy)
+		policies.POST("/defaults", serverHandler.CreateDefaultPolicies)
+		policies.GET("/:id", serverHandler.GetPolicy)
+		policies.PUT("/:id", serverHandler.UpdatePolicy)
+		policies.DELETE("/:id", serverHandler.DeletePolicy)
+	}
+
+	// 模型路由
+	modelRoutes := api.Group("/model-routes")
+	{
+		modelRoutes.GET("", serverHandler.ListModelRoutes)
+		modelRoutes.POST("", serverHandler.CreateModelRoute)
+		modelRoutes.PUT("/:id", serverHandler.UpdateModelRoute)
+		modelRoutes.DELETE("/:id", serverHandler.DeleteModelRoute)
+	}
+
+	// 头部规则引用的加密密文
+	secrets := api.Group("/secrets")
+	{
+		secrets.GET("", serverHandler.ListSecrets)
+		secrets.POST("", serverHandler.CreateSecret)
+		secrets.PUT("/:id", serverHandler.UpdateSecret)
+		secrets.DELETE("/:id", serverHandler.DeleteSecret)
 	}
 
 	// Key Management Routes
@@ -120,6 +155,7 @@ func registerProtectedAPIRoutes(api *gin.RouterGroup, serverHandler *handler.Ser
 		keys.POST("/clear-all", serverHandler.ClearAllKeys)
 		keys.POST("/validate-group", serverHandler.ValidateGroupKeys)
 		keys.POST("/test-multiple", serverHandler.TestMultipleKeys)
+		keys.PUT("/:id/weight", serverHandler.UpdateKeyWeight)
 	}
 
 	// Tasks
@@ -168,3 +204,12 @@ func registerProxyRoutes(
 
 	proxyGroup.Any("/:group_name/*path", proxyServer.HandleProxy)
 }
+
+// registerUnifiedProxyRoutes 注册统一入口路由，按请求模型路由到分组
+func registerUnifiedProxyRoutes(router *gin.Engine, proxyServer *proxy.ProxyServer) {
+	unifiedGroup := router.Group("/v1")
+
+	unifiedGroup.Use(middleware.UnifiedProxyAuth())
+
+	unifiedGroup.Any("/*path", proxyServer.HandleUnifiedProxy)
+}
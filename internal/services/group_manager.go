@@ -61,11 +61,33 @@ func (gm *GroupManager) Initialize() error {
 				g.HeaderRuleList = []models.HeaderRule{}
 			}
 
+			if len(group.ResponseHeaderRules) > 0 {
+				if err := json.Unmarshal(group.ResponseHeaderRules, &g.ResponseHeaderRuleList); err != nil {
+					logrus.WithError(err).WithField("group_name", g.Name).Warn("Failed to parse response header rules for group")
+					g.ResponseHeaderRuleList = nil
+				}
+			}
+
+			if len(group.BodyRules) > 0 {
+				if err := json.Unmarshal(group.BodyRules, &g.BodyRuleList); err != nil {
+					logrus.WithError(err).WithField("group_name", g.Name).Warn("Failed to parse body rules for group")
+					g.BodyRuleList = nil
+				}
+			}
+
+			if len(group.FallbackGroups) > 0 {
+				if err := json.Unmarshal(group.FallbackGroups, &g.FallbackGroupIDs); err != nil {
+					logrus.WithError(err).WithField("group_name", g.Name).Warn("Failed to parse fallback groups for group")
+					g.FallbackGroupIDs = nil
+				}
+			}
+
 			groupMap[g.Name] = &g
 			logrus.WithFields(logrus.Fields{
 				"group_name":         g.Name,
 				"effective_config":   g.EffectiveConfig,
 				"header_rules_count": len(g.HeaderRuleList),
+				"body_rules_count":   len(g.BodyRuleList),
 			}).Debug("Loaded group with effective config")
 		}
 
@@ -100,6 +122,20 @@ func (gm *GroupManager) GetGroupByName(name string) (*models.Group, error) {
 	return group, nil
 }
 
+// GetGroupByID retrieves a single group by its ID from the cache.
+func (gm *GroupManager) GetGroupByID(id uint) (*models.Group, error) {
+	if gm.syncer == nil {
+		return nil, fmt.Errorf("GroupManager is not initialized")
+	}
+
+	for _, group := range gm.syncer.Get() {
+		if group.ID == id {
+			return group, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
 // Invalidate triggers a cache reload across all instances.
 func (gm *GroupManager) Invalidate() error {
 	if gm.syncer == nil {
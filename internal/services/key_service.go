@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"gpt-load/internal/encryption"
@@ -168,6 +169,11 @@ func (s *KeyService) ParseKeysFromText(text string) []string {
 		return s.filterValidKeys(keys)
 	}
 
+	// JSON 对象形式的密钥（如服务账号凭据），支持单个、多个拼接或数组形式
+	if objectKeys := parseJSONObjectKeys(text); len(objectKeys) > 0 {
+		return s.filterValidKeys(objectKeys)
+	}
+
 	// 通用解析：通过分隔符分割文本，不使用复杂的正则表达式
 	delimiters := regexp.MustCompile(`[\s,;|\n\r\t]+`)
 	splitKeys := delimiters.Split(strings.TrimSpace(text), -1)
@@ -182,6 +188,43 @@ func (s *KeyService) ParseKeysFromText(text string) []string {
 	return s.filterValidKeys(keys)
 }
 
+// parseJSONObjectKeys parses a text of one or more JSON objects, or an array of them, into compacted keys.
+// It returns nil if the text is not made of JSON objects only.
+func parseJSONObjectKeys(text string) []string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return nil
+	}
+
+	var objects []json.RawMessage
+	decoder := json.NewDecoder(strings.NewReader(text))
+	for decoder.More() {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil
+		}
+		if raw[0] == '[' {
+			var items []json.RawMessage
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil
+			}
+			objects = append(objects, items...)
+		} else {
+			objects = append(objects, raw)
+		}
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		var buf bytes.Buffer
+		if len(object) == 0 || object[0] != '{' || json.Compact(&buf, object) != nil {
+			return nil
+		}
+		keys = append(keys, buf.String())
+	}
+	return keys
+}
+
 // filterValidKeys validates and filters potential API keys
 func (s *KeyService) filterValidKeys(keys []string) []string {
 	var validKeys []string
@@ -201,6 +244,10 @@ func (s *KeyService) isValidKeyFormat(key string) bool {
 		return false
 	}
 
+	if strings.HasPrefix(key, "{") {
+		return json.Valid([]byte(key))
+	}
+
 	validChars := regexp.MustCompile(`^[a-zA-Z0-9_\-./+=:]+$`)
 	return validChars.MatchString(key)
 }
@@ -258,6 +305,11 @@ func (s *KeyService) ClearAllKeys(groupID uint) (int64, error) {
 	return s.KeyProvider.RemoveAllKeys(groupID)
 }
 
+// UpdateKeyWeight sets the weight used by the weighted key selection strategy.
+func (s *KeyService) UpdateKeyWeight(keyID uint, weight int) error {
+	return s.KeyProvider.UpdateKeyWeight(keyID, weight)
+}
+
 // DeleteMultipleKeys handles the business logic of deleting keys from a text block.
 func (s *KeyService) DeleteMultipleKeys(groupID uint, keysText string) (*DeleteKeysResult, error) {
 	keysToDelete := s.ParseKeysFromText(keysText)
@@ -354,7 +406,7 @@ func (s *KeyService) StreamKeysToWriter(groupID uint, statusFilter string, write
 	query := s.DB.Model(&models.APIKey{}).Where("group_id = ?", groupID).Select("id, key_value")
 
 	switch statusFilter {
-	case models.KeyStatusActive, models.KeyStatusInvalid:
+	case models.KeyStatusActive, models.KeyStatusDegraded, models.KeyStatusDisabled, models.KeyStatusInvalid:
 		query = query.Where("status = ?", statusFilter)
 	case "all":
 	default:
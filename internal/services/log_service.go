@@ -48,6 +48,9 @@ func (s *LogService) logFiltersScope(c *gin.Context) func(db *gorm.DB) *gorm.DB
 		if model := c.Query("model"); model != "" {
 			db = db.Where("model LIKE ?", "%"+model+"%")
 		}
+		if upstreamModel := c.Query("upstream_model"); upstreamModel != "" {
+			db = db.Where("upstream_model LIKE ?", "%"+upstreamModel+"%")
+		}
 		if isSuccessStr := c.Query("is_success"); isSuccessStr != "" {
 			if isSuccess, err := strconv.ParseBool(isSuccessStr); err == nil {
 				db = db.Where("is_success = ?", isSuccess)
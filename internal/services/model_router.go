@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gpt-load/internal/models"
+	"gpt-load/internal/store"
+	"gpt-load/internal/syncer"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const ModelRouteUpdateChannel = "model_routes:updated"
+
+// ModelRouter caches the model routing table used by the unified endpoint.
+type ModelRouter struct {
+	syncer *syncer.CacheSyncer[[]models.ModelRoute]
+	db     *gorm.DB
+	store  store.Store
+}
+
+// NewModelRouter creates a new, uninitialized ModelRouter.
+func NewModelRouter(db *gorm.DB, store store.Store) *ModelRouter {
+	return &ModelRouter{
+		db:    db,
+		store: store,
+	}
+}
+
+// Initialize sets up the CacheSyncer for the routing table.
+func (mr *ModelRouter) Initialize() error {
+	loader := func() ([]models.ModelRoute, error) {
+		var routes []models.ModelRoute
+		if err := mr.db.Where("is_active = ?", true).Find(&routes).Error; err != nil {
+			return nil, fmt.Errorf("failed to load model routes from db: %w", err)
+		}
+		SortModelRoutes(routes)
+		return routes, nil
+	}
+
+	syncer, err := syncer.NewCacheSyncer(
+		loader,
+		mr.store,
+		ModelRouteUpdateChannel,
+		logrus.WithField("syncer", "model_routes"),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create model route syncer: %w", err)
+	}
+	mr.syncer = syncer
+	return nil
+}
+
+// Routes returns all active routes in resolution order.
+func (mr *ModelRouter) Routes() []models.ModelRoute {
+	if mr.syncer == nil {
+		return nil
+	}
+	return mr.syncer.Get()
+}
+
+// Match returns the active routes serving the given model, in resolution order.
+func (mr *ModelRouter) Match(model string) []models.ModelRoute {
+	var matched []models.ModelRoute
+	for _, route := range mr.Routes() {
+		if route.Matches(model) {
+			matched = append(matched, route)
+		}
+	}
+	return matched
+}
+
+// Invalidate triggers a cache reload across all instances.
+func (mr *ModelRouter) Invalidate() error {
+	if mr.syncer == nil {
+		return fmt.Errorf("ModelRouter is not initialized")
+	}
+	return mr.syncer.Invalidate()
+}
+
+// Stop gracefully stops the ModelRouter's background syncer.
+func (mr *ModelRouter) Stop(ctx context.Context) {
+	if mr.syncer != nil {
+		mr.syncer.Stop()
+	}
+}
+
+// SortModelRoutes orders routes by priority, preferring exact models over patterns on ties.
+func SortModelRoutes(routes []models.ModelRoute) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Priority != routes[j].Priority {
+			return routes[i].Priority < routes[j].Priority
+		}
+		if routes[i].IsPattern() != routes[j].IsPattern() {
+			return !routes[i].IsPattern()
+		}
+		return routes[i].ID < routes[j].ID
+	})
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"gpt-load/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortModelRoutes(t *testing.T) {
+	routes := []models.ModelRoute{
+		{ID: 1, Model: "gpt-*", GroupID: 1, Priority: 0},
+		{ID: 2, Model: "gpt-4o", GroupID: 2, Priority: 1},
+		{ID: 3, Model: "gpt-4o", GroupID: 3, Priority: 0},
+		{ID: 4, Model: "claude-*", GroupID: 4, Priority: 0},
+	}
+
+	SortModelRoutes(routes)
+
+	var ids []uint
+	for _, route := range routes {
+		ids = append(ids, route.ID)
+	}
+	// 同优先级下精确模型优先于通配模式，其余按 ID 排序
+	assert.Equal(t, []uint{3, 1, 4, 2}, ids)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gpt-load/internal/encryption"
+	"gpt-load/internal/models"
+	"gpt-load/internal/store"
+	"gpt-load/internal/syncer"
+	"gpt-load/internal/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const SecretUpdateChannel = "secrets:updated"
+
+// SecretManager caches the decrypted secrets that header rules reference through ${SECRET:name}.
+type SecretManager struct {
+	syncer        *syncer.CacheSyncer[map[string]string]
+	db            *gorm.DB
+	store         store.Store
+	encryptionSvc encryption.Service
+}
+
+// NewSecretManager creates a new, uninitialized SecretManager.
+func NewSecretManager(db *gorm.DB, store store.Store, encryptionSvc encryption.Service) *SecretManager {
+	return &SecretManager{
+		db:            db,
+		store:         store,
+		encryptionSvc: encryptionSvc,
+	}
+}
+
+// Initialize sets up the CacheSyncer for the secrets and registers the manager as the header variable secret resolver.
+func (sm *SecretManager) Initialize() error {
+	loader := func() (map[string]string, error) {
+		var secrets []models.Secret
+		if err := sm.db.Find(&secrets).Error; err != nil {
+			return nil, fmt.Errorf("failed to load secrets from db: %w", err)
+		}
+
+		values := make(map[string]string, len(secrets))
+		for _, secret := range secrets {
+			value, err := sm.encryptionSvc.Decrypt(secret.Value)
+			if err != nil {
+				logrus.WithError(err).WithField("secret", secret.Name).Error("Failed to decrypt secret, skipping")
+				continue
+			}
+			values[secret.Name] = value
+		}
+		return values, nil
+	}
+
+	syncer, err := syncer.NewCacheSyncer(
+		loader,
+		sm.store,
+		SecretUpdateChannel,
+		logrus.WithField("syncer", "secrets"),
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create secret syncer: %w", err)
+	}
+	sm.syncer = syncer
+	utils.SetSecretResolver(sm.Resolve)
+	return nil
+}
+
+// Resolve returns the decrypted value of the named secret.
+func (sm *SecretManager) Resolve(name string) (string, bool) {
+	if sm.syncer == nil {
+		return "", false
+	}
+	value, ok := sm.syncer.Get()[name]
+	return value, ok
+}
+
+// Invalidate triggers a cache reload across all instances.
+func (sm *SecretManager) Invalidate() error {
+	if sm.syncer == nil {
+		return fmt.Errorf("SecretManager is not initialized")
+	}
+	return sm.syncer.Invalidate()
+}
+
+// Stop gracefully stops the SecretManager's background syncer.
+func (sm *SecretManager) Stop(ctx context.Context) {
+	if sm.syncer != nil {
+		sm.syncer.Stop()
+	}
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -13,12 +14,30 @@ type memoryStoreItem struct {
 	expiresAt int64 // Unix-nano timestamp. 0 for no expiry.
 }
 
+// memoryTokenBucket holds the state of a token bucket.
+type memoryTokenBucket struct {
+	tokens    float64
+	updatedAt int64 // Unix-nano timestamp of the last refill.
+	expiresAt int64 // Unix-nano timestamp one interval after the last take, when the bucket is full again.
+}
+
+// memorySlotCounter holds the state of a concurrency counter.
+type memorySlotCounter struct {
+	count     int64
+	expiresAt int64 // Unix-nano timestamp, refreshed on every acquire.
+}
+
+// memoryExpirySweepInterval is how often expired keys are removed from a MemoryStore.
+const memoryExpirySweepInterval = time.Minute
+
 // MemoryStore is an in-memory key-value store that is safe for concurrent use.
 type MemoryStore struct {
 	mu            sync.RWMutex
 	data          map[string]any
 	muSubscribers sync.RWMutex
 	subscribers   map[string]map[chan *Message]struct{}
+	stopSweep     chan struct{}
+	closeOnce     sync.Once
 }
 
 // NewMemoryStore creates and returns a new MemoryStore instance.
@@ -26,15 +45,56 @@ func NewMemoryStore() *MemoryStore {
 	s := &MemoryStore{
 		data:        make(map[string]any),
 		subscribers: make(map[string]map[chan *Message]struct{}),
+		stopSweep:   make(chan struct{}),
 	}
+	go s.sweepExpired()
 	return s
 }
 
 // Close cleans up resources.
 func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stopSweep)
+	})
 	return nil
 }
 
+// sweepExpired periodically removes expired keys, which are otherwise only dropped when read again.
+func (s *MemoryStore) sweepExpired() {
+	ticker := time.NewTicker(memoryExpirySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deleteExpired(time.Now().UnixNano())
+		case <-s.stopSweep:
+			return
+		}
+	}
+}
+
+// deleteExpired removes all keys that expired before now.
+func (s *MemoryStore) deleteExpired(now int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, rawValue := range s.data {
+		var expiresAt int64
+		switch v := rawValue.(type) {
+		case memoryStoreItem:
+			expiresAt = v.expiresAt
+		case *memoryTokenBucket:
+			expiresAt = v.expiresAt
+		case *memorySlotCounter:
+			expiresAt = v.expiresAt
+		}
+		if expiresAt > 0 && now > expiresAt {
+			delete(s.data, key)
+		}
+	}
+}
+
 // Set stores a key-value pair.
 func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
 	s.mu.Lock()
@@ -217,6 +277,30 @@ func (s *MemoryStore) HIncrBy(key, field string, incr int64) (int64, error) {
 	return newVal, nil
 }
 
+func (s *MemoryStore) HCompareAndSet(key, field, expected, value string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var hash map[string]string
+	rawHash, exists := s.data[key]
+	if !exists {
+		hash = make(map[string]string)
+	} else {
+		var ok bool
+		hash, ok = rawHash.(map[string]string)
+		if !ok {
+			return false, fmt.Errorf("type mismatch: key '%s' holds a different data type", key)
+		}
+	}
+
+	if hash[field] != expected {
+		return false, nil
+	}
+	hash[field] = value
+	s.data[key] = hash
+	return true, nil
+}
+
 // --- LIST operations ---
 
 func (s *MemoryStore) LPush(key string, values ...any) error {
@@ -302,6 +386,40 @@ func (s *MemoryStore) Rotate(key string) (string, error) {
 	return item, nil
 }
 
+// LRange returns the elements between start and stop (inclusive), supporting negative indexes like Redis.
+func (s *MemoryStore) LRange(key string, start, stop int64) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rawList, exists := s.data[key]
+	if !exists {
+		return []string{}, nil
+	}
+
+	list, ok := rawList.([]string)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: key '%s' holds a different data type", key)
+	}
+
+	length := int64(len(list))
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if stop < 0 {
+		stop = length + stop
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop {
+		return []string{}, nil
+	}
+
+	result := make([]string, stop-start+1)
+	copy(result, list[start:stop+1])
+	return result, nil
+}
+
 // --- SET operations ---
 
 // SAdd adds members to a set.
@@ -359,6 +477,113 @@ func (s *MemoryStore) SPopN(key string, count int64) ([]string, error) {
 	return popped, nil
 }
 
+// --- Token bucket operations ---
+
+// TakeToken atomically consumes one token from the bucket stored at key.
+func (s *MemoryStore) TakeToken(key string, capacity int64, interval time.Duration) (bool, time.Duration, error) {
+	if capacity <= 0 || interval <= 0 {
+		return false, 0, fmt.Errorf("invalid token bucket parameters: capacity=%d, interval=%s", capacity, interval)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	rate := float64(capacity) / float64(interval.Nanoseconds())
+
+	var bucket *memoryTokenBucket
+	if rawBucket, exists := s.data[key]; exists {
+		var ok bool
+		bucket, ok = rawBucket.(*memoryTokenBucket)
+		if !ok {
+			return false, 0, fmt.Errorf("type mismatch: key '%s' holds a different data type", key)
+		}
+		if now > bucket.expiresAt {
+			bucket = nil
+		}
+	}
+	if bucket == nil {
+		bucket = &memoryTokenBucket{tokens: float64(capacity), updatedAt: now}
+		s.data[key] = bucket
+	}
+
+	if elapsed := now - bucket.updatedAt; elapsed > 0 {
+		bucket.tokens = min(float64(capacity), bucket.tokens+float64(elapsed)*rate)
+	}
+	bucket.updatedAt = now
+	// Like the Redis PEXPIRE, an idle bucket is full after one interval and can be dropped.
+	bucket.expiresAt = now + interval.Nanoseconds()
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+
+	wait := time.Duration(math.Ceil((1 - bucket.tokens) / rate))
+	return false, wait, nil
+}
+
+// AcquireSlot atomically increments the counter stored at key if it is below limit.
+func (s *MemoryStore) AcquireSlot(key string, limit int64, ttl time.Duration) (bool, error) {
+	if limit <= 0 || ttl <= 0 {
+		return false, fmt.Errorf("invalid slot parameters: limit=%d, ttl=%s", limit, ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	counter, err := s.slotCounter(key, now)
+	if err != nil {
+		return false, err
+	}
+	if counter == nil {
+		counter = &memorySlotCounter{}
+		s.data[key] = counter
+	}
+
+	if counter.count >= limit {
+		return false, nil
+	}
+	counter.count++
+	counter.expiresAt = now + ttl.Nanoseconds()
+	return true, nil
+}
+
+// ReleaseSlot decrements the counter stored at key, never going below zero.
+func (s *MemoryStore) ReleaseSlot(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, err := s.slotCounter(key, time.Now().UnixNano())
+	if err != nil || counter == nil {
+		return err
+	}
+	if counter.count <= 1 {
+		delete(s.data, key)
+	} else {
+		counter.count--
+	}
+	return nil
+}
+
+// slotCounter returns the live counter stored at key, or nil if it is missing or expired. The caller must hold the lock.
+func (s *MemoryStore) slotCounter(key string, now int64) (*memorySlotCounter, error) {
+	rawCounter, exists := s.data[key]
+	if !exists {
+		return nil, nil
+	}
+	counter, ok := rawCounter.(*memorySlotCounter)
+	if !ok {
+		return nil, fmt.Errorf("type mismatch: key '%s' holds a different data type", key)
+	}
+	if now > counter.expiresAt {
+		delete(s.data, key)
+		return nil, nil
+	}
+	return counter, nil
+}
+
 // --- Pub/Sub operations ---
 
 // memorySubscription implements the Subscription interface for the in-memory store.
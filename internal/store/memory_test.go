@@ -239,6 +239,34 @@ func TestMemoryStore_HIncrBy(t *testing.T) {
 	})
 }
 
+func TestMemoryStore_HCompareAndSet(t *testing.T) {
+	store := NewMemoryStore()
+
+	t.Run("missing field matches empty expected", func(t *testing.T) {
+		set, err := store.HCompareAndSet("cas-key", "used", "", "1")
+		assert.NoError(t, err)
+		assert.True(t, set)
+
+		hash, err := store.HGetAll("cas-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", hash["used"])
+	})
+
+	t.Run("stale expected value is rejected", func(t *testing.T) {
+		set, err := store.HCompareAndSet("cas-key", "used", "", "2")
+		assert.NoError(t, err)
+		assert.False(t, set)
+
+		set, err = store.HCompareAndSet("cas-key", "used", "1", "2")
+		assert.NoError(t, err)
+		assert.True(t, set)
+
+		hash, err := store.HGetAll("cas-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "2", hash["used"])
+	})
+}
+
 func TestMemoryStore_ListOperations(t *testing.T) {
 	store := NewMemoryStore()
 
@@ -297,6 +325,23 @@ func TestMemoryStore_ListOperations(t *testing.T) {
 		assert.Contains(t, values, "d")
 		assert.NotContains(t, values, "b")
 	})
+
+	t.Run("lrange", func(t *testing.T) {
+		key := "lrange-list"
+		store.LPush(key, "c", "b", "a")
+
+		all, err := store.LRange(key, 0, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"c", "b", "a"}, all)
+
+		tail, err := store.LRange(key, -2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b", "a"}, tail)
+
+		empty, err := store.LRange("missing-list", 0, -1)
+		assert.NoError(t, err)
+		assert.Empty(t, empty)
+	})
 }
 
 func TestMemoryStore_SetOperations(t *testing.T) {
@@ -330,6 +375,56 @@ func TestMemoryStore_SetOperations(t *testing.T) {
 	})
 }
 
+func TestMemoryStore_TakeToken(t *testing.T) {
+	store := NewMemoryStore()
+
+	t.Run("consume until empty", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			allowed, _, err := store.TakeToken("bucket", 3, time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+		}
+
+		allowed, wait, err := store.TakeToken("bucket", 3, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Greater(t, wait, time.Duration(0))
+		assert.LessOrEqual(t, wait, 20*time.Second)
+	})
+
+	t.Run("refill over time", func(t *testing.T) {
+		allowed, _, err := store.TakeToken("fast-bucket", 1, 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, _, _ = store.TakeToken("fast-bucket", 1, 50*time.Millisecond)
+		assert.False(t, allowed)
+
+		time.Sleep(60 * time.Millisecond)
+		allowed, _, err = store.TakeToken("fast-bucket", 1, 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, _, err := store.TakeToken("bad-bucket", 0, time.Minute)
+		assert.Error(t, err)
+	})
+
+	t.Run("idle buckets are swept", func(t *testing.T) {
+		_, _, err := store.TakeToken("idle-bucket", 1, time.Minute)
+		assert.NoError(t, err)
+
+		store.deleteExpired(time.Now().UnixNano())
+		exists, _ := store.Exists("idle-bucket")
+		assert.True(t, exists)
+
+		store.deleteExpired(time.Now().Add(time.Minute + time.Second).UnixNano())
+		exists, _ = store.Exists("idle-bucket")
+		assert.False(t, exists)
+	})
+}
+
 func TestMemoryStore_PubSub(t *testing.T) {
 	store := NewMemoryStore()
 
@@ -447,3 +542,45 @@ func TestMemoryStore_ConcurrentAccess(t *testing.T) {
 	<-done
 	<-done
 }
+
+func TestMemoryStore_Slots(t *testing.T) {
+	store := NewMemoryStore()
+
+	t.Run("acquire up to limit and release", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			acquired, err := store.AcquireSlot("slots", 2, time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, acquired)
+		}
+
+		acquired, err := store.AcquireSlot("slots", 2, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+
+		assert.NoError(t, store.ReleaseSlot("slots"))
+		acquired, err = store.AcquireSlot("slots", 2, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+
+	t.Run("release never goes below zero", func(t *testing.T) {
+		assert.NoError(t, store.ReleaseSlot("empty-slots"))
+		assert.NoError(t, store.ReleaseSlot("empty-slots"))
+
+		acquired, err := store.AcquireSlot("empty-slots", 1, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+		acquired, _ = store.AcquireSlot("empty-slots", 1, time.Minute)
+		assert.False(t, acquired)
+	})
+
+	t.Run("leaked slots expire", func(t *testing.T) {
+		acquired, _ := store.AcquireSlot("leaky-slots", 1, 20*time.Millisecond)
+		assert.True(t, acquired)
+
+		time.Sleep(30 * time.Millisecond)
+		acquired, err := store.AcquireSlot("leaky-slots", 1, 20*time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
@@ -96,6 +96,24 @@ func (s *RedisStore) HGetAll(key string) (map[string]string, error) {
 	return s.client.HGetAll(context.Background(), s.prefixKey(key)).Result()
 }
 
+// hCompareAndSetScript sets a hash field only while it still holds the expected value.
+var hCompareAndSetScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1]) or ''
+if current ~= ARGV[2] then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+return 1
+`)
+
+func (s *RedisStore) HCompareAndSet(key, field, expected, value string) (bool, error) {
+	res, err := hCompareAndSetScript.Run(context.Background(), s.client, []string{s.prefixKey(key)}, field, expected, value).Int64()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
 func (s *RedisStore) HIncrBy(key, field string, incr int64) (int64, error) {
 	return s.client.HIncrBy(context.Background(), s.prefixKey(key), field, incr).Result()
 }
@@ -122,6 +140,10 @@ func (s *RedisStore) Rotate(key string) (string, error) {
 	return val, nil
 }
 
+func (s *RedisStore) LRange(key string, start, stop int64) ([]string, error) {
+	return s.client.LRange(context.Background(), s.prefixKey(key), start, stop).Result()
+}
+
 // --- SET operations ---
 
 func (s *RedisStore) SAdd(key string, members ...any) error {
@@ -132,6 +154,97 @@ func (s *RedisStore) SPopN(key string, count int64) ([]string, error) {
 	return s.client.SPopN(context.Background(), s.prefixKey(key), count).Result()
 }
 
+// --- Token bucket operations ---
+
+// takeTokenScript refills and consumes a token bucket atomically.
+// Redis server time is used so that all nodes share the same clock.
+var takeTokenScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+local rate = capacity / interval
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], interval)
+return {allowed, wait}
+`)
+
+// TakeToken atomically consumes one token from the bucket stored at key.
+func (s *RedisStore) TakeToken(key string, capacity int64, interval time.Duration) (bool, time.Duration, error) {
+	intervalMs := interval.Milliseconds()
+	if capacity <= 0 || intervalMs <= 0 {
+		return false, 0, fmt.Errorf("invalid token bucket parameters: capacity=%d, interval=%s", capacity, interval)
+	}
+
+	res, err := takeTokenScript.Run(context.Background(), s.client, []string{s.prefixKey(key)}, capacity, intervalMs).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket result: %v", res)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// acquireSlotScript increments a concurrency counter only while it is below the limit.
+var acquireSlotScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// releaseSlotScript decrements a concurrency counter and removes it once it reaches zero.
+var releaseSlotScript = redis.NewScript(`
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+if count <= 1 then
+	redis.call('DEL', KEYS[1])
+else
+	redis.call('DECR', KEYS[1])
+end
+return 0
+`)
+
+// AcquireSlot atomically increments the counter stored at key if it is below limit.
+func (s *RedisStore) AcquireSlot(key string, limit int64, ttl time.Duration) (bool, error) {
+	if limit <= 0 || ttl.Milliseconds() <= 0 {
+		return false, fmt.Errorf("invalid slot parameters: limit=%d, ttl=%s", limit, ttl)
+	}
+
+	res, err := acquireSlotScript.Run(context.Background(), s.client, []string{s.prefixKey(key)}, limit, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// ReleaseSlot decrements the counter stored at key, never going below zero.
+func (s *RedisStore) ReleaseSlot(key string) error {
+	return releaseSlotScript.Run(context.Background(), s.client, []string{s.prefixKey(key)}).Err()
+}
+
 // --- Pipeliner implementation ---
 
 type redisPipeliner struct {
@@ -44,16 +44,31 @@ type Store interface {
 	HSet(key string, values map[string]any) error
 	HGetAll(key string) (map[string]string, error)
 	HIncrBy(key, field string, incr int64) (int64, error)
+	// HCompareAndSet atomically sets field to value if its current value equals expected,
+	// an empty expected matching a missing field. It reports whether the field was set.
+	HCompareAndSet(key, field, expected, value string) (bool, error)
 
 	// LIST operations
 	LPush(key string, values ...any) error
 	LRem(key string, count int64, value any) error
 	Rotate(key string) (string, error)
+	LRange(key string, start, stop int64) ([]string, error)
 
 	// SET operations
 	SAdd(key string, members ...any) error
 	SPopN(key string, count int64) ([]string, error)
 
+	// TakeToken atomically consumes one token from the bucket stored at key.
+	// The bucket holds up to capacity tokens and is refilled at capacity per interval.
+	// It returns whether a token was taken and, if not, how long until one is available.
+	TakeToken(key string, capacity int64, interval time.Duration) (bool, time.Duration, error)
+
+	// AcquireSlot atomically increments the counter stored at key if it is below limit.
+	// The counter expires after ttl without activity, so slots held by a crashed node are eventually freed.
+	AcquireSlot(key string, limit int64, ttl time.Duration) (bool, error)
+	// ReleaseSlot decrements the counter stored at key, never going below zero.
+	ReleaseSlot(key string) error
+
 	// Close closes the store and releases any underlying resources.
 	Close() error
 
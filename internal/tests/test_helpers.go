@@ -113,6 +113,18 @@ func (m *MockMemoryStore) HIncrBy(key, field string, incr int64) (int64, error)
 	return incr, nil
 }
 
+func (m *MockMemoryStore) HCompareAndSet(key, field, expected, value string) (bool, error) {
+	return true, nil
+}
+
+func (m *MockMemoryStore) AcquireSlot(key string, limit int64, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (m *MockMemoryStore) ReleaseSlot(key string) error {
+	return nil
+}
+
 func (m *MockMemoryStore) LPush(key string, values ...any) error {
 	return nil
 }
@@ -125,6 +137,10 @@ func (m *MockMemoryStore) Rotate(key string) (string, error) {
 	return "", store.ErrNotFound
 }
 
+func (m *MockMemoryStore) LRange(key string, start, stop int64) ([]string, error) {
+	return []string{}, nil
+}
+
 func (m *MockMemoryStore) SAdd(key string, members ...any) error {
 	return nil
 }
@@ -133,6 +149,10 @@ func (m *MockMemoryStore) SPopN(key string, count int64) ([]string, error) {
 	return []string{}, nil
 }
 
+func (m *MockMemoryStore) TakeToken(key string, capacity int64, interval time.Duration) (bool, time.Duration, error) {
+	return true, 0, nil
+}
+
 func (m *MockMemoryStore) Close() error {
 	return nil
 }
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gpt-load/internal/models"
+	"path"
+)
+
+// ValidateBodyRule checks that a body rule has a known action, a valid path and the arguments its action needs.
+func ValidateBodyRule(rule models.BodyRule) error {
+	if _, err := SplitJSONPath(rule.Path); err != nil {
+		return err
+	}
+
+	switch rule.Action {
+	case models.BodyRuleActionSet, models.BodyRuleActionSetDefault:
+		if rule.Value == nil {
+			return fmt.Errorf("action %q requires a value", rule.Action)
+		}
+	case models.BodyRuleActionDelete:
+	case models.BodyRuleActionClamp:
+		if rule.Min == nil && rule.Max == nil {
+			return errors.New("action \"clamp\" requires min or max")
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+			return errors.New("min cannot be greater than max")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+
+	for _, patterns := range [][]string{rule.Models, rule.Paths} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("invalid pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyBodyRules applies the rules scoped to the model and request path to a JSON object body, in order.
+// Numbers are kept verbatim unless a rule changes them. Rules that cannot be applied, such as a path
+// through a scalar value, are skipped. An error is returned only when the body is not a JSON object.
+func ApplyBodyRules(bodyBytes []byte, rules []models.BodyRule, model, requestPath string) ([]byte, error) {
+	var applicable []models.BodyRule
+	for i := range rules {
+		if rules[i].Applies(model, requestPath) {
+			applicable = append(applicable, rules[i])
+		}
+	}
+	if len(applicable) == 0 || len(bodyBytes) == 0 {
+		return bodyBytes, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(bodyBytes))
+	decoder.UseNumber()
+	var data map[string]any
+	if err := decoder.Decode(&data); err != nil {
+		return bodyBytes, fmt.Errorf("failed to decode request body: %w", err)
+	}
+	if data == nil {
+		return bodyBytes, errors.New("request body is not a JSON object")
+	}
+
+	for _, rule := range applicable {
+		switch rule.Action {
+		case models.BodyRuleActionSet:
+			_ = SetJSONPath(data, rule.Path, rule.Value)
+		case models.BodyRuleActionSetDefault:
+			if _, ok := GetJSONPath(data, rule.Path); !ok {
+				_ = SetJSONPath(data, rule.Path, rule.Value)
+			}
+		case models.BodyRuleActionDelete:
+			DeleteJSONPath(data, rule.Path)
+		case models.BodyRuleActionClamp:
+			clampJSONPath(data, rule)
+		}
+	}
+
+	return json.Marshal(data)
+}
+
+// clampJSONPath limits the number at the rule's path to the rule's bounds. Non-numeric values are left unchanged.
+func clampJSONPath(data map[string]any, rule models.BodyRule) {
+	value, ok := GetJSONPath(data, rule.Path)
+	if !ok {
+		return
+	}
+	var number float64
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return
+		}
+		number = f
+	case float64:
+		number = v
+	default:
+		return
+	}
+
+	if rule.Max != nil && number > *rule.Max {
+		_ = SetJSONPath(data, rule.Path, *rule.Max)
+	} else if rule.Min != nil && number < *rule.Min {
+		_ = SetJSONPath(data, rule.Path, *rule.Min)
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+
+	"gpt-load/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestApplyBodyRules(t *testing.T) {
+	rules := []models.BodyRule{
+		{Action: models.BodyRuleActionSetDefault, Path: "temperature", Value: 0.7},
+		{Action: models.BodyRuleActionSetDefault, Path: "top_p", Value: 0.9},
+		{Action: models.BodyRuleActionClamp, Path: "max_tokens", Min: float64Ptr(1), Max: float64Ptr(4096)},
+		{Action: models.BodyRuleActionDelete, Path: "user"},
+		{Action: models.BodyRuleActionSet, Path: "metadata.source", Value: "gpt-load"},
+		{Action: models.BodyRuleActionDelete, Path: "reasoning_effort", Models: []string{"gpt-4o*"}},
+		{Action: models.BodyRuleActionSet, Path: "stream_options.include_usage", Value: true, Paths: []string{"/v1/chat/*"}},
+	}
+
+	body := []byte(`{"model":"gpt-4o-mini","top_p":1,"max_tokens":100000,"user":"u1","reasoning_effort":"low","seed":12345678901234567890}`)
+	result, err := ApplyBodyRules(body, rules, "gpt-4o-mini", "/v1/chat/completions")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"model": "gpt-4o-mini",
+		"temperature": 0.7,
+		"top_p": 1,
+		"max_tokens": 4096,
+		"metadata": {"source": "gpt-load"},
+		"stream_options": {"include_usage": true},
+		"seed": 12345678901234567890
+	}`, string(result))
+	assert.Contains(t, string(result), `"seed":12345678901234567890`)
+
+	result, err = ApplyBodyRules([]byte(`{"max_tokens":0,"reasoning_effort":"low"}`), rules, "o3", "/v1/responses")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"max_tokens":1,"reasoning_effort":"low","temperature":0.7,"top_p":0.9,"metadata":{"source":"gpt-load"}}`, string(result))
+}
+
+func TestApplyBodyRules_PassThrough(t *testing.T) {
+	rules := []models.BodyRule{{Action: models.BodyRuleActionSet, Path: "a", Value: 1, Models: []string{"m1"}}}
+
+	body := []byte(`{"b":1}`)
+	result, err := ApplyBodyRules(body, rules, "m2", "/v1/chat/completions")
+	require.NoError(t, err)
+	assert.Equal(t, body, result)
+
+	_, err = ApplyBodyRules([]byte(`[1,2]`), rules, "m1", "/v1/chat/completions")
+	assert.Error(t, err)
+	_, err = ApplyBodyRules([]byte(`null`), rules, "m1", "/v1/chat/completions")
+	assert.Error(t, err)
+
+	// 无法应用的规则被跳过，其余规则照常生效
+	rules = []models.BodyRule{
+		{Action: models.BodyRuleActionSet, Path: "a.b", Value: 1},
+		{Action: models.BodyRuleActionClamp, Path: "c", Max: float64Ptr(1)},
+		{Action: models.BodyRuleActionSet, Path: "d", Value: "x"},
+	}
+	result, err = ApplyBodyRules([]byte(`{"a":"scalar","c":"text"}`), rules, "", "/")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":"scalar","c":"text","d":"x"}`, string(result))
+}
+
+func TestValidateBodyRule(t *testing.T) {
+	assert.NoError(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionSet, Path: "a.b", Value: 1}))
+	assert.NoError(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionDelete, Path: "a", Models: []string{"gpt-*"}}))
+	assert.NoError(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionClamp, Path: "a", Max: float64Ptr(1)}))
+
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: "rename", Path: "a"}))
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionSet, Path: "a"}))
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionDelete, Path: "a."}))
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionClamp, Path: "a"}))
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionClamp, Path: "a", Min: float64Ptr(2), Max: float64Ptr(1)}))
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionDelete, Path: "a", Paths: []string{"/v1/["}}))
+	assert.Error(t, ValidateBodyRule(models.BodyRule{Action: models.BodyRuleActionDelete, Path: "a", Models: []string{""}}))
+}
@@ -34,6 +34,7 @@ func GenerateSettingsMetadata(s *types.SystemSettings) []models.SystemSettingInf
 		categoryTag := field.Tag.Get("category")
 
 		var minValue *int
+		var options []string
 		var required bool
 
 		rules := strings.Split(validateTag, ",")
@@ -46,6 +47,8 @@ func GenerateSettingsMetadata(s *types.SystemSettings) []models.SystemSettingInf
 				if val, err := strconv.Atoi(valStr); err == nil {
 					minValue = &val
 				}
+			} else if strings.HasPrefix(rule, "oneof=") {
+				options = strings.Fields(strings.TrimPrefix(rule, "oneof="))
 			}
 		}
 
@@ -58,6 +61,7 @@ func GenerateSettingsMetadata(s *types.SystemSettings) []models.SystemSettingInf
 			Description:  descTag,
 			Category:     categoryTag,
 			MinValue:     minValue,
+			Options:      options,
 			Required:     required,
 		}
 		settingsInfo = append(settingsInfo, info)
@@ -3,67 +3,191 @@ package utils
 import (
 	"gpt-load/internal/models"
 	"net/http"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 )
 
+// RequestIDContextKey is the gin context key holding the ID of a proxy request.
+const RequestIDContextKey = "requestID"
+
 // HeaderVariableContext holds context data for variable resolution
 type HeaderVariableContext struct {
-	ClientIP string
-	Group    *models.Group
-	APIKey   *models.APIKey
+	ClientIP      string
+	Group         *models.Group
+	APIKey        *models.APIKey
+	AttemptCount  int            // 响应头变量：本次请求的上游尝试次数，含回退分组
+	ServedGroup   string         // 响应头变量：实际处理请求的分组，发生回退时为回退分组
+	RequestID     string         // 本次代理请求的 ID
+	Model         string         // 请求的模型，校验请求为分组的测试模型
+	ProxyKey      string         // 客户端使用的代理密钥，仅以掩码形式暴露为 ${PROXY_KEY_NAME}
+	UpstreamHost  string         // 未设置时由 ApplyHeaderRules 取自请求 URL
+	RequestHeader http.Header    // 客户端原始请求头，供 ${HEADER:Name} 引用
+	Secrets       SecretResolver // 为 nil 时 ${SECRET:name} 解析为空
 }
 
-// ResolveHeaderVariables resolves dynamic variables in header values
+// SecretResolver returns the decrypted value of a named secret.
+type SecretResolver func(name string) (string, bool)
+
+var (
+	secretResolverMu sync.RWMutex
+	secretResolver   SecretResolver
+)
+
+// SetSecretResolver sets the resolver given to new header variable contexts for ${SECRET:name}.
+func SetSecretResolver(resolver SecretResolver) {
+	secretResolverMu.Lock()
+	defer secretResolverMu.Unlock()
+	secretResolver = resolver
+}
+
+func defaultSecretResolver() SecretResolver {
+	secretResolverMu.RLock()
+	defer secretResolverMu.RUnlock()
+	return secretResolver
+}
+
+// clientAuthHeaders carry the client's proxy key; ${HEADER:Name} never copies them.
+var clientAuthHeaders = []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key"}
+
+// IsClientAuthHeader reports whether the named request header carries the client's proxy key.
+func IsClientAuthHeader(name string) bool {
+	return slices.Contains(clientAuthHeaders, http.CanonicalHeaderKey(strings.TrimSpace(name)))
+}
+
+// ClientAuthHeaderReference returns the client auth header a header value references through ${HEADER:Name}, if any.
+func ClientAuthHeaderReference(value string) (string, bool) {
+	for _, parts := range headerVariablePattern.FindAllStringSubmatch(value, -1) {
+		if parts[1] == "HEADER" && IsClientAuthHeader(parts[2]) {
+			return http.CanonicalHeaderKey(strings.TrimSpace(parts[2])), true
+		}
+	}
+	return "", false
+}
+
+// headerVariablePattern matches ${NAME} and ${NAME:argument} variables.
+var headerVariablePattern = regexp.MustCompile(`\$\{([A-Z_]+)(?::([^}]*))?\}`)
+
+// ResolveHeaderVariables resolves dynamic variables in header values.
+// Variables are replaced in a single pass, so values taken from client headers are never expanded again.
+// Unknown variables and variables without a value in the context are left unchanged.
 func ResolveHeaderVariables(value string, ctx *HeaderVariableContext) string {
-	if ctx == nil {
+	if ctx == nil || !strings.Contains(value, "${") {
 		return value
 	}
 
 	now := time.Now()
-	result := value
 
 	// Replace all supported variables
 	variables := map[string]string{
-		"${CLIENT_IP}":    ctx.ClientIP,
-		"${TIMESTAMP_MS}": strconv.FormatInt(now.UnixMilli(), 10),
-		"${TIMESTAMP_S}":  strconv.FormatInt(now.Unix(), 10),
+		"CLIENT_IP":    ctx.ClientIP,
+		"TIMESTAMP_MS": strconv.FormatInt(now.UnixMilli(), 10),
+		"TIMESTAMP_S":  strconv.FormatInt(now.Unix(), 10),
 	}
 
 	if ctx.Group != nil {
-		variables["${GROUP_NAME}"] = ctx.Group.Name
+		variables["GROUP_NAME"] = ctx.Group.Name
 	}
 
 	if ctx.APIKey != nil {
-		variables["${API_KEY}"] = ctx.APIKey.KeyValue
+		variables["API_KEY"] = ctx.APIKey.KeyValue
+		if ctx.APIKey.ID != 0 {
+			variables["KEY_ID"] = strconv.FormatUint(uint64(ctx.APIKey.ID), 10)
+		}
 	}
 
-	// Replace variables in the value
-	for variable, replacement := range variables {
-		result = strings.ReplaceAll(result, variable, replacement)
+	// 响应头上下文（设置了 ServedGroup）中未发出上游请求时为 0
+	if ctx.AttemptCount > 0 || ctx.ServedGroup != "" {
+		variables["ATTEMPT_COUNT"] = strconv.Itoa(ctx.AttemptCount)
 	}
 
-	return result
+	for name, replacement := range map[string]string{
+		"REQUEST_ID":    ctx.RequestID,
+		"SERVED_GROUP":  ctx.ServedGroup,
+		"MODEL":         ctx.Model,
+		"UPSTREAM_HOST": ctx.UpstreamHost,
+	} {
+		if replacement != "" {
+			variables[name] = replacement
+		}
+	}
+
+	if ctx.ProxyKey != "" {
+		// 代理密钥没有名称，以掩码标识；过短的密钥完全隐藏
+		name := MaskAPIKey(ctx.ProxyKey)
+		if name == ctx.ProxyKey {
+			name = "****"
+		}
+		variables["PROXY_KEY_NAME"] = name
+	}
+
+	return headerVariablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		parts := headerVariablePattern.FindStringSubmatch(match)
+		name, argument := parts[1], parts[2]
+		hasArgument := strings.Contains(match, ":")
+
+		switch {
+		case name == "HEADER" && hasArgument:
+			// 认证头携带客户端的代理密钥，不转发给上游或回显给客户端
+			if IsClientAuthHeader(argument) {
+				return ""
+			}
+			return ctx.RequestHeader.Get(argument)
+		case name == "SECRET" && hasArgument:
+			if ctx.Secrets != nil {
+				if secret, ok := ctx.Secrets(argument); ok {
+					return secret
+				}
+			}
+			logrus.WithField("secret", argument).Warn("Header rule references an unknown secret")
+			return ""
+		case !hasArgument:
+			if replacement, ok := variables[name]; ok {
+				return replacement
+			}
+		}
+		return match
+	})
 }
 
 // ApplyHeaderRules applies header rules to the HTTP request
 func ApplyHeaderRules(req *http.Request, rules []models.HeaderRule, ctx *HeaderVariableContext) {
-	if req == nil || len(rules) == 0 {
+	if req == nil {
 		return
 	}
+	if ctx != nil && ctx.UpstreamHost == "" && req.URL != nil {
+		withHost := *ctx
+		withHost.UpstreamHost = req.URL.Host
+		ctx = &withHost
+	}
+	applyHeaderRules(req.Header, rules, ctx)
+}
 
+// ApplyResponseHeaderRules applies header rules to the headers of the response sent to the client.
+// The context should carry no API key, so that ${API_KEY} cannot leak the upstream key.
+func ApplyResponseHeaderRules(header http.Header, rules []models.HeaderRule, ctx *HeaderVariableContext) {
+	if header == nil {
+		return
+	}
+	applyHeaderRules(header, rules, ctx)
+}
+
+func applyHeaderRules(header http.Header, rules []models.HeaderRule, ctx *HeaderVariableContext) {
 	for _, rule := range rules {
 		canonicalKey := http.CanonicalHeaderKey(rule.Key)
 
 		switch rule.Action {
 		case "remove":
-			req.Header.Del(canonicalKey)
+			header.Del(canonicalKey)
 		case "set":
 			resolvedValue := ResolveHeaderVariables(rule.Value, ctx)
-			req.Header.Set(canonicalKey, resolvedValue)
+			header.Set(canonicalKey, resolvedValue)
 		}
 	}
 }
@@ -75,17 +199,26 @@ func NewHeaderVariableContextFromGin(c *gin.Context, group *models.Group, apiKey
 	}
 
 	return &HeaderVariableContext{
-		ClientIP: c.ClientIP(),
-		Group:    group,
-		APIKey:   apiKey,
+		ClientIP:      c.ClientIP(),
+		Group:         group,
+		APIKey:        apiKey,
+		RequestID:     c.GetString(RequestIDContextKey),
+		ProxyKey:      c.GetString("proxyKey"),
+		RequestHeader: c.Request.Header,
+		Secrets:       defaultSecretResolver(),
 	}
 }
 
 // NewHeaderVariableContext creates HeaderVariableContext without Gin context
 func NewHeaderVariableContext(group *models.Group, apiKey *models.APIKey) *HeaderVariableContext {
-	return &HeaderVariableContext{
+	ctx := &HeaderVariableContext{
 		ClientIP: "127.0.0.1",
 		Group:    group,
 		APIKey:   apiKey,
+		Secrets:  defaultSecretResolver(),
+	}
+	if group != nil {
+		ctx.Model = group.TestModel
 	}
+	return ctx
 }
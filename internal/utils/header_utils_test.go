@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"gpt-load/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyResponseHeaderRules(t *testing.T) {
+	header := http.Header{}
+	header.Set("Openai-Organization", "org-123")
+	header.Set("X-Ratelimit-Remaining-Requests", "42")
+	header.Set("Content-Type", "application/json")
+
+	rules := []models.HeaderRule{
+		{Key: "openai-organization", Action: "remove"},
+		{Key: "X-Ratelimit-Remaining-Requests", Action: "remove"},
+		{Key: "X-Served-Group", Value: "${GROUP_NAME}", Action: "set"},
+		{Key: "X-Attempts", Value: "${ATTEMPT_COUNT}", Action: "set"},
+		{Key: "X-Group", Value: "${SERVED_GROUP}", Action: "set"},
+	}
+	ctx := &HeaderVariableContext{Group: &models.Group{Name: "g1"}, AttemptCount: 3, ServedGroup: "g1"}
+	ApplyResponseHeaderRules(header, rules, ctx)
+
+	assert.Empty(t, header.Get("Openai-Organization"))
+	assert.Empty(t, header.Get("X-Ratelimit-Remaining-Requests"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "g1", header.Get("X-Served-Group"))
+	assert.Equal(t, "3", header.Get("X-Attempts"))
+	assert.Equal(t, "g1", header.Get("X-Group"))
+}
+
+func TestResolveHeaderVariables_WithoutAPIKey(t *testing.T) {
+	ctx := &HeaderVariableContext{Group: &models.Group{Name: "g1"}}
+	assert.Equal(t, "${API_KEY} ${ATTEMPT_COUNT}", ResolveHeaderVariables("${API_KEY} ${ATTEMPT_COUNT}", ctx))
+
+	// 响应头上下文中未发出上游请求时尝试次数为 0
+	ctx.ServedGroup = "g1"
+	assert.Equal(t, "g1 0", ResolveHeaderVariables("${SERVED_GROUP} ${ATTEMPT_COUNT}", ctx))
+}
+
+func TestResolveHeaderVariables_Extended(t *testing.T) {
+	requestHeader := http.Header{}
+	requestHeader.Set("X-User", "alice")
+	requestHeader.Set("X-Injected", "${SECRET:token}")
+	requestHeader.Set("Authorization", "Bearer sk-proxy-1234567890")
+	requestHeader.Set("X-Goog-Api-Key", "sk-proxy-1234567890")
+	secrets := map[string]string{"token": "s3cr3t"}
+
+	ctx := &HeaderVariableContext{
+		APIKey:        &models.APIKey{ID: 7, KeyValue: "sk-upstream"},
+		RequestID:     "req-1",
+		Model:         "gpt-4o",
+		ProxyKey:      "sk-proxy-1234567890",
+		UpstreamHost:  "api.example.com",
+		RequestHeader: requestHeader,
+		Secrets: func(name string) (string, bool) {
+			value, ok := secrets[name]
+			return value, ok
+		},
+	}
+
+	tests := []struct {
+		value, expected string
+	}{
+		{"${REQUEST_ID}", "req-1"},
+		{"${MODEL}@${UPSTREAM_HOST}", "gpt-4o@api.example.com"},
+		{"${KEY_ID}", "7"},
+		{"${PROXY_KEY_NAME}", MaskAPIKey("sk-proxy-1234567890")},
+		{"Bearer ${SECRET:token}", "Bearer s3cr3t"},
+		{"${SECRET:missing}", ""},
+		{"${HEADER:X-User}", "alice"},
+		{"${HEADER:X-Missing}", ""},
+		{"${HEADER:X-Injected}", "${SECRET:token}"},
+		{"${HEADER:Authorization}", ""},
+		{"${HEADER:x-goog-api-key}", ""},
+		{"${UNKNOWN} ${MODEL}", "${UNKNOWN} gpt-4o"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ResolveHeaderVariables(tt.value, ctx), tt.value)
+	}
+}
+
+func TestResolveHeaderVariables_ShortProxyKeyAndNoSecrets(t *testing.T) {
+	ctx := &HeaderVariableContext{ProxyKey: "short"}
+	assert.Equal(t, "****", ResolveHeaderVariables("${PROXY_KEY_NAME}", ctx))
+	assert.Equal(t, "", ResolveHeaderVariables("${SECRET:token}", ctx))
+	assert.Equal(t, "${KEY_ID} ${REQUEST_ID}", ResolveHeaderVariables("${KEY_ID} ${REQUEST_ID}", ctx))
+}
+
+func TestApplyHeaderRules_UpstreamHost(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://upstream.example.com:8443/v1/chat/completions", nil)
+	assert.NoError(t, err)
+
+	ctx := &HeaderVariableContext{}
+	ApplyHeaderRules(req, []models.HeaderRule{{Key: "X-Upstream", Value: "${UPSTREAM_HOST}", Action: "set"}}, ctx)
+
+	assert.Equal(t, "upstream.example.com:8443", req.Header.Get("X-Upstream"))
+	assert.Empty(t, ctx.UpstreamHost)
+}
+
+func TestClientAuthHeaderReference(t *testing.T) {
+	header, ok := ClientAuthHeaderReference("Bearer ${HEADER:authorization}")
+	assert.True(t, ok)
+	assert.Equal(t, "Authorization", header)
+
+	header, ok = ClientAuthHeaderReference("${HEADER:X-User} ${HEADER:X-Api-Key}")
+	assert.True(t, ok)
+	assert.Equal(t, "X-Api-Key", header)
+
+	_, ok = ClientAuthHeaderReference("${HEADER:X-User} ${API_KEY}")
+	assert.False(t, ok)
+}
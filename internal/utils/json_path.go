@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SplitJSONPath splits a dotted JSON path such as "input.model" or "messages.0.role" into its segments.
+// Numeric segments address array elements.
+func SplitJSONPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, errors.New("empty JSON path")
+	}
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid JSON path %q: empty segment", path)
+		}
+	}
+	return segments, nil
+}
+
+// GetJSONPath returns the value at a dotted path of a decoded JSON document.
+func GetJSONPath(data any, path string) (any, bool) {
+	segments, err := SplitJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	current := data
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// SetJSONPath sets the value at a dotted path of a decoded JSON object, creating missing objects on the way.
+// Array elements can be replaced but not appended.
+func SetJSONPath(data map[string]any, path string, value any) error {
+	segments, err := SplitJSONPath(path)
+	if err != nil {
+		return err
+	}
+	_, err = setJSONPath(data, segments, value)
+	if err != nil {
+		return fmt.Errorf("cannot set %q: %w", path, err)
+	}
+	return nil
+}
+
+func setJSONPath(node any, segments []string, value any) (any, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+	segment := segments[0]
+
+	switch current := node.(type) {
+	case map[string]any:
+		child, err := setJSONPath(current[segment], segments[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		current[segment] = child
+		return current, nil
+	case []any:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(current) {
+			return nil, fmt.Errorf("array index %q out of range", segment)
+		}
+		child, err := setJSONPath(current[index], segments[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		current[index] = child
+		return current, nil
+	case nil:
+		return setJSONPath(map[string]any{}, segments, value)
+	default:
+		return nil, fmt.Errorf("segment %q is not an object or array", segment)
+	}
+}
+
+// DeleteJSONPath removes the value at a dotted path of a decoded JSON object.
+// Deleting an array element shifts the following elements. It reports whether a value was removed.
+func DeleteJSONPath(data map[string]any, path string) bool {
+	segments, err := SplitJSONPath(path)
+	if err != nil {
+		return false
+	}
+	_, deleted := deleteJSONPath(data, segments)
+	return deleted
+}
+
+func deleteJSONPath(node any, segments []string) (any, bool) {
+	segment := segments[0]
+	last := len(segments) == 1
+
+	switch current := node.(type) {
+	case map[string]any:
+		child, ok := current[segment]
+		if !ok {
+			return current, false
+		}
+		if last {
+			delete(current, segment)
+			return current, true
+		}
+		child, deleted := deleteJSONPath(child, segments[1:])
+		current[segment] = child
+		return current, deleted
+	case []any:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(current) {
+			return current, false
+		}
+		if last {
+			return append(current[:index], current[index+1:]...), true
+		}
+		child, deleted := deleteJSONPath(current[index], segments[1:])
+		current[index] = child
+		return current, deleted
+	default:
+		return node, false
+	}
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJSONPath(t *testing.T) {
+	var data any
+	require.NoError(t, json.Unmarshal([]byte(`{"input":{"model":"m1"},"messages":[{"role":"user"}]}`), &data))
+
+	value, ok := GetJSONPath(data, "input.model")
+	assert.True(t, ok)
+	assert.Equal(t, "m1", value)
+
+	value, ok = GetJSONPath(data, "messages.0.role")
+	assert.True(t, ok)
+	assert.Equal(t, "user", value)
+
+	for _, path := range []string{"input.missing", "messages.1.role", "messages.x", "input..model", ""} {
+		_, ok = GetJSONPath(data, path)
+		assert.False(t, ok, path)
+	}
+}
+
+func TestSplitJSONPath(t *testing.T) {
+	segments, err := SplitJSONPath("a.0.b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "0", "b"}, segments)
+
+	_, err = SplitJSONPath("a.")
+	assert.Error(t, err)
+}
+
+func TestSetJSONPath(t *testing.T) {
+	data := map[string]any{"messages": []any{map[string]any{"role": "user"}}, "n": 1.0}
+
+	require.NoError(t, SetJSONPath(data, "generationConfig.maxOutputTokens", 512))
+	require.NoError(t, SetJSONPath(data, "messages.0.role", "system"))
+	assert.Equal(t, map[string]any{"maxOutputTokens": 512}, data["generationConfig"])
+	assert.Equal(t, "system", data["messages"].([]any)[0].(map[string]any)["role"])
+
+	assert.Error(t, SetJSONPath(data, "messages.1.role", "user"))
+	assert.Error(t, SetJSONPath(data, "n.value", 2))
+	assert.Error(t, SetJSONPath(data, "a..b", 2))
+}
+
+func TestDeleteJSONPath(t *testing.T) {
+	data := map[string]any{
+		"input":    map[string]any{"model": "m1", "seed": 1.0},
+		"messages": []any{"a", "b", "c"},
+	}
+
+	assert.True(t, DeleteJSONPath(data, "input.seed"))
+	assert.True(t, DeleteJSONPath(data, "messages.1"))
+	assert.Equal(t, map[string]any{"model": "m1"}, data["input"])
+	assert.Equal(t, []any{"a", "c"}, data["messages"])
+
+	assert.False(t, DeleteJSONPath(data, "input.seed"))
+	assert.False(t, DeleteJSONPath(data, "messages.5"))
+	assert.False(t, DeleteJSONPath(data, "input.model.name"))
+}
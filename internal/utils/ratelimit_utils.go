@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRateLimitCooldown caps the cool-down derived from upstream headers so that a bogus value cannot park a key forever.
+const MaxRateLimitCooldown = time.Hour
+
+// rateLimitResetHeaders pairs "remaining" headers with the header telling when that budget resets.
+// OpenAI uses durations such as "6m0s" or "20ms"; Anthropic uses RFC 3339 timestamps.
+var rateLimitResetHeaders = []struct {
+	remaining string
+	reset     string
+	requests  bool
+}{
+	{"x-ratelimit-remaining-requests", "x-ratelimit-reset-requests", true},
+	{"x-ratelimit-remaining-tokens", "x-ratelimit-reset-tokens", false},
+	{"anthropic-ratelimit-requests-remaining", "anthropic-ratelimit-requests-reset", true},
+	{"anthropic-ratelimit-tokens-remaining", "anthropic-ratelimit-tokens-reset", false},
+	{"anthropic-ratelimit-input-tokens-remaining", "anthropic-ratelimit-input-tokens-reset", false},
+	{"anthropic-ratelimit-output-tokens-remaining", "anthropic-ratelimit-output-tokens-reset", false},
+}
+
+// ParseRateLimitCooldown extracts how long a key should rest from upstream rate-limit headers.
+// For 429 responses Retry-After (seconds or HTTP date) and retry-after-ms win; otherwise, and for
+// successful responses, the longest reset among exhausted (remaining 0) OpenAI/Anthropic budgets
+// is used. A 429 whose counters have not caught up yet falls back to the request budget's reset,
+// then to the token budgets' reset. It returns 0 when the headers do not indicate a cool-down.
+func ParseRateLimitCooldown(header http.Header, statusCode int, now time.Time) time.Duration {
+	var cooldown time.Duration
+
+	if statusCode == http.StatusTooManyRequests {
+		if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+			cooldown = time.Duration(ms * float64(time.Millisecond))
+		} else if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+				cooldown = time.Duration(seconds * float64(time.Second))
+			} else if at, err := http.ParseTime(value); err == nil {
+				cooldown = at.Sub(now)
+			}
+		}
+	}
+
+	if cooldown <= 0 {
+		var requestsReset, tokensReset time.Duration
+		for _, h := range rateLimitResetHeaders {
+			remaining := strings.TrimSpace(header.Get(h.remaining))
+			if remaining == "" {
+				continue
+			}
+			n, err := strconv.ParseFloat(remaining, 64)
+			if err != nil {
+				continue
+			}
+			reset := parseRateLimitReset(header.Get(h.reset), now)
+			switch {
+			case n <= 0:
+				cooldown = max(cooldown, reset)
+			case h.requests:
+				requestsReset = max(requestsReset, reset)
+			default:
+				tokensReset = max(tokensReset, reset)
+			}
+		}
+
+		// A 429 means some budget is spent even if no counter has caught up yet.
+		if cooldown <= 0 && statusCode == http.StatusTooManyRequests {
+			if requestsReset > 0 {
+				cooldown = requestsReset
+			} else {
+				cooldown = tokensReset
+			}
+		}
+	}
+
+	if cooldown <= 0 {
+		return 0
+	}
+	return min(cooldown, MaxRateLimitCooldown)
+}
+
+// parseRateLimitReset parses a reset value given as a duration, a number of seconds or an RFC 3339 timestamp.
+func parseRateLimitReset(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// ParseTimeOfDay parses a daily time in "HH:MM" format.
+func ParseTimeOfDay(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("time of day must be in HH:MM format, got %q", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// NextQuotaReset returns the first daily quota reset strictly after now, for a reset time
+// in "HH:MM" format in the given IANA time zone.
+func NextQuotaReset(now time.Time, resetTime, timezone string) (time.Time, error) {
+	hour, minute, err := ParseTimeOfDay(resetTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown time zone %q: %w", timezone, err)
+	}
+
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, loc)
+	}
+	return next, nil
+}
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitCooldown(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		statusCode int
+		headers    map[string]string
+		expected   time.Duration
+	}{
+		{
+			name:       "retry-after seconds",
+			statusCode: http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "20"},
+			expected:   20 * time.Second,
+		},
+		{
+			name:       "retry-after http date",
+			statusCode: http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": now.Add(90 * time.Second).Format(http.TimeFormat)},
+			expected:   90 * time.Second,
+		},
+		{
+			name:       "retry-after-ms takes precedence",
+			statusCode: http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "20", "retry-after-ms": "1500"},
+			expected:   1500 * time.Millisecond,
+		},
+		{
+			name:       "openai reset headers on 429",
+			statusCode: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"x-ratelimit-remaining-requests": "0",
+				"x-ratelimit-reset-requests":     "6m0s",
+				"x-ratelimit-remaining-tokens":   "1000",
+				"x-ratelimit-reset-tokens":       "20ms",
+			},
+			expected: 6 * time.Minute,
+		},
+		{
+			name:       "only exhausted budgets count on 429",
+			statusCode: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"x-ratelimit-remaining-requests": "0",
+				"x-ratelimit-reset-requests":     "2s",
+				"x-ratelimit-remaining-tokens":   "5000",
+				"x-ratelimit-reset-tokens":       "40s",
+			},
+			expected: 2 * time.Second,
+		},
+		{
+			name:       "requests reset preferred when no budget reads exhausted",
+			statusCode: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"x-ratelimit-remaining-requests": "3",
+				"x-ratelimit-reset-requests":     "2s",
+				"x-ratelimit-remaining-tokens":   "5000",
+				"x-ratelimit-reset-tokens":       "40s",
+			},
+			expected: 2 * time.Second,
+		},
+		{
+			name:       "tokens reset used when it is the only one on 429",
+			statusCode: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"x-ratelimit-remaining-tokens": "5000",
+				"x-ratelimit-reset-tokens":     "40s",
+			},
+			expected: 40 * time.Second,
+		},
+		{
+			name:       "anthropic reset timestamp",
+			statusCode: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"anthropic-ratelimit-requests-remaining": "0",
+				"anthropic-ratelimit-requests-reset":     now.Add(45 * time.Second).Format(time.RFC3339),
+			},
+			expected: 45 * time.Second,
+		},
+		{
+			name:       "exhausted budget on successful response",
+			statusCode: http.StatusOK,
+			headers: map[string]string{
+				"x-ratelimit-remaining-requests": "0",
+				"x-ratelimit-reset-requests":     "2s",
+			},
+			expected: 2 * time.Second,
+		},
+		{
+			name:       "remaining budget on successful response",
+			statusCode: http.StatusOK,
+			headers: map[string]string{
+				"x-ratelimit-remaining-requests": "10",
+				"x-ratelimit-reset-requests":     "2s",
+			},
+			expected: 0,
+		},
+		{
+			name:       "retry-after ignored on non-429",
+			statusCode: http.StatusServiceUnavailable,
+			headers:    map[string]string{"Retry-After": "20"},
+			expected:   0,
+		},
+		{
+			name:       "capped at maximum",
+			statusCode: http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "86400"},
+			expected:   MaxRateLimitCooldown,
+		},
+		{
+			name:       "no headers",
+			statusCode: http.StatusTooManyRequests,
+			expected:   0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tc.headers {
+				header.Set(k, v)
+			}
+			assert.Equal(t, tc.expected, ParseRateLimitCooldown(header, tc.statusCode, now))
+		})
+	}
+}
+
+func TestNextQuotaReset(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		now       time.Time
+		resetTime string
+		timezone  string
+		expected  time.Time
+	}{
+		{
+			name:      "later today",
+			now:       time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+			resetTime: "12:00",
+			timezone:  "UTC",
+			expected:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "tomorrow when already passed",
+			now:       time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+			resetTime: "00:00",
+			timezone:  "UTC",
+			expected:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "exactly at reset moves to the next day",
+			now:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			resetTime: "00:00",
+			timezone:  "UTC",
+			expected:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "midnight pacific",
+			now:       time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+			resetTime: "00:00",
+			timezone:  "America/Los_Angeles",
+			expected:  time.Date(2026, 3, 2, 0, 0, 0, 0, la),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := NextQuotaReset(tc.now, tc.resetTime, tc.timezone)
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(next), "expected %s, got %s", tc.expected, next)
+		})
+	}
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := NextQuotaReset(time.Now(), "noon", "UTC")
+		assert.Error(t, err)
+		_, err = NextQuotaReset(time.Now(), "12:00", "Nowhere/Special")
+		assert.Error(t, err)
+	})
+}
@@ -89,6 +89,37 @@ func (s *KeyValidator) ValidateSingleKey(key *models.APIKey, group *models.Group
 	return true, nil
 }
 
+// CheckKeylessGroup probes the upstreams of a group served by a keyless channel.
+// It returns false when the group's channel needs keys.
+func (s *KeyValidator) CheckKeylessGroup(group *models.Group) bool {
+	if group.EffectiveConfig.AppUrl == "" {
+		group.EffectiveConfig = s.SettingsManager.GetEffectiveConfig(group.Config)
+	}
+
+	ch, err := s.channelFactory.GetChannel(group)
+	if err != nil {
+		logrus.WithError(err).WithField("group_id", group.ID).Error("Failed to get channel for keyless group check")
+		return false
+	}
+	keyless, ok := ch.(channel.KeylessChannel)
+	if !ok {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(group.EffectiveConfig.KeyValidationTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	if err := keyless.CheckUpstreams(ctx, group); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":    err,
+			"group_id": group.ID,
+		}).Warn("Keyless group upstream check failed")
+	} else {
+		logrus.WithField("group_id", group.ID).Debug("Keyless group upstream check successful")
+	}
+	return true
+}
+
 // TestMultipleKeys performs a synchronous validation for a list of key values within a specific group.
 func (s *KeyValidator) TestMultipleKeys(group *models.Group, keyValues []string) ([]interface{}, error) {
 	results := make([]KeyTestResult, len(keyValues))